@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// AccountsHandler handles brokerage account HTTP requests
+type AccountsHandler struct {
+	accountService *services.AccountService
+}
+
+// NewAccountsHandler creates a new accounts handler
+func NewAccountsHandler(accountService *services.AccountService) *AccountsHandler {
+	return &AccountsHandler{
+		accountService: accountService,
+	}
+}
+
+// AccountRequest represents the request structure for creating or updating an account
+type AccountRequest struct {
+	Name         string             `json:"name" binding:"required"`
+	Broker       string             `json:"broker" binding:"required"`
+	AccountType  models.AccountType `json:"account_type"`
+	BaseCurrency string             `json:"base_currency"`
+}
+
+// GetAccounts handles GET /api/v1/accounts
+func (h *AccountsHandler) GetAccounts(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	accounts, err := h.accountService.GetAccounts(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to get accounts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Accounts retrieved successfully",
+		"data":    gin.H{"accounts": accounts},
+	})
+}
+
+// GetAccount handles GET /api/v1/accounts/{id}
+func (h *AccountsHandler) GetAccount(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+	accountID, ok := parseAccountIDParam(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.accountService.GetAccount(userID, accountID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Account does not exist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Account retrieved successfully",
+		"data":    gin.H{"account": account},
+	})
+}
+
+// CreateAccount handles POST /api/v1/accounts
+func (h *AccountsHandler) CreateAccount(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindAccountRequest(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.accountService.CreateAccount(userID, req.Name, req.Broker, req.AccountType, req.BaseCurrency)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to create account",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Account created successfully",
+		"data":    gin.H{"account": account},
+	})
+}
+
+// UpdateAccount handles PUT /api/v1/accounts/{id}
+func (h *AccountsHandler) UpdateAccount(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+	accountID, ok := parseAccountIDParam(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindAccountRequest(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.accountService.UpdateAccount(userID, accountID, req.Name, req.Broker, req.AccountType, req.BaseCurrency)
+	if err != nil {
+		if err.Error() == "not_found" {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Account does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Account updated successfully",
+		"data":    gin.H{"account": account},
+	})
+}
+
+// DeleteAccount handles DELETE /api/v1/accounts/{id}
+func (h *AccountsHandler) DeleteAccount(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+	accountID, ok := parseAccountIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.accountService.DeleteAccount(userID, accountID); err != nil {
+		switch err.Error() {
+		case "not_found":
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Account does not exist"})
+		case "in_use":
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Account is referenced by transactions"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to delete account"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Account deleted successfully",
+	})
+}
+
+// bindAccountRequest binds and validates an account request, applying defaults for omitted fields
+func bindAccountRequest(c *gin.Context) (AccountRequest, bool) {
+	var req AccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid request format", "errors": map[string][]string{"json": {err.Error()}}})
+		return req, false
+	}
+
+	if req.AccountType == "" {
+		req.AccountType = models.AccountTypeTaxable
+	}
+	if req.BaseCurrency == "" {
+		req.BaseCurrency = models.DefaultBaseCurrency
+	}
+	req.BaseCurrency = strings.ToUpper(strings.TrimSpace(req.BaseCurrency))
+
+	var validationErrors []string
+	if name := strings.TrimSpace(req.Name); name == "" || len(name) > 100 {
+		validationErrors = append(validationErrors, "name must be between 1 and 100 characters")
+	}
+	if broker := strings.TrimSpace(req.Broker); broker == "" || len(broker) > 100 {
+		validationErrors = append(validationErrors, "broker must be between 1 and 100 characters")
+	}
+	if !req.AccountType.IsValid() {
+		validationErrors = append(validationErrors, "account_type must be one of: taxable, ira, roth_ira, 401k, other")
+	}
+	if !utils.CurrencyRegex.MatchString(req.BaseCurrency) {
+		validationErrors = append(validationErrors, "base_currency must be a 3-letter ISO code")
+	}
+	if len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Validation failed", "errors": map[string][]string{"validation": validationErrors}})
+		return req, false
+	}
+
+	return req, true
+}
+
+// parseAccountIDParam parses the account ID URL parameter
+func parseAccountIDParam(c *gin.Context) (uuid.UUID, bool) {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid account ID format"})
+		return uuid.Nil, false
+	}
+	return accountID, true
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -9,6 +10,7 @@ import (
 	"github.com/transaction-tracker/backend/internal/models"
 	"github.com/transaction-tracker/backend/internal/repositories"
 	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/utils"
 	"gorm.io/gorm"
 )
 
@@ -78,6 +80,18 @@ type UserInfoResponse struct {
 	ActiveTokens int         `json:"active_tokens"`
 }
 
+// UserPreferences represents the user's portfolio preferences
+type UserPreferences struct {
+	CostBasisMethod models.CostBasisMethod `json:"cost_basis_method"`
+	BaseCurrency    string                 `json:"base_currency"`
+}
+
+// UpdatePreferencesRequest represents a request to update user preferences; omitted fields are left unchanged
+type UpdatePreferencesRequest struct {
+	CostBasisMethod *models.CostBasisMethod `json:"cost_basis_method"`
+	BaseCurrency    *string                 `json:"base_currency"`
+}
+
 // Login handles user authentication and returns a JWT token
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
@@ -196,6 +210,113 @@ func (h *AuthHandler) Me(c *gin.Context) {
 	})
 }
 
+// GetPreferences returns the current user's portfolio preferences
+func (h *AuthHandler) GetPreferences(c *gin.Context) {
+	user, ok := h.getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    toUserPreferences(user),
+	})
+}
+
+// UpdatePreferences updates the current user's portfolio preferences
+func (h *AuthHandler) UpdatePreferences(c *gin.Context) {
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	user, ok := h.getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if req.CostBasisMethod != nil {
+		if !req.CostBasisMethod.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid cost_basis_method. Supported values: average, fifo, lifo, hifo, specific_lot",
+			})
+			return
+		}
+		user.CostBasisMethod = *req.CostBasisMethod
+	}
+
+	if req.BaseCurrency != nil {
+		baseCurrency := strings.ToUpper(strings.TrimSpace(*req.BaseCurrency))
+		if !utils.CurrencyRegex.MatchString(baseCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid base_currency. Must be a 3-letter ISO currency code",
+			})
+			return
+		}
+		user.BaseCurrency = baseCurrency
+	}
+
+	if err := h.userRepo.Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update preferences",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    toUserPreferences(user),
+	})
+}
+
+// getCurrentUser loads the authenticated user, writing an error response on failure
+func (h *AuthHandler) getCurrentUser(c *gin.Context) (*models.User, bool) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return nil, false
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID",
+		})
+		return nil, false
+	}
+
+	user, err := h.userRepo.FindByUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return nil, false
+	}
+
+	return user, true
+}
+
+// toUserPreferences builds the preferences view of a user, applying defaults for unset values
+func toUserPreferences(user *models.User) UserPreferences {
+	method := user.CostBasisMethod
+	if !method.IsValid() {
+		method = models.DefaultCostBasisMethod
+	}
+	baseCurrency := user.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = models.DefaultBaseCurrency
+	}
+	return UserPreferences{
+		CostBasisMethod: method,
+		BaseCurrency:    baseCurrency,
+	}
+}
+
 // Signup handles user registration
 func (h *AuthHandler) Signup(c *gin.Context) {
 	var req SignupRequest
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/transaction-tracker/backend/internal/exporter"
+	"github.com/transaction-tracker/backend/internal/logger"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/services"
+)
+
+// ExportTransactions handles GET /transaction-history/export. It takes the filters and sort of the
+// transaction history plus format=csv|json|xlsx (default csv) and returns every matching
+// transaction as a file download, ignoring pagination. Rows are streamed from the database to the
+// response, so the size of the history does not affect memory use.
+func (h *TransactionsHandler) ExportTransactions(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	params, validationErrors := parseTransactionQueryParams(c)
+	format, ok := exporter.ParseFormat(c.DefaultQuery("format", string(exporter.FormatCSV)))
+	if !ok {
+		names := make([]string, len(exporter.Formats))
+		for i, format := range exporter.Formats {
+			names[i] = string(format)
+		}
+		validationErrors["format"] = []string{"Must be one of: " + strings.Join(names, ", ")}
+	}
+	if len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, GetTransactionsResponse{
+			Success: false,
+			Message: "Validation failed",
+			Errors:  validationErrors,
+		})
+		return
+	}
+
+	filter := services.TransactionFilter{
+		UserID:         &userID,
+		Symbols:        params.Symbols,
+		TradeTypes:     params.TradeTypes,
+		Exchanges:      params.Exchanges,
+		Brokers:        params.Brokers,
+		Currencies:     params.Currencies,
+		StartDate:      params.StartDate,
+		EndDate:        params.EndDate,
+		OrderBy:        params.SortBy,
+		OrderDirection: params.SortOrder,
+	}
+
+	// The file is started with the first row, so a query that fails outright still gets an error
+	// response; once rows are sent, a failure can only cut the download short
+	var writer exporter.Writer
+	start := func() error {
+		c.Header("Content-Type", format.ContentType())
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="transactions-%s.%s"`, time.Now().Format("2006-01-02"), format))
+		c.Status(http.StatusOK)
+		var err error
+		writer, err = exporter.NewWriter(format, c.Writer)
+		return err
+	}
+
+	err := h.transactionService.StreamTransactionsWithFilter(filter, func(transaction models.Transaction) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return writer.Write(modelToTransactionData(transaction))
+	})
+	if err == nil && writer == nil {
+		err = start()
+	}
+	if err != nil {
+		if writer == nil {
+			c.JSON(http.StatusInternalServerError, GetTransactionsResponse{
+				Success: false,
+				Message: "Failed to export transactions",
+				Errors:  map[string][]string{"database": {err.Error()}},
+			})
+			return
+		}
+		logger.Error("Failed to export transactions", err, logger.H{"user_id": userID.String()})
+		c.Abort()
+		return
+	}
+
+	if err := writer.Close(); err != nil {
+		logger.Error("Failed to finish transaction export", err, logger.H{"user_id": userID.String()})
+	}
+}
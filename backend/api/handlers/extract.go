@@ -1,12 +1,21 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/transaction-tracker/backend/config"
 	"github.com/transaction-tracker/backend/internal/constants"
+	"github.com/transaction-tracker/backend/internal/pdf"
 	"github.com/transaction-tracker/backend/internal/types"
 )
 
@@ -26,7 +35,9 @@ func NewExtractTransactionsHandler(cfg *config.Config, aiClient AIClient) *Extra
 	return &ExtractTransactionHandler{cfg: cfg, aiClient: aiClient}
 }
 
-// ExtractTransactionsHandler handles the image upload and transaction extraction
+// ExtractTransactionsHandler handles the image upload and transaction extraction. A PDF is split
+// into pages that are extracted one at a time, and their transactions are merged into one
+// response with the page each came from.
 func (h *ExtractTransactionHandler) ExtractTransactions(c *gin.Context) {
 	form, err := c.MultipartForm()
 	if err != nil {
@@ -47,6 +58,14 @@ func (h *ExtractTransactionHandler) ExtractTransactions(c *gin.Context) {
 	}
 
 	fileHeader := file[0]
+	if fileHeader.Size > constants.MaxFileSize {
+		c.JSON(http.StatusBadRequest, types.ExtractResponse{
+			Success: false,
+			Message: fmt.Sprintf("File %s exceeds the %d MB limit", fileHeader.Filename, constants.MaxFileSize>>20),
+		})
+		return
+	}
+
 	src, err := fileHeader.Open()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ExtractResponse{
@@ -57,10 +76,33 @@ func (h *ExtractTransactionHandler) ExtractTransactions(c *gin.Context) {
 	}
 	defer src.Close()
 
+	data, err := io.ReadAll(src)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ExtractResponse{
+			Success: false,
+			Message: "Failed to read uploaded file " + fileHeader.Filename + ": " + err.Error(),
+		})
+		return
+	}
+
+	mimeType, ok := uploadMimeType(fileHeader.Header.Get(constants.ContentTypeHeader), data)
+	if !ok {
+		c.JSON(http.StatusBadRequest, types.ExtractResponse{
+			Success: false,
+			Message: fmt.Sprintf("Unsupported file type %s. Supported types: %s", mimeType, strings.Join(constants.SupportedImageMimeTypes(), ", ")),
+		})
+		return
+	}
+
+	if mimeType == constants.MimeTypePDF {
+		h.extractPDF(c, fileHeader.Filename, data)
+		return
+	}
+
 	imageInput := types.FileInput{
-		Data:     src,
+		Data:     bytes.NewReader(data),
 		Filename: fileHeader.Filename,
-		MimeType: fileHeader.Header.Get(constants.ContentTypeHeader),
+		MimeType: mimeType,
 	}
 
 	extractResp, err := h.aiClient.ExtractTransactions(c.Request.Context(), imageInput)
@@ -79,3 +121,107 @@ func (h *ExtractTransactionHandler) ExtractTransactions(c *gin.Context) {
 
 	c.JSON(http.StatusOK, extractResp)
 }
+
+// uploadMimeType returns the MIME type of an upload and whether it is supported. Browsers send
+// some files as application/octet-stream, so an unsupported declared type is checked against the
+// type detected from the content.
+func uploadMimeType(declared string, data []byte) (string, bool) {
+	supported := constants.SupportedImageMimeTypesMap()
+	if mediaType, _, err := mime.ParseMediaType(declared); err == nil && supported[mediaType] {
+		return mediaType, true
+	}
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return detected, supported[detected]
+}
+
+// extractPDF extracts the transactions of each page of a PDF and merges them. A transaction that
+// appears again on a later page, such as in a summary repeating the trades, is kept once with the
+// first page it appears on. Identical transactions on one page are separate fills and are all kept:
+// a page only adds the occurrences beyond those already taken from earlier pages.
+func (h *ExtractTransactionHandler) extractPDF(c *gin.Context, filename string, data []byte) {
+	pages, err := pdf.SplitPagesUpTo(data, constants.MaxPDFPages)
+	var limitErr *pdf.PageLimitError
+	if errors.As(err, &limitErr) {
+		c.JSON(http.StatusBadRequest, types.ExtractResponse{
+			Success: false,
+			Message: fmt.Sprintf("PDF %s has %d pages; at most %d pages can be extracted", filename, limitErr.Pages, limitErr.Limit),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ExtractResponse{
+			Success: false,
+			Message: "Failed to read PDF " + filename + ": " + err.Error(),
+		})
+		return
+	}
+
+	merged := &types.ExtractResponseData{
+		Transactions: []types.TransactionData{},
+		FileName:     filename,
+		PageCount:    len(pages),
+	}
+	taken := make(map[string]int)
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	for i, page := range pages {
+		pageNumber := i + 1
+		extractResp, err := h.aiClient.ExtractTransactions(c.Request.Context(), types.FileInput{
+			Data:     bytes.NewReader(page),
+			Filename: fmt.Sprintf("%s-page-%d.pdf", base, pageNumber),
+			MimeType: constants.MimeTypePDF,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, types.ExtractResponse{
+				Success: false,
+				Message: fmt.Sprintf("Failed to extract transactions from page %d: %s", pageNumber, err.Error()),
+			})
+			return
+		}
+		if !extractResp.Success {
+			c.JSON(http.StatusInternalServerError, types.ExtractResponse{
+				Success: false,
+				Message: fmt.Sprintf("Failed to extract transactions from page %d: %s", pageNumber, extractResp.Message),
+			})
+			return
+		}
+		if extractResp.Data == nil {
+			continue
+		}
+
+		onPage := make(map[string]int)
+		for _, transaction := range extractResp.Data.Transactions {
+			key := extractedTransactionKey(transaction)
+			onPage[key]++
+			if onPage[key] <= taken[key] {
+				continue
+			}
+			transaction.Page = pageNumber
+			merged.Transactions = append(merged.Transactions, transaction)
+		}
+		for key, count := range onPage {
+			if count > taken[key] {
+				taken[key] = count
+			}
+		}
+	}
+	merged.TransactionCount = len(merged.Transactions)
+
+	c.JSON(http.StatusOK, types.ExtractResponse{
+		Success: true,
+		Message: constants.MsgTransactionsExtracted,
+		Data:    merged,
+	})
+}
+
+// extractedTransactionKey identifies an extracted transaction by the fields a statement shows
+func extractedTransactionKey(transaction types.TransactionData) string {
+	return strings.Join([]string{
+		strings.ToUpper(strings.TrimSpace(transaction.Symbol)),
+		transaction.TransactionDate,
+		string(transaction.TradeType),
+		strconv.FormatFloat(transaction.Quantity, 'f', -1, 64),
+		strconv.FormatFloat(transaction.Price, 'f', -1, 64),
+		strconv.FormatFloat(transaction.Amount, 'f', 2, 64),
+		strings.ToUpper(transaction.Currency),
+	}, "|")
+}
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/constants"
+	"github.com/transaction-tracker/backend/internal/importer"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// ImportTransactionsResponse represents the response for importing a transaction file
+type ImportTransactionsResponse struct {
+	Success bool                    `json:"success"`
+	Message string                  `json:"message"`
+	Data    *ImportTransactionsData `json:"data,omitempty"`
+	Errors  map[string][]string     `json:"errors,omitempty"`
+}
+
+// ImportTransactionsData represents the data part of import transactions response
+type ImportTransactionsData struct {
+	FileName       string                  `json:"file_name"`
+	Format         string                  `json:"format"`
+	Profile        string                  `json:"profile,omitempty"` // CSV only
+	DryRun         bool                    `json:"dry_run"`
+	Rows           []ImportRow             `json:"rows"`
+	ValidCount     int                     `json:"valid_count"`
+	InvalidCount   int                     `json:"invalid_count"`
+	SkippedCount   int                     `json:"skipped_count"`          // lines whose action is not a transaction
+	DuplicateCount int                     `json:"duplicate_count"`        // valid rows matching an existing transaction, found by a commit
+	Transactions   []types.TransactionData `json:"transactions,omitempty"` // created by a commit
+	Count          int                     `json:"count"`
+}
+
+// ImportRow represents one transaction read from an import file and whether it can be imported
+type ImportRow struct {
+	Line              int                   `json:"line"`
+	Transaction       types.TransactionData `json:"transaction"`
+	Valid             bool                  `json:"valid"`
+	Errors            []string              `json:"errors,omitempty"`
+	PossibleDuplicate bool                  `json:"possible_duplicate,omitempty"`
+	DuplicateOf       string                `json:"duplicate_of,omitempty"` // ID of the matching transaction
+	Skipped           bool                  `json:"skipped,omitempty"`      // not created as a duplicate
+}
+
+// ImportTransactions handles POST /transaction-history/import. The uploaded CSV is read with a
+// broker profile, or the uploaded OFX/QFX statement as is, and previewed row by row; with
+// dry_run=false the valid rows are created and rows with errors are left out. Rows whose FITID the
+// user has already imported are invalid, so re-importing an overlapping statement adds nothing twice.
+// A commit also flags the rows that match an existing transaction like a batch create does, and
+// with skip_duplicates=true leaves them out.
+func (h *TransactionsHandler) ImportTransactions(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ImportTransactionsResponse{
+			Success: false,
+			Message: "No file uploaded. Please upload a file under the 'file' field.",
+			Errors:  map[string][]string{"file": {"File is required"}},
+		})
+		return
+	}
+	if fileHeader.Size > constants.MaxFileSize {
+		c.JSON(http.StatusBadRequest, ImportTransactionsResponse{
+			Success: false,
+			Message: "Validation failed",
+			Errors:  map[string][]string{"file": {fmt.Sprintf("File cannot exceed %d MB", constants.MaxFileSize>>20)}},
+		})
+		return
+	}
+
+	opts, validationErrors := parseImportOptions(c, fileHeader.Filename)
+	if len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, ImportTransactionsResponse{
+			Success: false,
+			Message: "Validation failed",
+			Errors:  validationErrors,
+		})
+		return
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ImportTransactionsResponse{
+			Success: false,
+			Message: "Failed to open uploaded file " + fileHeader.Filename + ": " + err.Error(),
+		})
+		return
+	}
+	defer src.Close()
+
+	var result *importer.Result
+	if opts.format == importFormatOFX {
+		result, err = importer.ParseOFX(src)
+	} else {
+		result, err = importer.ParseCSV(src, opts.profile)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ImportTransactionsResponse{
+			Success: false,
+			Message: "Failed to read file",
+			Errors:  map[string][]string{"file": {err.Error()}},
+		})
+		return
+	}
+
+	imported, err := h.importedTransactionIDs(userID, result.Rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ImportTransactionsResponse{
+			Success: false,
+			Message: "Failed to check previously imported transactions",
+			Errors:  map[string][]string{"database": {err.Error()}},
+		})
+		return
+	}
+
+	data := &ImportTransactionsData{
+		FileName:     fileHeader.Filename,
+		Format:       opts.format,
+		DryRun:       opts.dryRun,
+		Rows:         make([]ImportRow, 0, len(result.Rows)),
+		SkippedCount: result.Skipped,
+	}
+	if opts.format == importFormatCSV {
+		data.Profile = opts.profile.Name
+	}
+	var valid []models.Transaction
+	var validRows []int // index in data.Rows of each valid transaction
+	for _, row := range result.Rows {
+		importRow, transaction := previewImportRow(row, opts)
+		if id, ok := imported[row.Transaction.ExternalID]; ok && row.Transaction.ExternalID != "" {
+			importRow.Valid = false
+			importRow.Errors = append(importRow.Errors, "already imported as transaction "+id.String())
+		}
+		if importRow.Valid {
+			data.ValidCount++
+			valid = append(valid, transaction)
+			validRows = append(validRows, len(data.Rows))
+		} else {
+			data.InvalidCount++
+		}
+		data.Rows = append(data.Rows, importRow)
+	}
+
+	if opts.dryRun {
+		c.JSON(http.StatusOK, ImportTransactionsResponse{
+			Success: true,
+			Message: "Import preview generated successfully",
+			Data:    data,
+		})
+		return
+	}
+
+	if len(valid) == 0 {
+		c.JSON(http.StatusBadRequest, ImportTransactionsResponse{
+			Success: false,
+			Message: "No valid transactions to import",
+			Data:    data,
+		})
+		return
+	}
+
+	created, err := h.transactionService.CreateTransactions(userID, valid, opts.skipDuplicates)
+	if err != nil {
+		if errors.Is(err, services.ErrAccountNotFound) {
+			c.JSON(http.StatusBadRequest, ImportTransactionsResponse{
+				Success: false,
+				Message: "Validation failed",
+				Errors:  map[string][]string{"account_id": {err.Error()}},
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ImportTransactionsResponse{
+			Success: false,
+			Message: "Failed to import transactions",
+			Errors:  map[string][]string{"database": {err.Error()}},
+		})
+		return
+	}
+
+	for i, id := range created.DuplicateOf {
+		if id == nil {
+			continue
+		}
+		row := &data.Rows[validRows[i]]
+		row.PossibleDuplicate = true
+		row.DuplicateOf = id.String()
+		row.Skipped = opts.skipDuplicates
+		data.DuplicateCount++
+	}
+	data.Transactions = modelsToTransactionData(created.Created)
+	data.Count = len(data.Transactions)
+
+	status, message := http.StatusCreated, "Transactions imported successfully"
+	if data.Count == 0 {
+		status, message = http.StatusOK, "All transactions already exist; nothing was imported"
+	}
+	c.JSON(status, ImportTransactionsResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// importedTransactionIDs maps the external IDs of the rows that the user has already imported to
+// their transactions
+func (h *TransactionsHandler) importedTransactionIDs(userID uuid.UUID, rows []importer.Row) (map[string]uuid.UUID, error) {
+	var externalIDs []string
+	for _, row := range rows {
+		if row.Transaction.ExternalID != "" {
+			externalIDs = append(externalIDs, row.Transaction.ExternalID)
+		}
+	}
+	if len(externalIDs) == 0 {
+		return map[string]uuid.UUID{}, nil
+	}
+	return h.transactionService.GetImportedIDs(userID, externalIDs)
+}
+
+// Import file formats
+const (
+	importFormatCSV = "csv"
+	importFormatOFX = "ofx"
+)
+
+// importOptions holds the form fields of an import request
+type importOptions struct {
+	format         string
+	profile        importer.Profile
+	dryRun         bool
+	skipDuplicates bool
+	broker         string
+	currency       string
+	accountID      *uuid.UUID
+}
+
+// parseImportOptions parses and validates the form fields of an import request. Without a format
+// field, .ofx and .qfx files are read as OFX and any other file as CSV.
+func parseImportOptions(c *gin.Context, fileName string) (opts importOptions, validationErrors map[string][]string) {
+	validationErrors = make(map[string][]string)
+
+	opts.format = strings.ToLower(strings.TrimSpace(c.PostForm("format")))
+	switch opts.format {
+	case importFormatCSV, importFormatOFX:
+	case "qfx":
+		opts.format = importFormatOFX
+	case "":
+		opts.format = importFormatCSV
+		switch strings.ToLower(filepath.Ext(fileName)) {
+		case ".ofx", ".qfx":
+			opts.format = importFormatOFX
+		}
+	default:
+		validationErrors["format"] = []string{"Must be one of: csv, ofx"}
+	}
+
+	profileName := c.DefaultPostForm("profile", "generic")
+	profile, ok := importer.LookupProfile(profileName)
+	if !ok {
+		validationErrors["profile"] = []string{"Must be one of: " + strings.Join(importer.ProfileNames(), ", ")}
+	}
+	opts.profile = profile
+
+	// Importing is opt-in, so a request without dry_run only previews
+	dryRun, err := strconv.ParseBool(c.DefaultPostForm("dry_run", "true"))
+	if err != nil {
+		validationErrors["dry_run"] = []string{"Must be true or false"}
+	}
+	opts.dryRun = dryRun
+
+	skipDuplicates, err := strconv.ParseBool(c.DefaultPostForm("skip_duplicates", "false"))
+	if err != nil {
+		validationErrors["skip_duplicates"] = []string{"Must be true or false"}
+	}
+	opts.skipDuplicates = skipDuplicates
+
+	opts.broker = strings.TrimSpace(c.PostForm("broker"))
+
+	if currency := strings.ToUpper(strings.TrimSpace(c.PostForm("currency"))); currency != "" {
+		if !utils.CurrencyRegex.MatchString(currency) {
+			validationErrors["currency"] = []string{"Must be a valid 3-letter ISO currency code"}
+		}
+		opts.currency = currency
+	}
+
+	if accountParam := strings.TrimSpace(c.PostForm("account_id")); accountParam != "" {
+		accountID, err := uuid.Parse(accountParam)
+		if err != nil {
+			validationErrors["account_id"] = []string{"Invalid account ID format"}
+		} else {
+			opts.accountID = &accountID
+		}
+	}
+
+	return opts, validationErrors
+}
+
+// previewImportRow applies the request's overrides to a row read from the file and validates it
+// like a created transaction
+func previewImportRow(row importer.Row, opts importOptions) (ImportRow, models.Transaction) {
+	data := row.Transaction
+	if opts.broker != "" {
+		data.Broker = opts.broker
+	} else if opts.accountID != nil {
+		// The account's broker is used instead of the profile's
+		data.Broker = ""
+	}
+	if opts.currency != "" {
+		data.Currency = opts.currency
+	}
+	if opts.accountID != nil {
+		data.AccountID = opts.accountID.String()
+	}
+
+	importRow := ImportRow{Line: row.Line, Transaction: data}
+	if row.Error != "" {
+		importRow.Errors = []string{row.Error}
+		return importRow, models.Transaction{}
+	}
+
+	req := TransactionRequest{
+		Symbol:     data.Symbol,
+		Exchange:   data.Exchange,
+		Broker:     data.Broker,
+		AccountID:  opts.accountID,
+		Currency:   data.Currency,
+		TradeDate:  data.TransactionDate,
+		TradeType:  data.TradeType,
+		Quantity:   data.Quantity,
+		Price:      data.Price,
+		Amount:     data.Amount,
+		UserNotes:  data.UserNotes,
+		SplitRatio: data.SplitRatio,
+	}
+	if err := validateTransaction(req); err != nil {
+		importRow.Errors = []string{err.Error()}
+		return importRow, models.Transaction{}
+	}
+
+	transactionDate, _ := time.Parse("2006-01-02", req.TradeDate)
+	transaction := requestToTransaction(req, transactionDate)
+	if data.ExternalID != "" {
+		externalID := data.ExternalID
+		transaction.ExternalID = &externalID
+	}
+	importRow.Valid = true
+	return importRow, transaction
+}
@@ -1,25 +1,34 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/constants"
 	"github.com/transaction-tracker/backend/internal/models"
 	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/utils"
 )
 
 // PortfolioHandler handles portfolio-related HTTP requests
 type PortfolioHandler struct {
 	portfolioService *services.PortfolioService
+	taxLotService    *services.TaxLotService
+	rebalanceService *services.RebalanceService
 }
 
 // NewPortfolioHandler creates a new portfolio handler
-func NewPortfolioHandler(portfolioService *services.PortfolioService) *PortfolioHandler {
+func NewPortfolioHandler(portfolioService *services.PortfolioService, taxLotService *services.TaxLotService, rebalanceService *services.RebalanceService) *PortfolioHandler {
 	return &PortfolioHandler{
 		portfolioService: portfolioService,
+		taxLotService:    taxLotService,
+		rebalanceService: rebalanceService,
 	}
 }
 
@@ -59,9 +68,17 @@ func (h *PortfolioHandler) GetSingleHoldingBasicInfo(c *gin.Context) {
 		return
 	}
 
+	opts, ok := parsePortfolioOptions(c)
+	if !ok {
+		return
+	}
+
 	// Get stock basic info from service
-	holdingInfo, err := h.portfolioService.GetSingleHoldingBasicInfo(c.Request.Context(), userID, symbol)
+	holdingInfo, err := h.portfolioService.GetSingleHoldingBasicInfo(c.Request.Context(), userID, symbol, opts)
 	if err != nil {
+		if respondAccountNotFound(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "no transactions found") || strings.Contains(err.Error(), "no current holdings") {
 			c.JSON(http.StatusNotFound, gin.H{
 				"success": false,
@@ -96,6 +113,59 @@ func (h *PortfolioHandler) GetSingleHoldingBasicInfo(c *gin.Context) {
 	})
 }
 
+// GetTaxLots handles GET /api/v1/portfolio/holdings/{symbol}/lots
+func (h *PortfolioHandler) GetTaxLots(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	symbol := strings.TrimSpace(strings.ToUpper(c.Param("symbol")))
+	if len(symbol) < 1 || len(symbol) > 10 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Symbol must be 1-10 characters",
+		})
+		return
+	}
+
+	opts, ok := parsePortfolioOptions(c)
+	if !ok {
+		return
+	}
+
+	var lots *models.TaxLotsResponse
+	var err error
+	if opts.AccountID != nil {
+		lots, err = h.taxLotService.GetAccountTaxLots(userID, *opts.AccountID, symbol, opts.CostBasisMethod)
+	} else {
+		lots, err = h.taxLotService.GetTaxLots(userID, symbol, opts.CostBasisMethod)
+	}
+	if err != nil {
+		if respondAccountNotFound(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "no transactions found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to get tax lots",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Tax lots retrieved successfully",
+		"data":    lots,
+	})
+}
+
 // GetAllHoldings handles GET /api/v1/portfolio/holdings
 func (h *PortfolioHandler) GetAllHoldings(c *gin.Context) {
 	userID, ok := getUserIDFromContext(c)
@@ -103,9 +173,17 @@ func (h *PortfolioHandler) GetAllHoldings(c *gin.Context) {
 		return
 	}
 
+	opts, ok := parsePortfolioOptions(c)
+	if !ok {
+		return
+	}
+
 	// Get all holdings from service
-	holdings, err := h.portfolioService.GetAllHoldings(c.Request.Context(), userID)
+	holdings, priceFailures, err := h.portfolioService.GetAllHoldings(c.Request.Context(), userID, opts)
 	if err != nil {
+		if respondAccountNotFound(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"message": "Failed to get holdings information",
@@ -115,8 +193,9 @@ func (h *PortfolioHandler) GetAllHoldings(c *gin.Context) {
 
 	// Create response
 	response := models.AllHoldingsResponse{
-		Holdings:  holdings,
-		Timestamp: time.Now(),
+		Holdings:      holdings,
+		PriceFailures: priceFailures,
+		Timestamp:     time.Now(),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -129,9 +208,17 @@ func (h *PortfolioHandler) GetPortfolioSummary(c *gin.Context) {
 		return
 	}
 
+	opts, ok := parsePortfolioOptions(c)
+	if !ok {
+		return
+	}
+
 	// Get portfolio summary from service
-	summary, err := h.portfolioService.GetPortfolioSummary(c.Request.Context(), userID)
+	summary, err := h.portfolioService.GetPortfolioSummary(c.Request.Context(), userID, opts)
 	if err != nil {
+		if respondAccountNotFound(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "failed to get current price") {
 			c.JSON(http.StatusServiceUnavailable, gin.H{
 				"success": false,
@@ -149,50 +236,338 @@ func (h *PortfolioHandler) GetPortfolioSummary(c *gin.Context) {
 	c.JSON(http.StatusOK, summary)
 }
 
-// GetHistoricalPortfolioTotalValue handles GET /api/v1/portfolio/chart/historical-market-value
-func (h *PortfolioHandler) GetHistoricalPortfolioTotalValue(c *gin.Context) {
+// GetRealizedGains handles GET /api/v1/portfolio/realized-gains
+func (h *PortfolioHandler) GetRealizedGains(c *gin.Context) {
 	userID, ok := getUserIDFromContext(c)
 	if !ok {
 		return
 	}
 
-	// Get timeframe parameter (required)
-	timeframeStr := c.Query("timeframe")
-	if timeframeStr == "" {
+	year := time.Now().Year()
+	if yearStr := c.Query("year"); yearStr != "" {
+		parsed, err := strconv.Atoi(yearStr)
+		if err != nil || parsed < 1900 || parsed > 9999 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "year must be a four-digit year (YYYY)",
+			})
+			return
+		}
+		year = parsed
+	}
+
+	opts, ok := parsePortfolioOptions(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.portfolioService.GetRealizedGains(c.Request.Context(), userID, year, opts)
+	if err != nil {
+		if respondAccountNotFound(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to get realized gains",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Realized gains retrieved successfully",
+		"data":    report,
+	})
+}
+
+// GetDividendIncome handles GET /api/v1/portfolio/dividends
+func (h *PortfolioHandler) GetDividendIncome(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	groupBy := models.DividendGrouping(strings.ToLower(c.DefaultQuery("group_by", string(models.DividendGroupingMonth))))
+	if !groupBy.IsValid() {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"message": "timeframe parameter is required",
+			"message": "Invalid group_by. Supported values: month, year",
 		})
 		return
 	}
 
-	// Validate timeframe
-	timeframe := models.TimeFrame(timeframeStr)
-	validTimeframes := []models.TimeFrame{
-		models.TimeFrame1D, models.TimeFrame1W, models.TimeFrame1M,
-		models.TimeFrame3M, models.TimeFrame6M, models.TimeFrameYTD,
-		models.TimeFrame1Y, models.TimeFrame5Y, models.TimeFrameALL,
+	opts, ok := parsePortfolioOptions(c)
+	if !ok {
+		return
 	}
 
-	isValidTimeframe := false
-	for _, vt := range validTimeframes {
-		if timeframe == vt {
-			isValidTimeframe = true
-			break
+	report, err := h.portfolioService.GetDividendIncome(c.Request.Context(), userID, groupBy, opts)
+	if err != nil {
+		if respondAccountNotFound(c, err) {
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to get dividend income",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Dividend income retrieved successfully",
+		"data":    report,
+	})
+}
+
+// GetAllocation handles GET /api/v1/portfolio/allocation
+func (h *PortfolioHandler) GetAllocation(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
 	}
 
-	if !isValidTimeframe {
+	groupBy := models.AllocationGrouping(strings.ToLower(c.DefaultQuery("group_by", string(models.AllocationGroupingSector))))
+	if !groupBy.IsValid() {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"message": "Invalid timeframe. Supported values: 1D, 1W, 1M, 3M, 6M, YTD, 1Y, 5Y, ALL",
+			"message": "Invalid group_by. Supported values: sector, asset_class, country, broker, currency",
+		})
+		return
+	}
+
+	opts, ok := parsePortfolioOptions(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.portfolioService.GetAllocation(c.Request.Context(), userID, groupBy, opts)
+	if err != nil {
+		if respondAccountNotFound(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to get allocation",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Allocation retrieved successfully",
+		"data":    report,
+	})
+}
+
+// TargetAllocationRequest represents the request structure for replacing the target allocation
+type TargetAllocationRequest struct {
+	Targets []TargetRequest `json:"targets"`
+}
+
+// TargetRequest represents the target weight of one symbol or asset class
+type TargetRequest struct {
+	Type   models.TargetType `json:"type" binding:"required"`
+	Key    string            `json:"key" binding:"required"`
+	Weight float64           `json:"weight"`
+}
+
+// RebalanceRequest represents the request structure for computing rebalancing trades
+type RebalanceRequest struct {
+	NoSells      bool    `json:"no_sells"`
+	MinTradeSize float64 `json:"min_trade_size"`
+	WholeShares  bool    `json:"whole_shares"`
+	Cash         float64 `json:"cash"` // extra cash to invest on top of the portfolio's cash balance
+}
+
+// GetTargetAllocation handles GET /api/v1/portfolio/targets
+func (h *PortfolioHandler) GetTargetAllocation(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	targets, err := h.rebalanceService.GetTargets(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to get target allocation",
 		})
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Target allocation retrieved successfully",
+		"data":    gin.H{"targets": targets},
+	})
+}
+
+// UpdateTargetAllocation handles PUT /api/v1/portfolio/targets, replacing every saved target
+func (h *PortfolioHandler) UpdateTargetAllocation(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	var req TargetAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid request format", "errors": map[string][]string{"json": {err.Error()}}})
+		return
+	}
+
+	var validationErrors []string
+	var totalWeight float64
+	seen := make(map[string]bool)
+	targets := make([]models.TargetAllocation, 0, len(req.Targets))
+	for i, target := range req.Targets {
+		target.Type = models.TargetType(strings.ToLower(strings.TrimSpace(string(target.Type))))
+		switch target.Type {
+		case models.TargetTypeSymbol:
+			target.Key = strings.ToUpper(strings.TrimSpace(target.Key))
+			if !utils.SymbolRegex.MatchString(target.Key) {
+				validationErrors = append(validationErrors, fmt.Sprintf("targets[%d]: invalid symbol format", i))
+			}
+		case models.TargetTypeAssetClass:
+			target.Key = strings.ToLower(strings.TrimSpace(target.Key))
+			if target.Key == "" || len(target.Key) > 50 {
+				validationErrors = append(validationErrors, fmt.Sprintf("targets[%d]: asset class must be between 1 and 50 characters", i))
+			}
+		default:
+			validationErrors = append(validationErrors, fmt.Sprintf("targets[%d]: type must be one of: symbol, asset_class", i))
+		}
+		if target.Weight <= 0 || target.Weight > 100 {
+			validationErrors = append(validationErrors, fmt.Sprintf("targets[%d]: weight must be greater than 0 and at most 100", i))
+		}
+		if id := string(target.Type) + ":" + target.Key; seen[id] {
+			validationErrors = append(validationErrors, fmt.Sprintf("targets[%d]: duplicate target %s", i, target.Key))
+		} else {
+			seen[id] = true
+		}
+		totalWeight += target.Weight
+		targets = append(targets, models.TargetAllocation{Type: target.Type, Key: target.Key, Weight: target.Weight})
+	}
+	if totalWeight > 100+1e-9 {
+		validationErrors = append(validationErrors, "target weights must not add up to more than 100")
+	}
+	if len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Validation failed", "errors": map[string][]string{"validation": validationErrors}})
+		return
+	}
+
+	saved, err := h.rebalanceService.ReplaceTargets(userID, targets)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to save target allocation",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Target allocation saved successfully",
+		"data":    gin.H{"targets": saved},
+	})
+}
+
+// Rebalance handles POST /api/v1/portfolio/rebalance
+func (h *PortfolioHandler) Rebalance(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	var req RebalanceRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid request format", "errors": map[string][]string{"json": {err.Error()}}})
+			return
+		}
+	}
+
+	var validationErrors []string
+	if req.MinTradeSize < 0 {
+		validationErrors = append(validationErrors, "min_trade_size must not be negative")
+	}
+	if req.Cash < 0 {
+		validationErrors = append(validationErrors, "cash must not be negative")
+	}
+	if len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Validation failed", "errors": map[string][]string{"validation": validationErrors}})
+		return
+	}
+
+	opts, ok := parsePortfolioOptions(c)
+	if !ok {
+		return
+	}
+
+	rebalanceOpts := services.RebalanceOptions{
+		NoSells:      req.NoSells,
+		MinTradeSize: req.MinTradeSize,
+		WholeShares:  req.WholeShares,
+	}
+	plan, err := h.rebalanceService.Rebalance(c.Request.Context(), userID, req.Cash, rebalanceOpts, opts)
+	if err != nil {
+		if respondAccountNotFound(c, err) {
+			return
+		}
+		if err.Error() == "no_targets" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "No target allocation saved. Set targets with PUT " + constants.APIVersion + constants.PortfolioTargetsEndpoint,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to compute rebalancing trades",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Rebalancing trades computed successfully",
+		"data":    plan,
+	})
+}
+
+// GetHistoricalPortfolioTotalValue handles GET /api/v1/portfolio/chart/historical-market-value
+func (h *PortfolioHandler) GetHistoricalPortfolioTotalValue(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	chartRange, ok := parseChartRange(c)
+	if !ok {
+		return
+	}
+
+	opts, ok := parsePortfolioOptions(c)
+	if !ok {
+		return
+	}
+
+	// Optional benchmark symbol to compare against
+	if opts.Benchmark, ok = parseBenchmark(c, ""); !ok {
+		return
+	}
+
 	// Get historical total value data
-	historicalData, err := h.portfolioService.GetHistoricalPortfolioTotalValue(c.Request.Context(), userID, timeframe)
+	historicalData, err := h.portfolioService.GetHistoricalPortfolioTotalValue(c.Request.Context(), userID, chartRange, opts)
 	if err != nil {
+		if respondAccountNotFound(c, err) {
+			return
+		}
+		if respondTooManyPoints(c, err) {
+			return
+		}
+		if respondBenchmarkNotFound(c, err, opts.Benchmark) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"message": "Failed to get historical total value data",
@@ -207,6 +582,269 @@ func (h *PortfolioHandler) GetHistoricalPortfolioTotalValue(c *gin.Context) {
 	})
 }
 
+// GetHoldingChart handles GET /api/v1/portfolio/holdings/:symbol/chart
+func (h *PortfolioHandler) GetHoldingChart(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	symbol := strings.TrimSpace(strings.ToUpper(c.Param("symbol")))
+	if len(symbol) < 1 || len(symbol) > 10 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Symbol must be 1-10 characters",
+		})
+		return
+	}
+
+	chartRange, ok := parseChartRange(c)
+	if !ok {
+		return
+	}
+
+	opts, ok := parsePortfolioOptions(c)
+	if !ok {
+		return
+	}
+
+	chart, err := h.portfolioService.GetHoldingChart(c.Request.Context(), userID, symbol, chartRange, opts)
+	if err != nil {
+		if respondAccountNotFound(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "no transactions found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+		if respondTooManyPoints(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to get holding chart",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    chart,
+	})
+}
+
+// GetRiskMetrics handles GET /api/v1/portfolio/risk
+func (h *PortfolioHandler) GetRiskMetrics(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Risk needs a series of daily returns, so intraday timeframes are not supported
+	timeframe := models.TimeFrame(c.DefaultQuery("timeframe", string(models.TimeFrame1Y)))
+	if !timeframe.IsValid() || timeframe == models.TimeFrame1D {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid timeframe. Supported values: 1W, 1M, 3M, 6M, YTD, 1Y, 5Y, ALL",
+		})
+		return
+	}
+
+	// Risk-free rate is an annual percentage
+	riskFreeRate := constants.DefaultRiskFreeRate
+	if rateParam := strings.TrimSpace(c.Query("risk_free_rate")); rateParam != "" {
+		rate, err := strconv.ParseFloat(rateParam, 64)
+		if err != nil || rate < 0 || rate > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid risk_free_rate. Must be a percentage between 0 and 100",
+			})
+			return
+		}
+		riskFreeRate = rate
+	}
+
+	opts, ok := parsePortfolioOptions(c)
+	if !ok {
+		return
+	}
+	if opts.Benchmark, ok = parseBenchmark(c, constants.DefaultBenchmark); !ok {
+		return
+	}
+
+	metrics, err := h.portfolioService.GetRiskMetrics(c.Request.Context(), userID, timeframe, riskFreeRate/100, opts)
+	if err != nil {
+		if respondAccountNotFound(c, err) {
+			return
+		}
+		if respondBenchmarkNotFound(c, err, opts.Benchmark) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to get risk metrics",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Risk metrics retrieved successfully",
+		"data":    metrics,
+	})
+}
+
+// parsePortfolioOptions parses the optional portfolio calculation query parameters
+func parsePortfolioOptions(c *gin.Context) (services.PortfolioOptions, bool) {
+	var opts services.PortfolioOptions
+
+	if methodParam := strings.TrimSpace(strings.ToLower(c.Query("cost_basis_method"))); methodParam != "" {
+		method := models.CostBasisMethod(methodParam)
+		if !method.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid cost_basis_method. Supported values: average, fifo, lifo, hifo, specific_lot",
+			})
+			return opts, false
+		}
+		opts.CostBasisMethod = method
+	}
+
+	if accountParam := strings.TrimSpace(c.Query("account_id")); accountParam != "" {
+		accountID, err := uuid.Parse(accountParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid account_id format",
+			})
+			return opts, false
+		}
+		opts.AccountID = &accountID
+	}
+
+	return opts, true
+}
+
+// parseChartRange reads the historical chart's period, either a timeframe or from/to dates, and its
+// optional granularity. It writes a bad request response and returns false when they are invalid.
+func parseChartRange(c *gin.Context) (services.ChartRange, bool) {
+	var chartRange services.ChartRange
+	badRequest := func(message string) (services.ChartRange, bool) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": message})
+		return chartRange, false
+	}
+
+	timeframeStr := c.Query("timeframe")
+	fromStr := c.Query("from")
+	toStr := c.Query("to")
+
+	switch {
+	case fromStr == "" && toStr != "":
+		return badRequest("from parameter is required when to is set")
+	case fromStr != "" && timeframeStr != "":
+		return badRequest("Use either timeframe or from/to, not both")
+	case fromStr == "" && timeframeStr == "":
+		return badRequest("timeframe or from parameter is required")
+	}
+
+	if timeframeStr != "" {
+		chartRange.TimeFrame = models.TimeFrame(timeframeStr)
+		if !chartRange.TimeFrame.IsValid() {
+			return badRequest("Invalid timeframe. Supported values: 1D, 1W, 1M, 3M, 6M, YTD, 1Y, 5Y, ALL")
+		}
+	}
+
+	if fromStr != "" {
+		from, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return badRequest("Invalid from date. Use YYYY-MM-DD")
+		}
+		if from.After(time.Now()) {
+			return badRequest("from date cannot be in the future")
+		}
+		chartRange.From = &from
+
+		if toStr != "" {
+			to, err := time.Parse("2006-01-02", toStr)
+			if err != nil {
+				return badRequest("Invalid to date. Use YYYY-MM-DD")
+			}
+			if to.Before(from) {
+				return badRequest("to date must not be before from date")
+			}
+			// The to date is inclusive
+			to = to.AddDate(0, 0, 1).Add(-time.Second)
+			chartRange.To = &to
+		}
+	}
+
+	if granularityStr := strings.TrimSpace(strings.ToLower(c.Query("granularity"))); granularityStr != "" {
+		chartRange.Granularity = models.Granularity(granularityStr)
+		if !chartRange.Granularity.IsValid() || chartRange.Granularity == models.GranularityHourly {
+			return badRequest("Invalid granularity. Supported values: daily, weekly, monthly")
+		}
+	}
+
+	return chartRange, true
+}
+
+// parseBenchmark reads the benchmark query parameter, writing a bad request response when it is invalid
+func parseBenchmark(c *gin.Context, defaultSymbol string) (string, bool) {
+	benchmark := strings.TrimSpace(strings.ToUpper(c.Query("benchmark")))
+	if benchmark == "" {
+		return defaultSymbol, true
+	}
+	if !utils.SymbolRegex.MatchString(benchmark) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid benchmark symbol format",
+		})
+		return "", false
+	}
+	return benchmark, true
+}
+
+// respondBenchmarkNotFound writes a not found response when err reports a benchmark without prices
+func respondBenchmarkNotFound(c *gin.Context, err error, benchmark string) bool {
+	if !strings.HasPrefix(err.Error(), "benchmark_not_found") {
+		return false
+	}
+	c.JSON(http.StatusNotFound, gin.H{
+		"success": false,
+		"message": "No price history found for benchmark " + benchmark,
+	})
+	return true
+}
+
+// respondAccountNotFound writes a not found response when err reports an unknown account filter
+func respondAccountNotFound(c *gin.Context, err error) bool {
+	if !errors.Is(err, services.ErrAccountNotFound) {
+		return false
+	}
+	c.JSON(http.StatusNotFound, gin.H{
+		"success": false,
+		"message": "Account does not exist",
+	})
+	return true
+}
+
+// respondTooManyPoints writes a bad request response when err reports a chart over the data point limit
+func respondTooManyPoints(c *gin.Context, err error) bool {
+	if !strings.HasPrefix(err.Error(), "too_many_points") {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"success": false,
+		"message": "Too many data points: " + strings.TrimPrefix(err.Error(), "too_many_points: ") + ". Choose a coarser granularity or a shorter period",
+	})
+	return true
+}
+
 // getUserIDFromContext extracts and validates user_id from gin.Context
 func getUserIDFromContext(c *gin.Context) (uuid.UUID, bool) {
 	userIDStr, exists := c.Get("user_id")
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/transaction-tracker/backend/config"
 	"github.com/transaction-tracker/backend/internal/ai"
 	"github.com/transaction-tracker/backend/internal/provider"
@@ -14,18 +16,38 @@ type Handlers struct {
 	ExtractTransactionsHandler *ExtractTransactionHandler
 	Auth                       *AuthHandler
 	Portfolio                  *PortfolioHandler
+	Accounts                   *AccountsHandler
 }
 
 // InitHandlers wires up all dependencies and returns a Handlers struct
 func InitHandlers(db *gorm.DB, cfg *config.Config) *Handlers {
 	transactionRepo := repositories.NewTransactionRepository(db)
-	transactionService := services.NewTransactionService(transactionRepo)
+	userRepo := repositories.NewUserRepository(db)
+
+	// Initialize Account Service
+	accountRepo := repositories.NewAccountRepository(db)
+	accountService := services.NewAccountService(accountRepo, transactionRepo)
+
+	// Initialize Tax Lot Service
+	taxLotRepo := repositories.NewTaxLotRepository(db)
+	taxLotService := services.NewTaxLotService(transactionRepo, taxLotRepo, accountRepo, userRepo)
 
 	// Initialize Price Service Manager
 	priceServiceManager := provider.NewPriceServiceManager(cfg)
 
 	// Initialize Portfolio Service
-	portfolioService := services.NewPortfolioService(transactionRepo, priceServiceManager)
+	snapshotRepo := repositories.NewPortfolioSnapshotRepository(db)
+	portfolioService := services.NewPortfolioService(transactionRepo, userRepo, accountRepo, snapshotRepo, priceServiceManager)
+
+	// Initialize the portfolio snapshot job, which runs in the background for the life of the process
+	snapshotService := services.NewSnapshotService(snapshotRepo, transactionRepo, portfolioService)
+	snapshotService.Start(context.Background(), cfg.SnapshotInterval)
+
+	transactionService := services.NewTransactionService(transactionRepo, taxLotService, accountRepo, snapshotService)
+
+	// Initialize Rebalance Service
+	targetAllocationRepo := repositories.NewTargetAllocationRepository(db)
+	rebalanceService := services.NewRebalanceService(targetAllocationRepo, portfolioService, priceServiceManager)
 
 	// Initialize AI client once for reuse
 	aiClient, err := ai.NewClient(cfg)
@@ -34,9 +56,10 @@ func InitHandlers(db *gorm.DB, cfg *config.Config) *Handlers {
 	}
 
 	return &Handlers{
-		Transactions:               NewTransactionsHandler(transactionService),
+		Transactions:               NewTransactionsHandler(transactionService, portfolioService),
 		ExtractTransactionsHandler: NewExtractTransactionsHandler(cfg, aiClient),
 		Auth:                       NewAuthHandler(db, cfg),
-		Portfolio:                  NewPortfolioHandler(portfolioService),
+		Portfolio:                  NewPortfolioHandler(portfolioService, taxLotService, rebalanceService),
+		Accounts:                   NewAccountsHandler(accountService),
 	}
 }
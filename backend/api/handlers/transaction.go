@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -8,6 +9,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/constants"
+	"github.com/transaction-tracker/backend/internal/logger"
 	"github.com/transaction-tracker/backend/internal/models"
 	"github.com/transaction-tracker/backend/internal/services"
 	"github.com/transaction-tracker/backend/internal/types"
@@ -15,32 +18,42 @@ import (
 )
 
 // NewTransactionsHandler creates a new TransactionsHandler
-func NewTransactionsHandler(service *services.TransactionService) *TransactionsHandler {
-	return &TransactionsHandler{transactionService: service}
+func NewTransactionsHandler(service *services.TransactionService, portfolioService *services.PortfolioService) *TransactionsHandler {
+	return &TransactionsHandler{transactionService: service, portfolioService: portfolioService}
 }
 
 // TransactionsHandler handles transaction-related endpoints
 type TransactionsHandler struct {
 	transactionService *services.TransactionService
+	portfolioService   *services.PortfolioService
 }
 
 // TransactionRequest represents the request structure for creating transactions
 type TransactionRequest struct {
-	Symbol    string          `json:"symbol" binding:"required"`
-	Exchange  string          `json:"exchange"`
-	Broker    string          `json:"broker"`
+	Symbol   string `json:"symbol"` // optional for cash movements
+	Exchange string `json:"exchange"`
+	Broker   string `json:"broker"`
+	// AccountID optionally names the user's account; Broker defaults to the account's broker
+	AccountID *uuid.UUID      `json:"account_id"`
 	Currency  string          `json:"currency" binding:"required"`
 	TradeDate string          `json:"transaction_date" binding:"required"`
 	TradeType types.TradeType `json:"trade_type" binding:"required"`
-	Quantity  float64         `json:"quantity" binding:"required,gt=0"`
-	Price     float64         `json:"price" binding:"required,gt=0"`
-	Amount    float64         `json:"amount" binding:"required,gt=0"`
+	Quantity  float64         `json:"quantity" binding:"gte=0"`
+	Price     float64         `json:"price" binding:"gte=0"`
+	Amount    float64         `json:"amount" binding:"gte=0"`
 	UserNotes string          `json:"user_notes"`
+	// LotSelections optionally names the lots a Sell relieves under specific lot identification
+	LotSelections []types.LotSelection `json:"lot_selections"`
+	// SplitRatio is the number of new shares per old share of a Split (4 for 4:1, 0.1 for 1:10)
+	SplitRatio float64 `json:"split_ratio" binding:"gte=0"`
 }
 
 // CreateTransactionsRequest represents the batch request for creating transactions
 type CreateTransactionsRequest struct {
 	Transactions []TransactionRequest `json:"transactions" binding:"required,min=1"`
+	// SkipDuplicates leaves out the transactions that match an existing one, so that sending the
+	// same batch again creates nothing
+	SkipDuplicates bool `json:"skip_duplicates"`
 }
 
 // CreateTransactionsResponse represents the response for creating transactions
@@ -55,6 +68,17 @@ type CreateTransactionsResponse struct {
 type CreateTransactionsData struct {
 	Transactions []types.TransactionData `json:"transactions"`
 	Count        int                     `json:"count"`
+	Rows         []CreateTransactionRow  `json:"rows"` // one per requested transaction, in order
+	SkippedCount int                     `json:"skipped_count"`
+}
+
+// CreateTransactionRow reports whether a requested transaction may duplicate an existing one, that
+// is, has the same symbol, date, type, quantity, price and broker
+type CreateTransactionRow struct {
+	Index             int    `json:"index"`
+	PossibleDuplicate bool   `json:"possible_duplicate"`
+	DuplicateOf       string `json:"duplicate_of,omitempty"` // ID of the matching transaction
+	Skipped           bool   `json:"skipped"`
 }
 
 // GetTransactionsResponse represents the response for getting transaction history
@@ -108,10 +132,58 @@ func modelToTransactionData(transaction models.Transaction) types.TransactionDat
 		Amount:          transaction.Amount,
 		Currency:        transaction.Currency,
 		Broker:          transaction.Broker,
+		AccountID:       accountID(transaction),
 		Exchange:        transaction.Exchange,
 		TransactionDate: transaction.TransactionDate.Format("2006-01-02"),
 		UserNotes:       transaction.UserNotes,
+		LotSelections:   transaction.LotSelections,
+		SplitRatio:      transaction.SplitRatio,
+		LinkedID:        linkedTransactionID(transaction),
+		ExternalID:      externalID(transaction),
+	}
+}
+
+// externalID returns the institution's ID of an imported transaction, if any
+func externalID(transaction models.Transaction) string {
+	if transaction.ExternalID == nil {
+		return ""
 	}
+	return *transaction.ExternalID
+}
+
+// requestToTransaction converts a validated request transaction to a models.Transaction
+func requestToTransaction(req TransactionRequest, transactionDate time.Time) models.Transaction {
+	return models.Transaction{
+		TradeType:       req.TradeType,
+		Symbol:          req.Symbol,
+		Quantity:        req.Quantity,
+		Price:           req.Price,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		Broker:          req.Broker,
+		AccountID:       req.AccountID,
+		Exchange:        req.Exchange,
+		TransactionDate: transactionDate,
+		UserNotes:       req.UserNotes,
+		LotSelections:   req.LotSelections,
+		SplitRatio:      req.SplitRatio,
+	}
+}
+
+// linkedTransactionID returns the ID of a transaction's reinvested dividend pair, if any
+func linkedTransactionID(transaction models.Transaction) string {
+	if transaction.LinkedTransactionID == nil {
+		return ""
+	}
+	return transaction.LinkedTransactionID.String()
+}
+
+// accountID returns the ID of the account a transaction was made in, if any
+func accountID(transaction models.Transaction) string {
+	if transaction.AccountID == nil {
+		return ""
+	}
+	return transaction.AccountID.String()
 }
 
 // modelsToTransactionData converts a slice of models.Transaction to []types.TransactionData
@@ -159,20 +231,7 @@ func (h *TransactionsHandler) CreateTransactions(c *gin.Context) {
 		}
 
 		// Convert request transaction to model transaction
-		transaction := models.Transaction{
-			TradeType:       reqTransaction.TradeType,
-			Symbol:          reqTransaction.Symbol,
-			Quantity:        reqTransaction.Quantity,
-			Price:           reqTransaction.Price,
-			Amount:          reqTransaction.Amount,
-			Currency:        reqTransaction.Currency,
-			Broker:          reqTransaction.Broker,
-			Exchange:        reqTransaction.Exchange,
-			TransactionDate: transactionDate,
-			UserNotes:       reqTransaction.UserNotes,
-		}
-
-		validatedTransactions = append(validatedTransactions, transaction)
+		validatedTransactions = append(validatedTransactions, requestToTransaction(reqTransaction, transactionDate))
 	}
 
 	// If there were validation errors, return them
@@ -207,8 +266,16 @@ func (h *TransactionsHandler) CreateTransactions(c *gin.Context) {
 	}
 
 	// Create transactions using injected service
-	createdTransactions, err := h.transactionService.CreateTransactions(userUUID, validatedTransactions)
+	result, err := h.transactionService.CreateTransactions(userUUID, validatedTransactions, req.SkipDuplicates)
 	if err != nil {
+		if errors.Is(err, services.ErrAccountNotFound) {
+			c.JSON(http.StatusBadRequest, CreateTransactionsResponse{
+				Success: false,
+				Message: "Validation failed",
+				Errors:  map[string][]string{"account_id": {err.Error()}},
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, CreateTransactionsResponse{
 			Success: false,
 			Message: "Failed to create transactions",
@@ -218,18 +285,38 @@ func (h *TransactionsHandler) CreateTransactions(c *gin.Context) {
 	}
 
 	// Convert created transactions to response format
-	responseTransactions := modelsToTransactionData(createdTransactions)
+	responseTransactions := modelsToTransactionData(result.Created)
 
-	c.JSON(http.StatusCreated, CreateTransactionsResponse{
+	status, message := http.StatusCreated, "Transactions created successfully"
+	if len(responseTransactions) == 0 {
+		status, message = http.StatusOK, "All transactions already exist; nothing was created"
+	}
+	c.JSON(status, CreateTransactionsResponse{
 		Success: true,
-		Message: "Transactions created successfully",
+		Message: message,
 		Data: &CreateTransactionsData{
 			Transactions: responseTransactions,
 			Count:        len(responseTransactions),
+			Rows:         duplicateRows(result.DuplicateOf, req.SkipDuplicates),
+			SkippedCount: result.Skipped,
 		},
 	})
 }
 
+// duplicateRows reports the possible duplicates found by a batch create, one row per transaction
+func duplicateRows(duplicateOf []*uuid.UUID, skipDuplicates bool) []CreateTransactionRow {
+	rows := make([]CreateTransactionRow, len(duplicateOf))
+	for i, id := range duplicateOf {
+		rows[i] = CreateTransactionRow{Index: i}
+		if id != nil {
+			rows[i].PossibleDuplicate = true
+			rows[i].DuplicateOf = id.String()
+			rows[i].Skipped = skipDuplicates
+		}
+	}
+	return rows
+}
+
 // GetTransactionHistory handles GET /transaction-history/ endpoint
 func (h *TransactionsHandler) GetTransactionHistory(c *gin.Context) {
 	// Extract user ID from JWT context
@@ -303,6 +390,7 @@ func (h *TransactionsHandler) GetTransactionHistory(c *gin.Context) {
 
 	// Convert transactions to response format (exclude user_id for security)
 	responseTransactions := modelsToTransactionData(transactions)
+	h.attachWashSaleFlags(userUUID, transactions, responseTransactions)
 
 	// Calculate pagination
 	totalPages := int((totalCount + int64(params.PageSize) - 1) / int64(params.PageSize))
@@ -351,6 +439,31 @@ func (h *TransactionsHandler) GetTransactionHistory(c *gin.Context) {
 	})
 }
 
+// attachWashSaleFlags marks the history entries involved in a wash sale.
+// Flags are informational, so a detection failure leaves the entries unflagged.
+func (h *TransactionsHandler) attachWashSaleFlags(userID uuid.UUID, transactions []models.Transaction, data []types.TransactionData) {
+	if h.portfolioService == nil || len(transactions) == 0 {
+		return
+	}
+
+	symbols := make([]string, 0, len(transactions))
+	for _, tx := range transactions {
+		symbols = append(symbols, tx.Symbol)
+	}
+
+	flags, err := h.portfolioService.GetWashSaleFlags(userID, symbols)
+	if err != nil {
+		logger.Warn("Failed to detect wash sales", logger.H{"user_id": userID.String(), "error": err.Error()})
+		return
+	}
+
+	for i, tx := range transactions {
+		if flag, ok := flags[tx.TransactionID]; ok {
+			data[i].WashSale = &flag
+		}
+	}
+}
+
 // UpdateTransaction handles PUT /transaction-history/:id
 func (h *TransactionsHandler) UpdateTransaction(c *gin.Context) {
 	idParam := c.Param("id")
@@ -375,6 +488,10 @@ func (h *TransactionsHandler) UpdateTransaction(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Validation failed", "errors": map[string][]string{"validation": {err.Error()}}})
 		return
 	}
+	if req.TradeType == types.TradeTypeDividendReinvestment {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Validation failed", "errors": map[string][]string{"validation": {"DividendReinvestment can only be used when creating transactions"}}})
+		return
+	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -400,8 +517,19 @@ func (h *TransactionsHandler) UpdateTransaction(c *gin.Context) {
 		req.Price,
 		req.Amount,
 		req.UserNotes,
+		req.LotSelections,
+		req.SplitRatio,
+		req.AccountID,
 	)
 	if err != nil {
+		if errors.Is(err, services.ErrAccountNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Validation failed", "errors": map[string][]string{"account_id": {"Account does not exist"}}})
+			return
+		}
+		if errors.Is(err, services.ErrLinkedTransaction) {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Transaction is part of a reinvested dividend", "errors": map[string][]string{"linked_transaction_id": {"Delete the reinvested dividend and create it again to change it"}}})
+			return
+		}
 		switch err.Error() {
 		case "not_found":
 			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Transaction does not exist"})
@@ -424,14 +552,18 @@ func (h *TransactionsHandler) UpdateTransaction(c *gin.Context) {
 
 // validateTransaction validates a single transaction request
 func validateTransaction(transaction TransactionRequest) error {
-	// Validate symbol length
-	if len(transaction.Symbol) == 0 || len(transaction.Symbol) > 10 {
-		return fmt.Errorf("symbol must be between 1 and 10 characters")
-	}
+	// Cash movements may omit the symbol or relate to a security, e.g. a trading fee
+	cashMovement := transaction.TradeType.IsCashMovement()
+	if !cashMovement || (transaction.Symbol != "" && transaction.Symbol != types.CashSymbol) {
+		// Validate symbol length
+		if len(transaction.Symbol) == 0 || len(transaction.Symbol) > 10 {
+			return fmt.Errorf("symbol must be between 1 and 10 characters")
+		}
 
-	// Validate symbol format (alphanumeric uppercase, allow dot for e.g. BRK.B)
-	if !utils.SymbolRegex.MatchString(transaction.Symbol) {
-		return fmt.Errorf("symbol must contain only uppercase letters, numbers, and optionally a single dot (e.g. BRK.B)")
+		// Validate symbol format (alphanumeric uppercase, allow dot for e.g. BRK.B)
+		if !utils.SymbolRegex.MatchString(transaction.Symbol) {
+			return fmt.Errorf("symbol must contain only uppercase letters, numbers, and optionally a single dot (e.g. BRK.B)")
+		}
 	}
 
 	// Validate currency
@@ -439,18 +571,35 @@ func validateTransaction(transaction TransactionRequest) error {
 		return fmt.Errorf("currency must be a 3-letter ISO code")
 	}
 
-	// Validate trade type
-	validTradeTypes := map[types.TradeType]bool{
-		types.TradeTypeBuy:      true,
-		types.TradeTypeSell:     true,
-		types.TradeTypeDividend: true,
+	// Validate trade type; a reinvested dividend is accepted here and stored as its two halves
+	if !transaction.TradeType.IsValid() && transaction.TradeType != types.TradeTypeDividendReinvestment {
+		return fmt.Errorf("trade_type must be one of: %s, %s", strings.Join(constants.ValidTradeTypes(), ", "), types.TradeTypeDividendReinvestment)
+	}
+
+	// Cash movements carry only an amount; the trade type gives its direction
+	if cashMovement && (transaction.Quantity != 0 || transaction.Price != 0) {
+		return fmt.Errorf("quantity and price must be 0 for %s transactions", transaction.TradeType)
 	}
-	if !validTradeTypes[transaction.TradeType] {
-		return fmt.Errorf("trade_type must be one of: Buy, Sell, Dividends")
+
+	// Validate split ratio; a split moves no cash, so quantity, price and amount must be zero
+	if transaction.TradeType == types.TradeTypeSplit {
+		if transaction.SplitRatio <= 0 || transaction.SplitRatio == 1 {
+			return fmt.Errorf("split_ratio must be positive and not equal to 1")
+		}
+		if transaction.Quantity != 0 || transaction.Price != 0 || transaction.Amount != 0 {
+			return fmt.Errorf("quantity, price and amount must be 0 for Split transactions")
+		}
+	} else {
+		if transaction.SplitRatio != 0 {
+			return fmt.Errorf("split_ratio can only be set on Split transactions")
+		}
+		if transaction.Amount <= 0 {
+			return fmt.Errorf("amount must be positive")
+		}
 	}
 
 	// Validate quantities and amounts
-	if transaction.TradeType != types.TradeTypeDividend {
+	if transaction.TradeType != types.TradeTypeDividend && transaction.TradeType != types.TradeTypeSplit && !cashMovement {
 		if transaction.Quantity <= 0 {
 			return fmt.Errorf("quantity must be positive")
 		}
@@ -491,6 +640,26 @@ func validateTransaction(transaction TransactionRequest) error {
 		return fmt.Errorf("user_notes cannot exceed 1000 characters")
 	}
 
+	// Validate specific lot selections
+	if len(transaction.LotSelections) > 0 {
+		if transaction.TradeType != types.TradeTypeSell {
+			return fmt.Errorf("lot_selections can only be set on Sell transactions")
+		}
+		var selectedQuantity float64
+		for _, selection := range transaction.LotSelections {
+			if _, err := uuid.Parse(selection.TransactionID); err != nil {
+				return fmt.Errorf("lot_selections transaction_id must be a valid UUID")
+			}
+			if selection.Quantity <= 0 {
+				return fmt.Errorf("lot_selections quantity must be positive")
+			}
+			selectedQuantity += selection.Quantity
+		}
+		if selectedQuantity > transaction.Quantity {
+			return fmt.Errorf("lot_selections quantity cannot exceed the sold quantity")
+		}
+	}
+
 	return nil
 }
 
@@ -542,7 +711,7 @@ func parseTransactionQueryParams(c *gin.Context) (params TransactionQueryParams,
 		for _, tradeType := range typeList {
 			tradeType = strings.TrimSpace(tradeType)
 			if _, ok := utils.TradeTypeFromString(tradeType); !ok {
-				validationErrors["trade_type"] = []string{"Must be one of: Buy, Sell, Dividends (comma-separated for multiple)"}
+				validationErrors["trade_type"] = []string{"Must be one of: " + strings.Join(constants.ValidTradeTypes(), ", ") + " (comma-separated for multiple)"}
 				break
 			} else {
 				validTypes = append(validTypes, tradeType)
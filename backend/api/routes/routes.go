@@ -62,19 +62,39 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 
 		api.POST(constants.LogoutEndpoint, handlersProvider.Auth.Logout)
 		api.GET(constants.MeEndpoint, handlersProvider.Auth.Me)
+		api.GET(constants.MePreferencesEndpoint, handlersProvider.Auth.GetPreferences)
+		api.PUT(constants.MePreferencesEndpoint, handlersProvider.Auth.UpdatePreferences)
 
 		api.POST(constants.ExtractTransEndpoint, handlersProvider.ExtractTransactionsHandler.ExtractTransactions)
 		api.GET(constants.TransactionHistoryEndpoint, handlersProvider.Transactions.GetTransactionHistory)
 		api.POST(constants.TransactionHistoryEndpoint, handlersProvider.Transactions.CreateTransactions)
+		api.POST(constants.TransactionImportEndpoint, handlersProvider.Transactions.ImportTransactions)
+		api.GET(constants.TransactionExportEndpoint, handlersProvider.Transactions.ExportTransactions)
 		api.PUT(constants.TransactionHistoryEndpoint+"/:id", handlersProvider.Transactions.UpdateTransaction)
 		api.DELETE(constants.TransactionHistoryEndpoint+"/:id", handlersProvider.Transactions.DeleteTransaction)
 		api.DELETE(constants.TransactionHistoryEndpoint, handlersProvider.Transactions.DeleteTransactions)
 
+		// Account routes
+		api.GET(constants.AccountsEndpoint, handlersProvider.Accounts.GetAccounts)
+		api.POST(constants.AccountsEndpoint, handlersProvider.Accounts.CreateAccount)
+		api.GET(constants.AccountsEndpoint+"/:id", handlersProvider.Accounts.GetAccount)
+		api.PUT(constants.AccountsEndpoint+"/:id", handlersProvider.Accounts.UpdateAccount)
+		api.DELETE(constants.AccountsEndpoint+"/:id", handlersProvider.Accounts.DeleteAccount)
+
 		// Portfolio routes
 		api.GET(constants.PortfolioSummaryEndpoint, handlersProvider.Portfolio.GetPortfolioSummary)
 		api.GET(constants.PortfolioHoldingsEndpoint, handlersProvider.Portfolio.GetAllHoldings)
 		api.GET(constants.PortfolioSingleHoldingEndpoint, handlersProvider.Portfolio.GetSingleHoldingBasicInfo)
+		api.GET(constants.PortfolioTaxLotsEndpoint, handlersProvider.Portfolio.GetTaxLots)
+		api.GET(constants.PortfolioHoldingChartEndpoint, handlersProvider.Portfolio.GetHoldingChart)
+		api.GET(constants.PortfolioRealizedGainsEndpoint, handlersProvider.Portfolio.GetRealizedGains)
+		api.GET(constants.PortfolioDividendsEndpoint, handlersProvider.Portfolio.GetDividendIncome)
 		api.GET(constants.PortfolioHistoricalMarketValueEndpoint, handlersProvider.Portfolio.GetHistoricalPortfolioTotalValue)
+		api.GET(constants.PortfolioRiskEndpoint, handlersProvider.Portfolio.GetRiskMetrics)
+		api.GET(constants.PortfolioAllocationEndpoint, handlersProvider.Portfolio.GetAllocation)
+		api.GET(constants.PortfolioTargetsEndpoint, handlersProvider.Portfolio.GetTargetAllocation)
+		api.PUT(constants.PortfolioTargetsEndpoint, handlersProvider.Portfolio.UpdateTargetAllocation)
+		api.POST(constants.PortfolioRebalanceEndpoint, handlersProvider.Portfolio.Rebalance)
 	}
 
 	return r
@@ -26,6 +26,8 @@ type Config struct {
 	AIMaxRetry int
 	// Price Service Configuration
 	PriceService PriceServiceConfig
+	// SnapshotInterval is how often portfolio snapshots are refreshed; zero disables the job
+	SnapshotInterval time.Duration
 }
 
 // PriceServiceConfig holds configuration for Price Service integration
@@ -108,6 +110,7 @@ func Load() (*Config, error) {
 		AITimeout:          aiTimeout,
 		AIMaxRetry:         aiMaxRetry,
 		PriceService:       priceServiceConfig,
+		SnapshotInterval:   time.Duration(getEnvOrDefaultInt("SNAPSHOT_INTERVAL_MINUTES", constants.DefaultSnapshotIntervalMinutes)) * time.Minute,
 	}, nil
 }
 
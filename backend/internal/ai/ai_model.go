@@ -156,7 +156,8 @@ func (c *AIModelClient) extractTransactionsGemini(ctx context.Context, image typ
 		}, fmt.Errorf("failed to read image %s: %w", image.Filename, readErr)
 	}
 
-	parts = append(parts, genai.ImageData(image.MimeType, imageData))
+	// The file is sent with its own MIME type, so PDF pages are sent as documents rather than images
+	parts = append(parts, genai.Blob{MIMEType: image.MimeType, Data: imageData})
 
 	// Set timeout if specified
 	if c.config.Timeout > 0 {
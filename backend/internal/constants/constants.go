@@ -12,6 +12,14 @@ const (
 	DefaultJWTExpiry  = 24
 )
 
+// Portfolio Analytics Defaults
+const (
+	DefaultBenchmark    = "SPY"
+	DefaultRiskFreeRate = 0.0 // annual percentage
+
+	DefaultSnapshotIntervalMinutes = 60
+)
+
 // API Routes and Endpoints
 const (
 	APIVersion                 = "/api/v1"
@@ -21,9 +29,13 @@ const (
 	SignupEndpoint             = "/signup"
 	LogoutEndpoint             = "/logout"
 	MeEndpoint                 = "/me"
+	MePreferencesEndpoint      = "/me/preferences"
 	HelloWorldEndpoint         = "/hello-world"
 	ExtractTransEndpoint       = "/extract-transactions"
 	TransactionHistoryEndpoint = "/transaction-history"
+	TransactionImportEndpoint  = "/transaction-history/import"
+	TransactionExportEndpoint  = "/transaction-history/export"
+	AccountsEndpoint           = "/accounts"
 )
 
 // Portfolio Endpoints
@@ -31,7 +43,15 @@ const (
 	PortfolioSummaryEndpoint               = "/portfolio/summary"
 	PortfolioHoldingsEndpoint              = "/portfolio/holdings"
 	PortfolioSingleHoldingEndpoint         = "/portfolio/holdings/:symbol"
+	PortfolioTaxLotsEndpoint               = "/portfolio/holdings/:symbol/lots"
+	PortfolioHoldingChartEndpoint          = "/portfolio/holdings/:symbol/chart"
+	PortfolioRealizedGainsEndpoint         = "/portfolio/realized-gains"
+	PortfolioDividendsEndpoint             = "/portfolio/dividends"
 	PortfolioHistoricalMarketValueEndpoint = "/portfolio/chart/historical-market-value"
+	PortfolioRiskEndpoint                  = "/portfolio/risk"
+	PortfolioAllocationEndpoint            = "/portfolio/allocation"
+	PortfolioTargetsEndpoint               = "/portfolio/targets"
+	PortfolioRebalanceEndpoint             = "/portfolio/rebalance"
 )
 
 // HTTP Headers
@@ -47,6 +67,7 @@ const (
 	MimeTypeJPEG = "image/jpeg"
 	MimeTypeGIF  = "image/gif"
 	MimeTypeWebP = "image/webp"
+	MimeTypePDF  = "application/pdf"
 
 	MimeTypeJSON = "application/json"
 	MimeTypeForm = "multipart/form-data"
@@ -64,7 +85,6 @@ const (
 	ErrMsgImageProcessingFailed = "Image processing failed"
 	ErrMsgAIRequestFailed       = "AI request failed"
 
-	ErrMsgInvalidTradeType  = "Invalid trade type, must be Buy, Sell, or Dividends"
 	ErrMsgTickerRequired    = "Ticker should not be empty"
 	ErrMsgTradeDateRequired = "TradeDate should not be empty"
 	ErrMsgTradeTypeRequired = "TradeType should not be empty"
@@ -96,29 +116,31 @@ const (
 const (
 	MaxFileSize      = 10 << 20
 	MaxFilesPerBatch = 10
+	MaxPDFPages      = 20 // each page is a separate AI request
 )
 
 // ValidTradeTypes returns a slice of valid trade types
 func ValidTradeTypes() []string {
-	return []string{
-		string(types.TradeTypeBuy),
-		string(types.TradeTypeSell),
-		string(types.TradeTypeDividend),
+	tradeTypes := make([]string, len(types.TradeTypes))
+	for i, tradeType := range types.TradeTypes {
+		tradeTypes[i] = string(tradeType)
 	}
+	return tradeTypes
 }
 
 // ValidTradeTypesMap returns a map of valid trade types for quick lookup
 func ValidTradeTypesMap() map[string]bool {
-	return map[string]bool{
-		string(types.TradeTypeBuy):      true,
-		string(types.TradeTypeSell):     true,
-		string(types.TradeTypeDividend): true,
+	tradeTypes := make(map[string]bool, len(types.TradeTypes))
+	for _, tradeType := range types.TradeTypes {
+		tradeTypes[string(tradeType)] = true
 	}
+	return tradeTypes
 }
 
-// SupportedImageMimeTypes returns a slice of supported image MIME types
+// SupportedImageMimeTypes returns a slice of the MIME types transactions can be extracted from:
+// images, and PDFs which are extracted page by page
 func SupportedImageMimeTypes() []string {
-	return []string{MimeTypePNG, MimeTypeJPEG, MimeTypeGIF, MimeTypeWebP}
+	return []string{MimeTypePNG, MimeTypeJPEG, MimeTypeGIF, MimeTypeWebP, MimeTypePDF}
 }
 
 // SupportedImageMimeTypesMap returns a map of supported image MIME types for quick lookup
@@ -128,5 +150,6 @@ func SupportedImageMimeTypesMap() map[string]bool {
 		MimeTypeJPEG: true,
 		MimeTypeGIF:  true,
 		MimeTypeWebP: true,
+		MimeTypePDF:  true,
 	}
 }
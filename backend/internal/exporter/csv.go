@@ -0,0 +1,49 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+// csvWriter writes a header row and one row per transaction
+type csvWriter struct {
+	writer *csv.Writer
+	fields []string
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(Columns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer, fields: make([]string, len(Columns))}, nil
+}
+
+func (w *csvWriter) Write(transaction types.TransactionData) error {
+	for i, value := range record(transaction) {
+		switch value := value.(type) {
+		case float64:
+			w.fields[i] = formatNumber(value)
+		case string:
+			w.fields[i] = escapeFormula(value)
+		}
+	}
+	return w.writer.Write(w.fields)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// escapeFormula keeps a spreadsheet from running text as a formula. Notes can come from
+// third-party statements, so text starting with a formula character is prefixed with a quote.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@") {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,117 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+// Format is a file format transactions can be exported to
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+	FormatXLSX Format = "xlsx"
+)
+
+// Formats lists the supported export formats
+var Formats = []Format{FormatCSV, FormatJSON, FormatXLSX}
+
+// ParseFormat returns the export format with a name, compared case-insensitively
+func ParseFormat(name string) (Format, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, format := range Formats {
+		if string(format) == name {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// ContentType returns the MIME type of files in the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/json"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Writer writes transactions to an export file one at a time, so the file can be streamed
+type Writer interface {
+	Write(transaction types.TransactionData) error
+	// Close finishes the file; it does not close the underlying writer
+	Close() error
+}
+
+// NewWriter returns a writer of the format that writes to w
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatJSON:
+		return newJSONWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// Columns are the columns of the tabular formats. The transaction fields are named like the
+// columns of the generic import profile, so an exported CSV can be imported again.
+var Columns = []string{
+	"transaction_id",
+	"transaction_date",
+	"trade_type",
+	"symbol",
+	"quantity",
+	"price",
+	"amount",
+	"currency",
+	"broker",
+	"exchange",
+	"account_id",
+	"split_ratio",
+	"user_notes",
+	"linked_transaction_id",
+	"external_id",
+}
+
+// record returns the cells of a transaction in the order of Columns; numbers are float64 and
+// everything else is a string
+func record(transaction types.TransactionData) []any {
+	var splitRatio any = ""
+	if transaction.SplitRatio != 0 {
+		splitRatio = transaction.SplitRatio
+	}
+	return []any{
+		transaction.ID,
+		transaction.TransactionDate,
+		string(transaction.TradeType),
+		transaction.Symbol,
+		transaction.Quantity,
+		transaction.Price,
+		transaction.Amount,
+		transaction.Currency,
+		transaction.Broker,
+		transaction.Exchange,
+		transaction.AccountID,
+		splitRatio,
+		transaction.UserNotes,
+		transaction.LinkedID,
+		transaction.ExternalID,
+	}
+}
+
+// formatNumber writes a number without exponent or trailing zeros
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,41 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+// jsonWriter writes a JSON array of transactions, shaped like those of the transaction history
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONWriter(w io.Writer) (*jsonWriter, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, err
+	}
+	return &jsonWriter{w: w}, nil
+}
+
+func (w *jsonWriter) Write(transaction types.TransactionData) error {
+	encoded, err := json.Marshal(transaction)
+	if err != nil {
+		return err
+	}
+	if w.count > 0 {
+		if _, err := io.WriteString(w.w, ","); err != nil {
+			return err
+		}
+	}
+	w.count++
+	_, err = w.w.Write(encoded)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	_, err := io.WriteString(w.w, "]")
+	return err
+}
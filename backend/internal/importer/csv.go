@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+// ParseCSV reads a broker's CSV export with a profile. Lines before the header row, such as account
+// details, and lines after the transactions, such as totals and disclaimers, are ignored. A line that cannot
+// be read is returned as a row with an error rather than failing the whole file.
+func ParseCSV(r io.Reader, profile Profile) (*Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var columns map[string]int
+	result := &Result{Rows: []Row{}}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if columns == nil {
+			columns = headerColumns(record, profile)
+			continue
+		}
+
+		field := func(name string) string {
+			index, ok := columns[strings.ToLower(name)]
+			if name == "" || !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+		// Totals and disclaimers after the transactions have neither an action nor a date
+		if field(profile.DateColumn) == "" {
+			continue
+		}
+		if _, err := parseDate(field(profile.DateColumn), profile.DateFormats); err != nil && field(profile.ActionColumn) == "" {
+			continue
+		}
+
+		rows, skipped := parseCSVRecord(field, profile, line)
+		if skipped {
+			result.Skipped++
+			continue
+		}
+		result.Rows = append(result.Rows, rows...)
+	}
+
+	if columns == nil {
+		return nil, fmt.Errorf("no header row with the %q and %q columns of the %s profile", profile.DateColumn, profile.ActionColumn, profile.Name)
+	}
+	return result, nil
+}
+
+// headerColumns returns the index of each column when a record is the profile's header row
+func headerColumns(record []string, profile Profile) map[string]int {
+	columns := make(map[string]int, len(record))
+	for i, name := range record {
+		// Excel prefixes UTF-8 exports with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	for _, required := range []string{profile.DateColumn, profile.ActionColumn} {
+		if _, ok := columns[strings.ToLower(required)]; !ok {
+			return nil
+		}
+	}
+	return columns
+}
+
+// parseCSVRecord turns one line of the file into its transaction, plus a Fee transaction for any
+// commission, or reports that the line's action is ignored
+func parseCSVRecord(field func(string) string, profile Profile, line int) ([]Row, bool) {
+	action := field(profile.ActionColumn)
+	tradeType, ignored, ok := profile.tradeType(action)
+	if ignored {
+		return nil, true
+	}
+
+	currency := strings.ToUpper(field(profile.CurrencyColumn))
+	if currency == "" {
+		currency = profile.Currency
+	}
+	broker := field(profile.BrokerColumn)
+	if broker == "" {
+		broker = profile.Broker
+	}
+	transaction := types.TransactionData{
+		Symbol:    normalizeSymbol(field(profile.SymbolColumn)),
+		TradeType: tradeType,
+		Currency:  currency,
+		Broker:    broker,
+		Exchange:  field(profile.ExchangeColumn),
+		UserNotes: field(profile.NotesColumn),
+	}
+	row := Row{Line: line}
+	fail := func(format string, args ...any) ([]Row, bool) {
+		row.Transaction = transaction
+		row.Error = fmt.Sprintf(format, args...)
+		return []Row{row}, false
+	}
+
+	date, err := parseDate(field(profile.DateColumn), profile.DateFormats)
+	if err != nil {
+		return fail("%s", err.Error())
+	}
+	transaction.TransactionDate = date
+	if !ok {
+		return fail("unsupported action %q", action)
+	}
+
+	var numbers [4]float64
+	for i, column := range []string{profile.QuantityColumn, profile.PriceColumn, profile.AmountColumn, profile.FeesColumn} {
+		if numbers[i], err = parseNumber(field(column)); err != nil {
+			return fail("invalid %s %q", column, field(column))
+		}
+	}
+	quantity, price, amount, fees := math.Abs(numbers[0]), math.Abs(numbers[1]), numbers[2], math.Abs(numbers[3])
+
+	switch tradeType {
+	case types.TradeTypeBuy, types.TradeTypeSell, types.TradeTypeDividendReinvestment:
+		// Trades are stored gross; the commission becomes its own Fee transaction
+		transaction.Quantity = quantity
+		transaction.Price = price
+		transaction.Amount = roundCents(quantity * price)
+	case types.TradeTypeSplit:
+		if transaction.SplitRatio, err = parseNumber(field(profile.SplitRatioColumn)); err != nil {
+			return fail("invalid %s %q", profile.SplitRatioColumn, field(profile.SplitRatioColumn))
+		}
+	case types.TradeTypeDeposit, types.TradeTypeWithdrawal:
+		if amount < 0 {
+			transaction.TradeType = types.TradeTypeWithdrawal
+		}
+		transaction.Symbol = ""
+		transaction.Amount = roundCents(math.Abs(amount))
+	default:
+		transaction.Amount = roundCents(math.Abs(amount))
+	}
+	row.Transaction = transaction
+	rows := []Row{row}
+
+	if fees > 0 {
+		rows = append(rows, Row{Line: line, Transaction: types.TransactionData{
+			Symbol:          transaction.Symbol,
+			TradeType:       types.TradeTypeFee,
+			Amount:          roundCents(fees),
+			Currency:        transaction.Currency,
+			Broker:          transaction.Broker,
+			Exchange:        transaction.Exchange,
+			TransactionDate: transaction.TransactionDate,
+			UserNotes:       fmt.Sprintf("Commission on %s %s", transaction.TradeType, transaction.Symbol),
+		}})
+	}
+	return rows, false
+}
@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+// Row is one transaction read from an import file, with the line of a CSV file or the position in
+// an OFX statement it came from. A trade with a commission yields a second Fee row for the same line.
+type Row struct {
+	Line        int
+	Transaction types.TransactionData
+	Error       string // why the line could not be read; the transaction is then incomplete
+}
+
+// Result holds the rows read from an import file
+type Result struct {
+	Rows    []Row
+	Skipped int // lines whose action is not a transaction, such as internal journals
+}
+
+// parseNumber reads a broker formatted number such as "$1,234.56", "-$12.50" or "(12.50)".
+// An empty value is zero.
+func parseNumber(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = value[1 : len(value)-1]
+	}
+	value = strings.NewReplacer("$", "", ",", "", " ", "").Replace(value)
+	if value == "" || value == "-" || value == "--" {
+		return 0, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		number = -number
+	}
+	return number, nil
+}
+
+// parseDate reads a date in any of the given layouts into YYYY-MM-DD. Text after the first space,
+// such as Schwab's "as of" dates, is ignored.
+func parseDate(value string, layouts []string) (string, error) {
+	value = strings.TrimSpace(value)
+	if fields := strings.Fields(value); len(fields) > 0 {
+		value = fields[0]
+	}
+	for _, layout := range layouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q", value)
+}
+
+// normalizeSymbol uppercases a symbol and drops the markers brokers append to money market funds
+func normalizeSymbol(symbol string) string {
+	return strings.TrimRight(strings.ToUpper(strings.TrimSpace(symbol)), "*")
+}
+
+// roundCents rounds an amount to cents, the precision transactions are stored with
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,346 @@
+package importer
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+// ofxElement is an OFX aggregate, or a leaf element with a value
+type ofxElement struct {
+	name     string
+	value    string
+	children []*ofxElement
+}
+
+// find returns the first descendant with a name, depth first
+func (e *ofxElement) find(name string) *ofxElement {
+	for _, child := range e.children {
+		if child.name == name {
+			return child
+		}
+		if found := child.find(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findAll returns the descendants with a name, in document order, without looking inside them
+func (e *ofxElement) findAll(name string) []*ofxElement {
+	var found []*ofxElement
+	for _, child := range e.children {
+		if child.name == name {
+			found = append(found, child)
+			continue
+		}
+		found = append(found, child.findAll(name)...)
+	}
+	return found
+}
+
+// text returns the value of the first descendant with a name, or an empty string
+func (e *ofxElement) text(name string) string {
+	if found := e.find(name); found != nil {
+		return found.value
+	}
+	return ""
+}
+
+// parseOFXElements reads the <OFX> element of a statement. Version 1 files are SGML, where leaf
+// elements have no closing tag, and version 2 files are XML; both read into the same tree.
+func parseOFXElements(data string) (*ofxElement, error) {
+	start := strings.Index(strings.ToUpper(data), "<OFX>")
+	if start < 0 {
+		return nil, fmt.Errorf("not an OFX file: no <OFX> element")
+	}
+
+	root := &ofxElement{}
+	stack := []*ofxElement{root}
+	rest := data[start:]
+	for {
+		open := strings.IndexByte(rest, '<')
+		if open < 0 {
+			break
+		}
+		rest = rest[open+1:]
+		end := strings.IndexByte(rest, '>')
+		if end < 0 {
+			return nil, fmt.Errorf("malformed OFX: unterminated tag")
+		}
+		tag := strings.ToUpper(strings.TrimSpace(rest[:end]))
+		rest = rest[end+1:]
+		next := strings.IndexByte(rest, '<')
+		if next < 0 {
+			next = len(rest)
+		}
+		value := strings.TrimSpace(rest[:next])
+		rest = rest[next:]
+
+		switch {
+		case tag == "" || strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!"):
+			continue
+		case strings.HasPrefix(tag, "/"):
+			// Closing an aggregate also closes any SGML leaves left open inside it; the closing tag
+			// of an XML leaf matches nothing on the stack
+			name := tag[1:]
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+			continue
+		}
+
+		element := &ofxElement{name: strings.TrimSuffix(tag, "/"), value: html.UnescapeString(value)}
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, element)
+		if value == "" && !strings.HasSuffix(tag, "/") {
+			stack = append(stack, element)
+		}
+	}
+
+	ofx := root.find("OFX")
+	if ofx == nil {
+		return nil, fmt.Errorf("not an OFX file: no <OFX> element")
+	}
+	return ofx, nil
+}
+
+// ofxDate reads the day of an OFX date such as 20240105120000.000[-5:EST]
+func ofxDate(value string) (string, error) {
+	if len(value) >= 8 {
+		if date, err := time.Parse("20060102", value[:8]); err == nil {
+			return date.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q", value)
+}
+
+// ofxNumber reads an OFX amount, which some institutions write with a decimal comma. An empty
+// value is zero.
+func ofxNumber(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// ParseOFX reads the investment transactions of an OFX or QFX statement. Buys, sells, income,
+// reinvestments, splits and cash transfers become transactions; other records are skipped. Each
+// security is identified by the ticker the statement's security list gives for its CUSIP or other
+// unique ID. A FITID is only unique within one account at one institution, so transactions carry
+// BROKERID/ACCTID/FITID as external ID, and one repeated within the file is skipped. A file may
+// hold the statements of several accounts. Rows are numbered by their position in the file.
+func ParseOFX(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OFX: %w", err)
+	}
+	ofx, err := parseOFXElements(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := make(map[string]string)
+	if secList := ofx.find("SECLIST"); secList != nil {
+		for _, security := range secList.children {
+			if uniqueID := security.text("UNIQUEID"); uniqueID != "" {
+				tickers[uniqueID] = normalizeSymbol(security.text("TICKER"))
+			}
+		}
+	}
+
+	statements := ofx.findAll("INVSTMTRS")
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("no investment statement (INVSTMTRS) in the OFX file")
+	}
+	result := &Result{Rows: []Row{}}
+	seen := make(map[string]bool)
+	position := 0
+	for _, statement := range statements {
+		account := statement.find("INVACCTFROM")
+		if account == nil {
+			account = &ofxElement{}
+		}
+		brokerID := account.text("BROKERID")
+		broker := ofx.text("ORG")
+		if broker == "" {
+			broker = brokerID
+		}
+		statementCurrency := strings.ToUpper(statement.text("CURDEF"))
+		if statementCurrency == "" {
+			statementCurrency = "USD"
+		}
+
+		transactionList := statement.find("INVTRANLIST")
+		if transactionList == nil {
+			continue
+		}
+		for _, record := range transactionList.children {
+			if record.name == "DTSTART" || record.name == "DTEND" {
+				continue
+			}
+			position++
+
+			var externalID string
+			if fitID := record.text("FITID"); fitID != "" {
+				externalID = strings.Join([]string{brokerID, account.text("ACCTID"), fitID}, "/")
+				if seen[externalID] {
+					result.Skipped++
+					continue
+				}
+				seen[externalID] = true
+			}
+
+			currency := statementCurrency
+			if symbol := strings.ToUpper(record.text("CURSYM")); symbol != "" {
+				currency = symbol
+			}
+			row := Row{Line: position, Transaction: types.TransactionData{
+				Currency:   currency,
+				Broker:     broker,
+				ExternalID: externalID,
+				UserNotes:  record.text("MEMO"),
+			}}
+
+			rows, skipped := parseOFXRecord(record, row, tickers)
+			if skipped {
+				result.Skipped++
+				continue
+			}
+			result.Rows = append(result.Rows, rows...)
+		}
+	}
+
+	return result, nil
+}
+
+// parseOFXRecord turns one investment transaction into its transaction, plus a Fee transaction for
+// any commission and fees, or reports that the record is not a supported transaction
+func parseOFXRecord(record *ofxElement, row Row, tickers map[string]string) ([]Row, bool) {
+	transaction := &row.Transaction
+	fail := func(format string, args ...any) ([]Row, bool) {
+		row.Error = fmt.Sprintf(format, args...)
+		return []Row{row}, false
+	}
+
+	switch record.name {
+	case "BUYSTOCK", "BUYMF", "BUYOTHER", "BUYDEBT", "BUYOPT":
+		transaction.TradeType = types.TradeTypeBuy
+	case "SELLSTOCK", "SELLMF", "SELLOTHER", "SELLDEBT", "SELLOPT":
+		transaction.TradeType = types.TradeTypeSell
+	case "INCOME":
+		transaction.TradeType = types.TradeTypeDividend
+		if strings.EqualFold(record.text("INCOMETYPE"), "INTEREST") {
+			transaction.TradeType = types.TradeTypeInterest
+		}
+	case "REINVEST":
+		transaction.TradeType = types.TradeTypeDividendReinvestment
+	case "SPLIT":
+		transaction.TradeType = types.TradeTypeSplit
+	case "INVBANKTRAN":
+		return parseOFXBankRecord(record, row)
+	default:
+		return nil, true
+	}
+
+	date, err := ofxDate(record.text("DTTRADE"))
+	if err != nil {
+		return fail("%s", err.Error())
+	}
+	transaction.TransactionDate = date
+
+	uniqueID := record.text("UNIQUEID")
+	transaction.Symbol = tickers[uniqueID]
+	if transaction.Symbol == "" {
+		return fail("no ticker for security %q in the statement's security list", uniqueID)
+	}
+
+	numbers := make(map[string]float64)
+	for _, name := range []string{"UNITS", "UNITPRICE", "TOTAL", "COMMISSION", "FEES", "NUMERATOR", "DENOMINATOR"} {
+		if numbers[name], err = ofxNumber(record.text(name)); err != nil {
+			return fail("invalid %s %q", name, record.text(name))
+		}
+	}
+
+	switch transaction.TradeType {
+	case types.TradeTypeBuy, types.TradeTypeSell, types.TradeTypeDividendReinvestment:
+		// Trades are stored gross; the commission becomes its own Fee transaction
+		transaction.Quantity = math.Abs(numbers["UNITS"])
+		transaction.Price = math.Abs(numbers["UNITPRICE"])
+		transaction.Amount = roundCents(transaction.Quantity * transaction.Price)
+	case types.TradeTypeSplit:
+		if numbers["DENOMINATOR"] == 0 {
+			return fail("split without a ratio")
+		}
+		transaction.SplitRatio = numbers["NUMERATOR"] / numbers["DENOMINATOR"]
+	default:
+		transaction.Amount = roundCents(math.Abs(numbers["TOTAL"]))
+	}
+	rows := []Row{row}
+
+	if fees := math.Abs(numbers["COMMISSION"]) + math.Abs(numbers["FEES"]); fees > 0 && transaction.TradeType != types.TradeTypeSplit {
+		fee := Row{Line: row.Line, Transaction: types.TransactionData{
+			Symbol:          transaction.Symbol,
+			TradeType:       types.TradeTypeFee,
+			Amount:          roundCents(fees),
+			Currency:        transaction.Currency,
+			Broker:          transaction.Broker,
+			TransactionDate: transaction.TransactionDate,
+			UserNotes:       fmt.Sprintf("Commission on %s %s", transaction.TradeType, transaction.Symbol),
+		}}
+		if transaction.ExternalID != "" {
+			fee.Transaction.ExternalID = transaction.ExternalID + ":fee"
+		}
+		rows = append(rows, fee)
+	}
+	return rows, false
+}
+
+// parseOFXBankRecord turns a cash transaction of the investment account into a deposit or
+// withdrawal by its sign, or into interest or a fee by its type
+func parseOFXBankRecord(record *ofxElement, row Row) ([]Row, bool) {
+	transaction := &row.Transaction
+	if transaction.UserNotes == "" {
+		transaction.UserNotes = record.text("NAME")
+	}
+
+	date, err := ofxDate(record.text("DTPOSTED"))
+	if err != nil {
+		row.Error = err.Error()
+		return []Row{row}, false
+	}
+	transaction.TransactionDate = date
+
+	amount, err := ofxNumber(record.text("TRNAMT"))
+	if err != nil {
+		row.Error = fmt.Sprintf("invalid TRNAMT %q", record.text("TRNAMT"))
+		return []Row{row}, false
+	}
+	transaction.Amount = roundCents(math.Abs(amount))
+
+	switch strings.ToUpper(record.text("TRNTYPE")) {
+	case "INT", "DIV":
+		transaction.TradeType = types.TradeTypeInterest
+	case "FEE", "SRVCHG":
+		transaction.TradeType = types.TradeTypeFee
+	default:
+		transaction.TradeType = types.TradeTypeDeposit
+		if amount < 0 {
+			transaction.TradeType = types.TradeTypeWithdrawal
+		}
+	}
+	return []Row{row}, false
+}
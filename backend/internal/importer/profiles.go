@@ -0,0 +1,235 @@
+package importer
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+// ActionRule maps the actions starting with a prefix, compared case-insensitively, to a trade type
+type ActionRule struct {
+	Prefix    string
+	TradeType types.TradeType
+}
+
+// Profile maps the columns of a broker's CSV export onto transactions. Column names are matched
+// case-insensitively against the header row; an empty name means the export has no such column.
+type Profile struct {
+	Name     string
+	Broker   string // recorded on the imported transactions unless the file has a broker column
+	Currency string // used when the file has no currency column
+
+	DateColumn     string
+	ActionColumn   string
+	SymbolColumn   string
+	QuantityColumn string
+	PriceColumn    string
+	AmountColumn   string
+	FeesColumn     string // commissions and fees, imported as a separate Fee transaction
+	CurrencyColumn string
+	BrokerColumn   string
+	ExchangeColumn string
+	NotesColumn    string
+	// SplitRatioColumn holds the new shares per old share of a Split; brokers report splits as
+	// share movements without a ratio, so only the generic profile maps them
+	SplitRatioColumn string
+
+	DateFormats []string
+	// Actions are tried in order. A deposit with a negative amount is imported as a withdrawal, so
+	// one rule can map every transfer.
+	Actions []ActionRule
+	// IgnoredActions are prefixes of actions that are not transactions and are skipped
+	IgnoredActions []string
+}
+
+// tradeType returns the trade type of an action, or whether the action is ignored
+func (p Profile) tradeType(action string) (tradeType types.TradeType, ignored, ok bool) {
+	action = strings.ToLower(strings.TrimSpace(action))
+	for _, rule := range p.Actions {
+		if strings.HasPrefix(action, strings.ToLower(rule.Prefix)) {
+			return rule.TradeType, false, true
+		}
+	}
+	for _, prefix := range p.IgnoredActions {
+		if strings.HasPrefix(action, strings.ToLower(prefix)) {
+			return "", true, false
+		}
+	}
+	return "", false, false
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = make(map[string]Profile)
+)
+
+// RegisterProfile adds a profile, replacing any profile of the same name
+func RegisterProfile(profile Profile) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles[strings.ToLower(profile.Name)] = profile
+}
+
+// LookupProfile returns the profile registered under a name
+func LookupProfile(name string) (Profile, bool) {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	profile, ok := profiles[strings.ToLower(strings.TrimSpace(name))]
+	return profile, ok
+}
+
+// ProfileNames returns the names of the registered profiles, sorted
+func ProfileNames() []string {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	// Generic matches the fields of the transaction API, with trade types spelled as in the API
+	RegisterProfile(Profile{
+		Name:           "generic",
+		Currency:       "USD",
+		DateColumn:     "transaction_date",
+		ActionColumn:   "trade_type",
+		SymbolColumn:   "symbol",
+		QuantityColumn: "quantity",
+		PriceColumn:    "price",
+		AmountColumn:   "amount",
+		CurrencyColumn: "currency",
+		BrokerColumn:   "broker",
+		ExchangeColumn: "exchange",
+		NotesColumn:    "user_notes",
+		DateFormats:    []string{"2006-01-02", "01/02/2006", "1/2/2006"},
+
+		SplitRatioColumn: "split_ratio",
+		Actions: []ActionRule{
+			{Prefix: "DividendReinvestment", TradeType: types.TradeTypeDividendReinvestment},
+			{Prefix: "Dividend", TradeType: types.TradeTypeDividend},
+			{Prefix: "Buy", TradeType: types.TradeTypeBuy},
+			{Prefix: "Sell", TradeType: types.TradeTypeSell},
+			{Prefix: "Split", TradeType: types.TradeTypeSplit},
+			{Prefix: "Deposit", TradeType: types.TradeTypeDeposit},
+			{Prefix: "Withdrawal", TradeType: types.TradeTypeWithdrawal},
+			{Prefix: "Fee", TradeType: types.TradeTypeFee},
+			{Prefix: "Interest", TradeType: types.TradeTypeInterest},
+			{Prefix: "Tax", TradeType: types.TradeTypeTax},
+		},
+	})
+
+	RegisterProfile(Profile{
+		Name:           "schwab",
+		Broker:         "Schwab",
+		Currency:       "USD",
+		DateColumn:     "Date",
+		ActionColumn:   "Action",
+		SymbolColumn:   "Symbol",
+		QuantityColumn: "Quantity",
+		PriceColumn:    "Price",
+		AmountColumn:   "Amount",
+		FeesColumn:     "Fees & Comm",
+		NotesColumn:    "Description",
+		DateFormats:    []string{"01/02/2006"},
+		Actions: []ActionRule{
+			{Prefix: "Buy", TradeType: types.TradeTypeBuy},
+			{Prefix: "Reinvest Shares", TradeType: types.TradeTypeBuy},
+			{Prefix: "Sell", TradeType: types.TradeTypeSell},
+			{Prefix: "Reinvest Dividend", TradeType: types.TradeTypeDividend},
+			{Prefix: "Cash Dividend", TradeType: types.TradeTypeDividend},
+			{Prefix: "Qualified Dividend", TradeType: types.TradeTypeDividend},
+			{Prefix: "Non-Qualified Div", TradeType: types.TradeTypeDividend},
+			{Prefix: "Special Dividend", TradeType: types.TradeTypeDividend},
+			{Prefix: "Pr Yr", TradeType: types.TradeTypeDividend},
+			{Prefix: "Bank Interest", TradeType: types.TradeTypeInterest},
+			{Prefix: "Credit Interest", TradeType: types.TradeTypeInterest},
+			{Prefix: "MoneyLink", TradeType: types.TradeTypeDeposit},
+			{Prefix: "Wire Funds", TradeType: types.TradeTypeDeposit},
+			{Prefix: "Funds Received", TradeType: types.TradeTypeDeposit},
+			{Prefix: "NRA Tax", TradeType: types.TradeTypeTax},
+			{Prefix: "Foreign Tax", TradeType: types.TradeTypeTax},
+			{Prefix: "Service Fee", TradeType: types.TradeTypeFee},
+			{Prefix: "ADR Mgmt Fee", TradeType: types.TradeTypeFee},
+		},
+		IgnoredActions: []string{"Journal", "Internal Transfer"},
+	})
+
+	RegisterProfile(Profile{
+		Name:           "fidelity",
+		Broker:         "Fidelity",
+		Currency:       "USD",
+		DateColumn:     "Run Date",
+		ActionColumn:   "Action",
+		SymbolColumn:   "Symbol",
+		QuantityColumn: "Quantity",
+		PriceColumn:    "Price ($)",
+		AmountColumn:   "Amount ($)",
+		FeesColumn:     "Commission ($)",
+		NotesColumn:    "Description",
+		DateFormats:    []string{"01/02/2006"},
+		Actions: []ActionRule{
+			{Prefix: "YOU BOUGHT", TradeType: types.TradeTypeBuy},
+			{Prefix: "REINVESTMENT", TradeType: types.TradeTypeBuy},
+			{Prefix: "YOU SOLD", TradeType: types.TradeTypeSell},
+			{Prefix: "DIVIDEND RECEIVED", TradeType: types.TradeTypeDividend},
+			{Prefix: "INTEREST EARNED", TradeType: types.TradeTypeInterest},
+			{Prefix: "Electronic Funds Transfer", TradeType: types.TradeTypeDeposit},
+			{Prefix: "DIRECT DEPOSIT", TradeType: types.TradeTypeDeposit},
+			{Prefix: "FOREIGN TAX PAID", TradeType: types.TradeTypeTax},
+			{Prefix: "FEE CHARGED", TradeType: types.TradeTypeFee},
+		},
+		IgnoredActions: []string{"JOURNALED", "TRANSFERRED FROM", "TRANSFERRED TO"},
+	})
+
+	// Interactive Brokers trades from a Flex Query report
+	RegisterProfile(Profile{
+		Name:           "ibkr",
+		Broker:         "Interactive Brokers",
+		Currency:       "USD",
+		DateColumn:     "TradeDate",
+		ActionColumn:   "Buy/Sell",
+		SymbolColumn:   "Symbol",
+		QuantityColumn: "Quantity",
+		PriceColumn:    "TradePrice",
+		AmountColumn:   "Proceeds",
+		FeesColumn:     "IBCommission",
+		CurrencyColumn: "CurrencyPrimary",
+		ExchangeColumn: "Exchange",
+		DateFormats:    []string{"20060102", "2006-01-02"},
+		Actions: []ActionRule{
+			{Prefix: "BUY", TradeType: types.TradeTypeBuy},
+			{Prefix: "SELL", TradeType: types.TradeTypeSell},
+		},
+	})
+
+	RegisterProfile(Profile{
+		Name:           "robinhood",
+		Broker:         "Robinhood",
+		Currency:       "USD",
+		DateColumn:     "Activity Date",
+		ActionColumn:   "Trans Code",
+		SymbolColumn:   "Instrument",
+		QuantityColumn: "Quantity",
+		PriceColumn:    "Price",
+		AmountColumn:   "Amount",
+		NotesColumn:    "Description",
+		DateFormats:    []string{"1/2/2006", "01/02/2006"},
+		Actions: []ActionRule{
+			{Prefix: "Buy", TradeType: types.TradeTypeBuy},
+			{Prefix: "Sell", TradeType: types.TradeTypeSell},
+			{Prefix: "CDIV", TradeType: types.TradeTypeDividend},
+			{Prefix: "ACH", TradeType: types.TradeTypeDeposit},
+			{Prefix: "INT", TradeType: types.TradeTypeInterest},
+			{Prefix: "SLIP", TradeType: types.TradeTypeInterest},
+			{Prefix: "GOLD", TradeType: types.TradeTypeFee},
+			{Prefix: "DTAX", TradeType: types.TradeTypeTax},
+		},
+		IgnoredActions: []string{"GDBP", "JNLC"},
+	})
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccountType classifies a brokerage account by its tax treatment
+type AccountType string
+
+const (
+	AccountTypeTaxable AccountType = "taxable"
+	AccountTypeIRA     AccountType = "ira"
+	AccountTypeRothIRA AccountType = "roth_ira"
+	AccountType401k    AccountType = "401k"
+	AccountTypeOther   AccountType = "other"
+)
+
+// IsValid reports whether the account type is supported
+func (t AccountType) IsValid() bool {
+	switch t {
+	case AccountTypeTaxable, AccountTypeIRA, AccountTypeRothIRA, AccountType401k, AccountTypeOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// Account represents a brokerage account owned by a user
+type Account struct {
+	AccountID    uuid.UUID   `gorm:"type:varchar(36);primaryKey" json:"account_id"`
+	UserID       uuid.UUID   `gorm:"type:varchar(36);not null;index" json:"-"`
+	Name         string      `gorm:"size:100;not null" json:"name"`
+	Broker       string      `gorm:"size:100;not null" json:"broker"`
+	AccountType  AccountType `gorm:"size:20;not null;default:'taxable'" json:"account_type"`
+	BaseCurrency string      `gorm:"size:3;not null;default:'USD'" json:"base_currency"`
+	BaseModel
+}
+
+// TableName specifies the table name for Account model
+func (Account) TableName() string {
+	return "accounts"
+}
+
+// BeforeCreate hook for Account model
+func (a *Account) BeforeCreate(tx *gorm.DB) error {
+	if a.AccountID == uuid.Nil {
+		a.AccountID = uuid.New()
+	}
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	if a.UpdatedAt.IsZero() {
+		a.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// BeforeUpdate hook for Account model
+func (a *Account) BeforeUpdate(tx *gorm.DB) error {
+	a.UpdatedAt = time.Now()
+	return nil
+}
@@ -1,19 +1,31 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/types"
+)
 
 // SingleHolding represents basic information about a stock holding
 type SingleHolding struct {
-	Symbol               string  `json:"symbol"`
-	TotalQuantity        float64 `json:"total_quantity"`
-	TotalCost            float64 `json:"total_cost"`
-	UnitCost             float64 `json:"unit_cost"`
-	CurrentPrice         float64 `json:"current_price"`
-	MarketValue          float64 `json:"market_value"`
-	TotalReturnRate      float64 `json:"total_return_rate"`
-	AnnualizedReturnRate float64 `json:"annualized_return_rate"`
-	RealizedGainLoss     float64 `json:"realized_gain_loss"`
-	UnrealizedGainLoss   float64 `json:"unrealized_gain_loss"`
+	Symbol               string          `json:"symbol"`
+	Currency             string          `json:"currency"`        // currency the monetary values are reported in
+	NativeCurrency       string          `json:"native_currency"` // currency the symbol trades in
+	FXRate               float64         `json:"fx_rate"`         // rate from NativeCurrency to Currency
+	TotalQuantity        float64         `json:"total_quantity"`
+	TotalCost            float64         `json:"total_cost"`
+	UnitCost             float64         `json:"unit_cost"`
+	CurrentPrice         float64         `json:"current_price"`
+	MarketValue          float64         `json:"market_value"`
+	TotalReturnRate      float64         `json:"total_return_rate"`
+	AnnualizedReturnRate float64         `json:"annualized_return_rate"`
+	RealizedGainLoss     float64         `json:"realized_gain_loss"`
+	UnrealizedGainLoss   float64         `json:"unrealized_gain_loss"`
+	DividendIncome       float64         `json:"dividend_income"`
+	TTMDividendIncome    float64         `json:"ttm_dividend_income"`
+	YieldOnCost          float64         `json:"yield_on_cost"`
+	CostBasisMethod      CostBasisMethod `json:"cost_basis_method"`
 }
 
 // SingleHoldingResponse represents the response structure for stock basic info
@@ -24,22 +36,135 @@ type SingleHoldingResponse struct {
 
 // AllHoldingsResponse represents the response structure for all holdings
 type AllHoldingsResponse struct {
-	Holdings  []SingleHolding `json:"holdings"`
-	Timestamp time.Time       `json:"timestamp"`
+	Holdings      []SingleHolding `json:"holdings"`
+	PriceFailures []PriceFailure  `json:"price_failures"`
+	Timestamp     time.Time       `json:"timestamp"`
+}
+
+// PriceFailure represents a current holding left out of the results because it could not be valued
+type PriceFailure struct {
+	Symbol   string  `json:"symbol"`
+	Quantity float64 `json:"quantity"`
+	Reason   string  `json:"reason"`
 }
 
 // PortfolioSummary represents the overall portfolio summary
 type PortfolioSummary struct {
-	Timestamp             time.Time `json:"timestamp"`
-	Currency              string    `json:"currency"`
-	MarketValue           float64   `json:"market_value"`
-	TotalCost             float64   `json:"total_cost"`
-	TotalReturn           float64   `json:"total_return"`
-	TotalReturnPercentage float64   `json:"total_return_percentage"`
-	HoldingsCount         int       `json:"holdings_count"`
-	HasTransactions       bool      `json:"has_transactions"`
-	AnnualizedReturnRate  float64   `json:"annualized_return_rate"`
-	LastUpdated           time.Time `json:"last_updated"`
+	Timestamp             time.Time       `json:"timestamp"`
+	Currency              string          `json:"currency"`
+	MarketValue           float64         `json:"market_value"`
+	TotalCost             float64         `json:"total_cost"`
+	TotalReturn           float64         `json:"total_return"`
+	TotalReturnPercentage float64         `json:"total_return_percentage"`
+	HoldingsCount         int             `json:"holdings_count"`
+	HasTransactions       bool            `json:"has_transactions"`
+	AnnualizedReturnRate  float64         `json:"annualized_return_rate"`
+	TWR                   float64         `json:"time_weighted_return"`            // cumulative since the first transaction
+	AnnualizedTWR         float64         `json:"annualized_time_weighted_return"` // equals TWR for histories under a year
+	DividendIncome        float64         `json:"dividend_income"`
+	TTMDividendIncome     float64         `json:"ttm_dividend_income"`
+	CashBalance           float64         `json:"cash_balance"` // total cash in the base currency, included in MarketValue
+	CashBalances          []CashBalance   `json:"cash_balances"`
+	PriceFailures         []PriceFailure  `json:"price_failures"` // holdings missing from the totals
+	CostBasisMethod       CostBasisMethod `json:"cost_basis_method"`
+	LastUpdated           time.Time       `json:"last_updated"`
+}
+
+// CashBalance represents the cash held at a broker in one currency
+type CashBalance struct {
+	Broker      string  `json:"broker"`
+	Currency    string  `json:"currency"`
+	Balance     float64 `json:"balance"`
+	BaseBalance float64 `json:"base_balance"` // Balance converted into the base currency
+}
+
+// HoldingTerm classifies a disposal by how long the shares were held
+type HoldingTerm string
+
+const (
+	HoldingTermShort HoldingTerm = "short_term"
+	HoldingTermLong  HoldingTerm = "long_term"
+)
+
+// RealizedGainTotals represents aggregated realized gain figures split by holding term
+type RealizedGainTotals struct {
+	Proceeds          float64 `json:"proceeds"`
+	CostBasis         float64 `json:"cost_basis"`
+	ShortTermGainLoss float64 `json:"short_term_gain_loss"`
+	LongTermGainLoss  float64 `json:"long_term_gain_loss"`
+	TotalGainLoss     float64 `json:"total_gain_loss"`
+	DisallowedLoss    float64 `json:"wash_sale_disallowed_loss"`
+}
+
+// RealizedGainDisposal represents the shares of one lot closed by a Sell transaction
+type RealizedGainDisposal struct {
+	Symbol            string      `json:"symbol"`
+	SellTransactionID string      `json:"sell_transaction_id"`
+	LotTransactionID  string      `json:"lot_transaction_id"`
+	AcquiredAt        time.Time   `json:"acquired_at"`
+	SoldAt            time.Time   `json:"sold_at"`
+	Quantity          float64     `json:"quantity"`
+	Proceeds          float64     `json:"proceeds"`
+	CostBasis         float64     `json:"cost_basis"`
+	GainLoss          float64     `json:"gain_loss"`
+	Term              HoldingTerm `json:"term"`
+
+	WashSale                  bool     `json:"wash_sale"`
+	DisallowedLoss            float64  `json:"wash_sale_disallowed_loss"`
+	ReplacementTransactionIDs []string `json:"replacement_transaction_ids,omitempty"`
+}
+
+// SymbolRealizedGains represents the realized gains of a single symbol
+type SymbolRealizedGains struct {
+	Symbol string `json:"symbol"`
+	RealizedGainTotals
+}
+
+// RealizedGainsReport represents the realized gains of a tax year
+type RealizedGainsReport struct {
+	Year            int                    `json:"year"`
+	CostBasisMethod CostBasisMethod        `json:"cost_basis_method"`
+	Totals          RealizedGainTotals     `json:"totals"`
+	Symbols         []SymbolRealizedGains  `json:"symbols"`
+	Disposals       []RealizedGainDisposal `json:"disposals"`
+	Timestamp       time.Time              `json:"timestamp"`
+}
+
+// DividendGrouping represents the period dividend income is aggregated by
+type DividendGrouping string
+
+const (
+	DividendGroupingMonth DividendGrouping = "month"
+	DividendGroupingYear  DividendGrouping = "year"
+)
+
+// IsValid reports whether the dividend grouping is supported
+func (g DividendGrouping) IsValid() bool {
+	return g == DividendGroupingMonth || g == DividendGroupingYear
+}
+
+// SymbolDividendIncome represents the dividend income of a single symbol
+type SymbolDividendIncome struct {
+	Symbol string  `json:"symbol"`
+	Amount float64 `json:"amount"`
+}
+
+// DividendPeriodIncome represents the dividend income of one month or year
+type DividendPeriodIncome struct {
+	Period  string                 `json:"period"` // YYYY-MM or YYYY
+	Total   float64                `json:"total"`
+	Symbols []SymbolDividendIncome `json:"symbols"`
+}
+
+// DividendIncomeReport represents dividend income aggregated by period and symbol
+type DividendIncomeReport struct {
+	GroupBy           DividendGrouping       `json:"group_by"`
+	Currency          string                 `json:"currency"`
+	TotalIncome       float64                `json:"total_income"`
+	TTMDividendIncome float64                `json:"ttm_dividend_income"`
+	Periods           []DividendPeriodIncome `json:"periods"`
+	Symbols           []SymbolDividendIncome `json:"symbols"`
+	Timestamp         time.Time              `json:"timestamp"`
 }
 
 // PortfolioAnalysisType represents the type of analysis requested
@@ -50,6 +175,30 @@ const (
 	AnalysisTypeDetailed PortfolioAnalysisType = "detailed"
 )
 
+// CostBasisMethod represents the lot relief method used to compute cost basis and realized gains
+type CostBasisMethod string
+
+const (
+	CostBasisAverage     CostBasisMethod = "average"
+	CostBasisFIFO        CostBasisMethod = "fifo"
+	CostBasisLIFO        CostBasisMethod = "lifo"
+	CostBasisHIFO        CostBasisMethod = "hifo"
+	CostBasisSpecificLot CostBasisMethod = "specific_lot"
+)
+
+// DefaultCostBasisMethod is used when neither the request nor the user specifies a method
+const DefaultCostBasisMethod = CostBasisAverage
+
+// IsValid reports whether the cost basis method is supported
+func (m CostBasisMethod) IsValid() bool {
+	switch m {
+	case CostBasisAverage, CostBasisFIFO, CostBasisLIFO, CostBasisHIFO, CostBasisSpecificLot:
+		return true
+	default:
+		return false
+	}
+}
+
 // TimeFrame represents supported timeframes for historical data
 type TimeFrame string
 
@@ -63,8 +212,21 @@ const (
 	TimeFrame1Y  TimeFrame = "1Y"
 	TimeFrame5Y  TimeFrame = "5Y"
 	TimeFrameALL TimeFrame = "ALL"
+
+	// TimeFrameCustom reports a chart over an explicit from/to range; it is not accepted as a timeframe
+	TimeFrameCustom TimeFrame = "CUSTOM"
 )
 
+// IsValid reports whether the timeframe is supported
+func (t TimeFrame) IsValid() bool {
+	switch t {
+	case TimeFrame1D, TimeFrame1W, TimeFrame1M, TimeFrame3M, TimeFrame6M, TimeFrameYTD, TimeFrame1Y, TimeFrame5Y, TimeFrameALL:
+		return true
+	default:
+		return false
+	}
+}
+
 // Granularity represents data point frequency
 type Granularity string
 
@@ -75,12 +237,24 @@ const (
 	GranularityMonthly Granularity = "monthly"
 )
 
+// IsValid reports whether the granularity is supported
+func (g Granularity) IsValid() bool {
+	switch g {
+	case GranularityHourly, GranularityDaily, GranularityWeekly, GranularityMonthly:
+		return true
+	default:
+		return false
+	}
+}
+
 // TotalValueDataPoint represents a single data point in the historical chart
 type TotalValueDataPoint struct {
 	Timestamp        time.Time `json:"timestamp"`
 	TotalValue       float64   `json:"market_value"`
 	DayChange        float64   `json:"day_change"`
 	DayChangePercent float64   `json:"day_change_percent"`
+	PeriodReturn     float64   `json:"period_return"`        // time-weighted return since the previous data point
+	CumulativeReturn float64   `json:"time_weighted_return"` // time-weighted return since the first data point
 }
 
 // TotalValueTrendSummary represents summary statistics for the time period
@@ -88,6 +262,7 @@ type TotalValueTrendSummary struct {
 	Change        float64 `json:"change"`
 	ChangePercent float64 `json:"change_percent"`
 	Volatility    float64 `json:"volatility"`
+	TWR           float64 `json:"time_weighted_return"`
 	MaxValue      float64 `json:"max_value"`
 	MinValue      float64 `json:"min_value"`
 }
@@ -96,10 +271,190 @@ type TotalValueTrendSummary struct {
 type HistoricalTotalValueResponse struct {
 	TimeFrame   TimeFrame   `json:"timeframe"`
 	Granularity Granularity `json:"granularity"`
+	Currency    string      `json:"currency"`
 	Period      struct {
 		StartDate time.Time `json:"start_date"`
 		EndDate   time.Time `json:"end_date"`
 	} `json:"period"`
 	DataPoints []TotalValueDataPoint  `json:"data_points"`
 	Summary    TotalValueTrendSummary `json:"summary"`
+	Benchmark  *BenchmarkComparison   `json:"benchmark,omitempty"`
+}
+
+// HoldingChartDataPoint represents a holding's position and value at one point of its chart
+type HoldingChartDataPoint struct {
+	Timestamp          time.Time `json:"timestamp"`
+	Quantity           float64   `json:"quantity"`
+	Price              float64   `json:"price"`
+	MarketValue        float64   `json:"market_value"`
+	CostBasis          float64   `json:"cost_basis"`
+	UnrealizedGainLoss float64   `json:"unrealized_gain_loss"`
+}
+
+// HoldingChartEvent marks a transaction of the holding on its chart
+type HoldingChartEvent struct {
+	TransactionID  uuid.UUID       `json:"transaction_id"`
+	Timestamp      time.Time       `json:"timestamp"`
+	TradeType      types.TradeType `json:"trade_type"`
+	Quantity       float64         `json:"quantity"`
+	Price          float64         `json:"price"`
+	Amount         float64         `json:"amount"`
+	DataPointIndex int             `json:"data_point_index"` // first data point that reflects the transaction
+}
+
+// HoldingChartResponse represents a single holding over time, with its transactions as events
+type HoldingChartResponse struct {
+	Symbol          string          `json:"symbol"`
+	TimeFrame       TimeFrame       `json:"timeframe"`
+	Granularity     Granularity     `json:"granularity"`
+	Currency        string          `json:"currency"`        // currency the monetary values are reported in
+	NativeCurrency  string          `json:"native_currency"` // currency the symbol trades in
+	CostBasisMethod CostBasisMethod `json:"cost_basis_method"`
+	Period          struct {
+		StartDate time.Time `json:"start_date"`
+		EndDate   time.Time `json:"end_date"`
+	} `json:"period"`
+	DataPoints []HoldingChartDataPoint `json:"data_points"`
+	Events     []HoldingChartEvent     `json:"events"`
+}
+
+// BenchmarkDataPoint represents the value the portfolio's cash flows would have had in the benchmark
+type BenchmarkDataPoint struct {
+	Timestamp        time.Time `json:"timestamp"`
+	TotalValue       float64   `json:"market_value"`
+	CumulativeReturn float64   `json:"time_weighted_return"`
+}
+
+// BenchmarkComparison compares the historical chart against a benchmark symbol
+type BenchmarkComparison struct {
+	Symbol        string               `json:"symbol"`
+	TWR           float64              `json:"time_weighted_return"`
+	Alpha         float64              `json:"alpha"` // annualized percentage
+	Beta          float64              `json:"beta"`
+	TrackingError float64              `json:"tracking_error"` // annualized percentage
+	DataPoints    []BenchmarkDataPoint `json:"data_points"`
+}
+
+// RiskMetrics represents the risk analytics of the portfolio over a timeframe, computed from
+// daily time-weighted returns. Rates, volatilities and drawdowns are annualized percentages.
+type RiskMetrics struct {
+	TimeFrame            TimeFrame     `json:"timeframe"`
+	Currency             string        `json:"currency"`
+	StartDate            time.Time     `json:"start_date"`
+	EndDate              time.Time     `json:"end_date"`
+	TradingDays          int           `json:"trading_days"`
+	Benchmark            string        `json:"benchmark"`
+	RiskFreeRate         float64       `json:"risk_free_rate"`
+	AnnualizedReturn     float64       `json:"annualized_return"`
+	AnnualizedVolatility float64       `json:"annualized_volatility"`
+	DownsideDeviation    float64       `json:"downside_deviation"`
+	SharpeRatio          float64       `json:"sharpe_ratio"`
+	SortinoRatio         float64       `json:"sortino_ratio"`
+	MaxDrawdown          MaxDrawdown   `json:"max_drawdown"`
+	Beta                 float64       `json:"beta"`
+	Holdings             []HoldingRisk `json:"holdings"`
+	Timestamp            time.Time     `json:"timestamp"`
+}
+
+// MaxDrawdown represents the largest fall in cumulative return from a peak to a later trough
+type MaxDrawdown struct {
+	Drawdown   float64    `json:"drawdown"` // percentage of the peak
+	PeakDate   *time.Time `json:"peak_date,omitempty"`
+	TroughDate *time.Time `json:"trough_date,omitempty"`
+}
+
+// HoldingRisk represents the risk of a current holding measured from its daily price returns
+type HoldingRisk struct {
+	Symbol               string  `json:"symbol"`
+	Weight               float64 `json:"weight"` // percentage of the market value of all holdings
+	Beta                 float64 `json:"beta"`
+	AnnualizedVolatility float64 `json:"annualized_volatility"`
+}
+
+// AllocationGrouping represents the dimension holdings are grouped by in an allocation breakdown
+type AllocationGrouping string
+
+const (
+	AllocationGroupingSector     AllocationGrouping = "sector"
+	AllocationGroupingAssetClass AllocationGrouping = "asset_class"
+	AllocationGroupingCountry    AllocationGrouping = "country"
+	AllocationGroupingBroker     AllocationGrouping = "broker"
+	AllocationGroupingCurrency   AllocationGrouping = "currency"
+)
+
+// AllocationUnknown is the group of holdings without a value for the grouping dimension
+const AllocationUnknown = "Unknown"
+
+// IsValid reports whether the allocation grouping is supported
+func (g AllocationGrouping) IsValid() bool {
+	switch g {
+	case AllocationGroupingSector, AllocationGroupingAssetClass, AllocationGroupingCountry, AllocationGroupingBroker, AllocationGroupingCurrency:
+		return true
+	default:
+		return false
+	}
+}
+
+// AllocationReport represents the weights of the portfolio's holdings grouped by one dimension
+type AllocationReport struct {
+	GroupBy     AllocationGrouping `json:"group_by"`
+	Currency    string             `json:"currency"`
+	MarketValue float64            `json:"market_value"`
+	Groups      []AllocationGroup  `json:"groups"`
+	Timestamp   time.Time          `json:"timestamp"`
+}
+
+// AllocationGroup represents the holdings sharing one value of the grouping dimension
+type AllocationGroup struct {
+	Key         string              `json:"key"`
+	MarketValue float64             `json:"market_value"`
+	Weight      float64             `json:"weight"` // percentage of the report's market value
+	Holdings    []AllocationHolding `json:"holdings"`
+}
+
+// AllocationHolding represents the part of a holding that falls in an allocation group
+type AllocationHolding struct {
+	Symbol      string  `json:"symbol"`
+	MarketValue float64 `json:"market_value"`
+	Weight      float64 `json:"weight"` // percentage of the report's market value
+}
+
+// RebalancePlan represents the trades that move the portfolio towards its target allocation
+type RebalancePlan struct {
+	Currency         string              `json:"currency"`
+	MarketValue      float64             `json:"market_value"` // holdings only
+	Cash             float64             `json:"cash"`         // available before trading
+	TotalValue       float64             `json:"total_value"`
+	NoSells          bool                `json:"no_sells"`
+	WholeShares      bool                `json:"whole_shares"`
+	MinTradeSize     float64             `json:"min_trade_size"`
+	BuyAmount        float64             `json:"buy_amount"`
+	SellAmount       float64             `json:"sell_amount"`
+	CashAfter        float64             `json:"cash_after"`
+	Positions        []RebalancePosition `json:"positions"`
+	Trades           []RebalanceTrade    `json:"trades"`
+	UnmatchedTargets []TargetAllocation  `json:"unmatched_targets"` // no priced holding to trade
+	Timestamp        time.Time           `json:"timestamp"`
+}
+
+// RebalancePosition represents a position before and after the suggested trades
+type RebalancePosition struct {
+	Symbol            string  `json:"symbol"`
+	AssetClass        string  `json:"asset_class,omitempty"`
+	Price             float64 `json:"price"`
+	Quantity          float64 `json:"quantity"`
+	MarketValue       float64 `json:"market_value"`
+	CurrentWeight     float64 `json:"current_weight"` // percentage of the total value
+	TargetWeight      float64 `json:"target_weight"`
+	ProjectedQuantity float64 `json:"projected_quantity"`
+	ProjectedWeight   float64 `json:"projected_weight"`
+}
+
+// RebalanceTrade represents one suggested Buy or Sell
+type RebalanceTrade struct {
+	Symbol   string          `json:"symbol"`
+	Action   types.TradeType `json:"action"`
+	Quantity float64         `json:"quantity"`
+	Price    float64         `json:"price"`
+	Amount   float64         `json:"amount"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PortfolioSnapshot is the end of day valuation of a user's whole portfolio in their base currency
+type PortfolioSnapshot struct {
+	UserID       uuid.UUID `gorm:"type:varchar(36);primaryKey" json:"-"`
+	SnapshotDate time.Time `gorm:"type:date;primaryKey" json:"snapshot_date"`
+	Currency     string    `gorm:"size:3;not null" json:"currency"`
+	MarketValue  float64   `gorm:"type:decimal(20,4);not null" json:"market_value"`
+	CostBasis    float64   `gorm:"type:decimal(20,4);not null" json:"cost_basis"`
+	Cash         float64   `gorm:"type:decimal(20,4);not null" json:"cash"`
+	NetFlows     float64   `gorm:"type:decimal(20,4);not null" json:"net_flows"` // external flows on the day
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for PortfolioSnapshot model
+func (PortfolioSnapshot) TableName() string {
+	return "portfolio_snapshots"
+}
+
+// TotalValue returns the value of the positions plus the tracked cash
+func (s PortfolioSnapshot) TotalValue() float64 {
+	return s.MarketValue + s.Cash
+}
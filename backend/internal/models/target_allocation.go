@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TargetType represents what a target allocation weight applies to
+type TargetType string
+
+const (
+	TargetTypeSymbol     TargetType = "symbol"
+	TargetTypeAssetClass TargetType = "asset_class"
+)
+
+// IsValid reports whether the target type is supported
+func (t TargetType) IsValid() bool {
+	switch t {
+	case TargetTypeSymbol, TargetTypeAssetClass:
+		return true
+	default:
+		return false
+	}
+}
+
+// TargetAllocation represents the target weight of a symbol or asset class in a user's portfolio
+type TargetAllocation struct {
+	TargetID  uuid.UUID  `gorm:"type:varchar(36);primaryKey" json:"target_id"`
+	UserID    uuid.UUID  `gorm:"type:varchar(36);not null;index" json:"-"`
+	Type      TargetType `gorm:"column:target_type;size:20;not null" json:"type"`
+	Key       string     `gorm:"column:target_key;size:50;not null" json:"key"`
+	Weight    float64    `gorm:"type:decimal(7,4);not null" json:"weight"` // percentage of the portfolio
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for TargetAllocation model
+func (TargetAllocation) TableName() string {
+	return "target_allocations"
+}
+
+// BeforeCreate hook for TargetAllocation model
+func (t *TargetAllocation) BeforeCreate(tx *gorm.DB) error {
+	if t.TargetID == uuid.Nil {
+		t.TargetID = uuid.New()
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	if t.UpdatedAt.IsZero() {
+		t.UpdatedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaxLot represents an acquisition lot in the persisted tax lot ledger
+type TaxLot struct {
+	LotID             uuid.UUID       `gorm:"type:varchar(36);primaryKey" json:"lot_id"`
+	UserID            uuid.UUID       `gorm:"type:varchar(36);not null;index" json:"-"`
+	TransactionID     uuid.UUID       `gorm:"type:varchar(36);not null;index" json:"transaction_id"`
+	Symbol            string          `gorm:"size:20;not null;index" json:"symbol"`
+	CostBasisMethod   CostBasisMethod `gorm:"size:20;not null" json:"cost_basis_method"`
+	AcquiredAt        time.Time       `gorm:"not null" json:"acquired_at"`
+	OriginalQuantity  float64         `gorm:"type:decimal(15,4);not null" json:"original_quantity"`
+	RemainingQuantity float64         `gorm:"type:decimal(15,4);not null" json:"remaining_quantity"`
+	UnitCost          float64         `gorm:"type:decimal(15,4);not null" json:"unit_cost"`
+	CostBasis         float64         `gorm:"type:decimal(15,2);not null" json:"cost_basis"`
+	ClosedAt          *time.Time      `gorm:"null" json:"closed_at,omitempty"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+
+	Disposals []TaxLotDisposal `gorm:"foreignKey:LotID;references:LotID" json:"disposals"`
+}
+
+// TableName specifies the table name for TaxLot model
+func (TaxLot) TableName() string {
+	return "tax_lots"
+}
+
+// BeforeCreate hook for TaxLot model
+func (l *TaxLot) BeforeCreate(tx *gorm.DB) error {
+	if l.LotID == uuid.Nil {
+		l.LotID = uuid.New()
+	}
+	return nil
+}
+
+// IsOpen reports whether the lot still has shares remaining
+func (l *TaxLot) IsOpen() bool {
+	return l.ClosedAt == nil
+}
+
+// TaxLotDisposal records the shares of a lot relieved by a Sell transaction
+type TaxLotDisposal struct {
+	DisposalID        uuid.UUID `gorm:"type:varchar(36);primaryKey" json:"disposal_id"`
+	LotID             uuid.UUID `gorm:"type:varchar(36);not null;index" json:"lot_id"`
+	UserID            uuid.UUID `gorm:"type:varchar(36);not null;index" json:"-"`
+	SellTransactionID uuid.UUID `gorm:"type:varchar(36);not null;index" json:"sell_transaction_id"`
+	Symbol            string    `gorm:"size:20;not null" json:"symbol"`
+	DisposedAt        time.Time `gorm:"not null" json:"disposed_at"`
+	Quantity          float64   `gorm:"type:decimal(15,4);not null" json:"quantity"`
+	CostBasis         float64   `gorm:"type:decimal(15,2);not null" json:"cost_basis"`
+	Proceeds          float64   `gorm:"type:decimal(15,2);not null" json:"proceeds"`
+	GainLoss          float64   `gorm:"type:decimal(15,2);not null" json:"gain_loss"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for TaxLotDisposal model
+func (TaxLotDisposal) TableName() string {
+	return "tax_lot_disposals"
+}
+
+// BeforeCreate hook for TaxLotDisposal model
+func (d *TaxLotDisposal) BeforeCreate(tx *gorm.DB) error {
+	if d.DisposalID == uuid.Nil {
+		d.DisposalID = uuid.New()
+	}
+	return nil
+}
+
+// TaxLotsResponse represents the open and closed lots of a symbol
+type TaxLotsResponse struct {
+	Symbol          string          `json:"symbol"`
+	CostBasisMethod CostBasisMethod `json:"cost_basis_method"`
+	OpenLots        []TaxLot        `json:"open_lots"`
+	ClosedLots      []TaxLot        `json:"closed_lots"`
+	Timestamp       time.Time       `json:"timestamp"`
+}
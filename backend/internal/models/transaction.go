@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,14 +23,61 @@ type Transaction struct {
 	Currency        string          `gorm:"size:3;not null;default:'USD'" json:"currency"`
 	Exchange        string          `gorm:"size:50" json:"exchange"`
 	Broker          string          `gorm:"size:100" json:"broker"`
+	AccountID       *uuid.UUID      `gorm:"type:varchar(36);index" json:"account_id,omitempty"`
 	TransactionDate time.Time       `gorm:"not null;index" json:"transaction_date"`
 	UserNotes       string          `gorm:"type:text" json:"user_notes"`
+	LotSelections   LotSelections   `gorm:"type:json" json:"lot_selections,omitempty"`
+	SplitRatio      float64         `gorm:"type:decimal(15,6)" json:"split_ratio,omitempty"` // new shares per old share, Split only
+	// LinkedTransactionID pairs the Buy of a reinvested dividend with its Dividends transaction
+	LinkedTransactionID *uuid.UUID `gorm:"type:varchar(36);index" json:"linked_transaction_id,omitempty"`
+	// ExternalID is the ID of an imported transaction at its institution, such as an OFX
+	// BROKERID/ACCTID/FITID
+	ExternalID *string `gorm:"size:255" json:"external_id,omitempty"`
 	BaseModel
 
 	// User relationship - foreign key is UserID pointing to users.user_id
 	User User `gorm:"foreignKey:UserID;references:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user,omitempty"`
 }
 
+// LotSelections holds the specific lots a Sell transaction relieves, stored as JSON
+type LotSelections []types.LotSelection
+
+// Value implements driver.Valuer for LotSelections
+func (l LotSelections) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal lot selections: %w", err)
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner for LotSelections
+func (l *LotSelections) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for lot selections: %T", value)
+	}
+
+	if len(data) == 0 || string(data) == "null" {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(data, l)
+}
+
 // TableName specifies the table name for Transaction model
 func (Transaction) TableName() string {
 	return "transactions"
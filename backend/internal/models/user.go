@@ -17,12 +17,19 @@ type User struct {
 	FirstName    string    `gorm:"size:100" json:"first_name"`
 	LastName     string    `gorm:"size:100" json:"last_name"`
 	IsActive     bool      `gorm:"default:true" json:"is_active"`
+	// CostBasisMethod is the user's default lot relief method for portfolio calculations
+	CostBasisMethod CostBasisMethod `gorm:"size:20;not null;default:'average'" json:"cost_basis_method"`
+	// BaseCurrency is the ISO currency code portfolio values are converted into
+	BaseCurrency string `gorm:"size:3;not null;default:'USD'" json:"base_currency"`
 	BaseModel
 
 	Transactions []Transaction `gorm:"foreignKey:UserID;references:UserID" json:"transactions,omitempty"`
 	JWTTokens    []JWTToken    `gorm:"foreignKey:UserID;references:UserID" json:"jwt_tokens,omitempty"`
 }
 
+// DefaultBaseCurrency is used when a user has not chosen a base currency
+const DefaultBaseCurrency = "USD"
+
 // TableName specifies the table name for User model
 func (User) TableName() string {
 	return "users"
@@ -0,0 +1,323 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// PDF values are kept close to their source so that objects can be written back unchanged:
+// strings and keywords keep their original bytes, and numbers their original text
+type (
+	number  string
+	name    string
+	literal []byte
+	array   []any
+	dict    map[string]any
+	ref     struct{ num, gen int }
+	// outputRef is a reference to an object of a written document, by its new number
+	outputRef int
+)
+
+// stream is a stream object: its dictionary and its still encoded data
+type stream struct {
+	dict dict
+	data []byte
+}
+
+func (n number) int() (int, bool) {
+	value, err := strconv.Atoi(string(n))
+	return value, err == nil
+}
+
+// nameValue returns the name under a key of a dictionary, or an empty string
+func (d dict) nameValue(key string) string {
+	if value, ok := d[key].(name); ok {
+		return string(value)
+	}
+	return ""
+}
+
+// intValue returns the integer under a key of a dictionary
+func (d dict) intValue(key string) (int, bool) {
+	if value, ok := d[key].(number); ok {
+		return value.int()
+	}
+	return 0, false
+}
+
+// maxNesting bounds how deeply arrays and dictionaries may nest, so that crafted input cannot
+// exhaust the stack
+const maxNesting = 64
+
+// parser reads PDF values from a byte slice
+type parser struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// skipSpace skips whitespace and comments
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case isWhitespace(c):
+			p.pos++
+		case c == '%':
+			for p.pos < len(p.data) && p.data[p.pos] != '\r' && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// token reads a run of regular characters, such as a number or a keyword
+func (p *parser) token() string {
+	start := p.pos
+	for p.pos < len(p.data) && !isWhitespace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+// value reads the next value; a number followed by a generation and R is read as a reference
+func (p *parser) value() (any, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxNesting {
+		return nil, fmt.Errorf("values nested too deeply at offset %d", p.pos)
+	}
+
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, fmt.Errorf("unexpected end of data")
+	}
+
+	switch c := p.data[p.pos]; {
+	case c == '/':
+		p.pos++
+		return name(p.token()), nil
+	case c == '(':
+		return p.literalString()
+	case c == '<' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '<':
+		return p.dictionary()
+	case c == '<':
+		end := bytes.IndexByte(p.data[p.pos:], '>')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated hex string")
+		}
+		value := literal(p.data[p.pos : p.pos+end+1])
+		p.pos += end + 1
+		return value, nil
+	case c == '[':
+		p.pos++
+		var items array
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.data) {
+				return nil, fmt.Errorf("unterminated array")
+			}
+			if p.data[p.pos] == ']' {
+				p.pos++
+				return items, nil
+			}
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		num := number(p.token())
+		if reference, ok := p.reference(num); ok {
+			return reference, nil
+		}
+		return num, nil
+	}
+
+	start := p.pos
+	switch keyword := p.token(); keyword {
+	case "true", "false":
+		return literal(keyword), nil
+	case "null":
+		return nil, nil
+	case "":
+		return nil, fmt.Errorf("unexpected character %q at offset %d", p.data[p.pos], p.pos)
+	default:
+		p.pos = start
+		return nil, fmt.Errorf("unexpected keyword %q at offset %d", keyword, start)
+	}
+}
+
+// int reads a value that must be an integer
+func (p *parser) int() (int, bool) {
+	value, err := p.value()
+	if err != nil {
+		return 0, false
+	}
+	num, ok := value.(number)
+	if !ok {
+		return 0, false
+	}
+	return num.int()
+}
+
+// reference reads the "gen R" following an object number, restoring the position when they do not follow
+func (p *parser) reference(num number) (ref, bool) {
+	objectNumber, ok := num.int()
+	if !ok {
+		return ref{}, false
+	}
+	start := p.pos
+	p.skipSpace()
+	generation, err := strconv.Atoi(p.token())
+	if err == nil {
+		p.skipSpace()
+		if p.token() == "R" {
+			return ref{objectNumber, generation}, true
+		}
+	}
+	p.pos = start
+	return ref{}, false
+}
+
+// literalString reads a string in parentheses, which may nest balanced parentheses
+func (p *parser) literalString() (any, error) {
+	start := p.pos
+	depth := 0
+	for ; p.pos < len(p.data); p.pos++ {
+		switch p.data[p.pos] {
+		case '\\':
+			p.pos++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return literal(p.data[start:p.pos]), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unterminated string")
+}
+
+func (p *parser) dictionary() (dict, error) {
+	p.pos += 2
+	d := dict{}
+	for {
+		p.skipSpace()
+		if p.pos+1 < len(p.data) && p.data[p.pos] == '>' && p.data[p.pos+1] == '>' {
+			p.pos += 2
+			return d, nil
+		}
+		key, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		keyName, ok := key.(name)
+		if !ok {
+			return nil, fmt.Errorf("dictionary key is not a name at offset %d", p.pos)
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		d[string(keyName)] = value
+	}
+}
+
+// object reads the body of an indirect object after its "num gen obj" header, up to endobj. A
+// stream's data is read from its /Length when that is a direct number, or else up to endstream.
+func (p *parser) object() (any, error) {
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	d, ok := value.(dict)
+	if !ok {
+		return value, nil
+	}
+
+	start := p.pos
+	p.skipSpace()
+	if p.token() != "stream" {
+		p.pos = start
+		return d, nil
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '\r' {
+		p.pos++
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+		p.pos++
+	}
+
+	dataStart := p.pos
+	if length, ok := d.intValue("Length"); ok && length >= 0 && dataStart+length <= len(p.data) {
+		rest := bytes.TrimLeft(p.data[dataStart+length:], " \t\r\n\f\x00")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			p.pos = len(p.data) - len(rest) + len("endstream")
+			return &stream{dict: d, data: p.data[dataStart : dataStart+length]}, nil
+		}
+	}
+	end := bytes.Index(p.data[dataStart:], []byte("endstream"))
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated stream")
+	}
+	data := p.data[dataStart : dataStart+end]
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	p.pos = dataStart + end + len("endstream")
+	return &stream{dict: d, data: data}, nil
+}
+
+// write serializes a value, numbering references with renumber; a reference that renumber does
+// not know is written as null, which is how readers treat references to missing objects
+func write(buf *bytes.Buffer, value any, renumber func(ref) (int, bool)) {
+	switch value := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case number:
+		buf.WriteString(string(value))
+	case literal:
+		buf.Write(value)
+	case name:
+		buf.WriteString("/" + string(value))
+	case outputRef:
+		fmt.Fprintf(buf, "%d 0 R", int(value))
+	case ref:
+		if num, ok := renumber(value); ok {
+			fmt.Fprintf(buf, "%d 0 R", num)
+		} else {
+			buf.WriteString("null")
+		}
+	case array:
+		buf.WriteByte('[')
+		for i, item := range value {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			write(buf, item, renumber)
+		}
+		buf.WriteByte(']')
+	case dict:
+		buf.WriteString("<<")
+		for _, key := range sortedKeys(value) {
+			buf.WriteString("/" + key + " ")
+			write(buf, value[key], renumber)
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(">>")
+	}
+}
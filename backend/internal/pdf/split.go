@@ -0,0 +1,400 @@
+// Package pdf splits PDF documents into single-page documents, so that each page of a statement
+// can be sent to the AI model on its own. It reads just enough of the format to find the pages and
+// the objects they use: objects are found by scanning the file rather than through the
+// cross-reference table, which also copes with damaged tables and incremental updates.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// ErrEncrypted is returned for password protected or otherwise encrypted documents
+var ErrEncrypted = fmt.Errorf("encrypted PDFs are not supported")
+
+// PageLimitError is returned for a document with more pages than may be split
+type PageLimitError struct {
+	Pages int
+	Limit int
+}
+
+func (e *PageLimitError) Error() string {
+	return fmt.Sprintf("the document has %d pages; at most %d pages can be split", e.Pages, e.Limit)
+}
+
+// document holds the objects of a PDF by object number
+type document struct {
+	objects map[int]any
+}
+
+var objectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// readDocument scans the data for indirect objects. An object defined again later in the file,
+// as by an incremental update, replaces the earlier definition.
+func readDocument(data []byte) (*document, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return nil, fmt.Errorf("not a PDF file")
+	}
+
+	doc := &document{objects: make(map[int]any)}
+	var objectStreams []*stream
+	for pos := 0; pos < len(data); {
+		match := objectHeader.FindSubmatchIndex(data[pos:])
+		if match == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(data[pos+match[2] : pos+match[3]]))
+		p := &parser{data: data, pos: pos + match[1]}
+		value, err := p.object()
+		if err != nil {
+			// Not an object after all, such as a match inside binary data
+			pos += match[1]
+			continue
+		}
+		pos = p.pos
+
+		if s, ok := value.(*stream); ok {
+			switch s.dict.nameValue("Type") {
+			case "ObjStm":
+				objectStreams = append(objectStreams, s)
+				continue
+			case "XRef":
+				if _, ok := s.dict["Encrypt"]; ok {
+					return nil, ErrEncrypted
+				}
+				continue
+			}
+		}
+		doc.objects[num] = value
+	}
+
+	for _, trailer := range bytes.Split(data, []byte("trailer"))[1:] {
+		p := &parser{data: trailer}
+		if value, err := p.value(); err == nil {
+			if d, ok := value.(dict); ok && d["Encrypt"] != nil {
+				return nil, ErrEncrypted
+			}
+		}
+	}
+
+	// Objects compressed into object streams are only used where the file has no plain definition
+	for _, s := range objectStreams {
+		if err := doc.readObjectStream(s); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+var errMalformedObjectStream = fmt.Errorf("malformed object stream")
+
+// readObjectStream adds the objects packed into an object stream
+func (doc *document) readObjectStream(s *stream) error {
+	data, err := decode(s)
+	if err != nil {
+		return fmt.Errorf("failed to read object stream: %w", err)
+	}
+	count, _ := s.dict.intValue("N")
+	first, _ := s.dict.intValue("First")
+	if first < 0 || first > len(data) {
+		return errMalformedObjectStream
+	}
+
+	header := &parser{data: data[:first]}
+	for i := 0; i < count; i++ {
+		objectNumber, okNum := header.int()
+		objectOffset, okOffset := header.int()
+		if !okNum || !okOffset || objectOffset < 0 || objectOffset > len(data)-first {
+			return errMalformedObjectStream
+		}
+		if _, ok := doc.objects[objectNumber]; ok {
+			continue
+		}
+		p := &parser{data: data, pos: first + objectOffset}
+		value, err := p.value()
+		if err != nil {
+			return fmt.Errorf("malformed object %d in object stream: %w", objectNumber, err)
+		}
+		doc.objects[objectNumber] = value
+	}
+	return nil
+}
+
+// maxDecodedSize bounds the decoded size of a stream, so a small compressed upload cannot
+// expand without limit
+const maxDecodedSize = 64 << 20
+
+// decode returns the decoded data of a stream without filters or with Flate compression
+func decode(s *stream) ([]byte, error) {
+	filter := s.dict["Filter"]
+	if filters, ok := filter.(array); ok && len(filters) == 1 {
+		filter = filters[0]
+	}
+	switch filter {
+	case nil:
+		return s.data, nil
+	case name("FlateDecode"):
+		reader, err := zlib.NewReader(bytes.NewReader(s.data))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(io.LimitReader(reader, maxDecodedSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxDecodedSize {
+			return nil, fmt.Errorf("stream exceeds %d MB when decoded", maxDecodedSize>>20)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported filter %v", filter)
+	}
+}
+
+// resolve follows a reference to the object it names
+func (doc *document) resolve(value any) any {
+	for i := 0; i < 32; i++ {
+		reference, ok := value.(ref)
+		if !ok {
+			return value
+		}
+		value = doc.objects[reference.num]
+	}
+	return nil
+}
+
+// dictOf returns the dictionary of a value, or of a stream
+func (doc *document) dictOf(value any) dict {
+	switch value := doc.resolve(value).(type) {
+	case dict:
+		return value
+	case *stream:
+		return value.dict
+	}
+	return nil
+}
+
+// catalog returns the document catalog. The last catalog in the file is the current one.
+func (doc *document) catalog() (dict, error) {
+	numbers := make([]int, 0, len(doc.objects))
+	for num := range doc.objects {
+		numbers = append(numbers, num)
+	}
+	sort.Ints(numbers)
+	var catalog dict
+	for _, num := range numbers {
+		if d, ok := doc.objects[num].(dict); ok && d.nameValue("Type") == "Catalog" {
+			catalog = d
+		}
+	}
+	if catalog == nil {
+		return nil, fmt.Errorf("no document catalog")
+	}
+	return catalog, nil
+}
+
+// inheritedKeys are the page attributes a page may take from its ancestors in the page tree
+var inheritedKeys = []string{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+// page is a page of the document with the attributes it inherits filled in
+type page struct {
+	ref  ref
+	dict dict
+}
+
+// pages returns the pages of the document in order
+func (doc *document) pages() ([]page, error) {
+	catalog, err := doc.catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	var pages []page
+	visited := make(map[ref]bool)
+	var walk func(node any, inherited dict)
+	walk = func(node any, inherited dict) {
+		reference, _ := node.(ref)
+		if visited[reference] {
+			return
+		}
+		visited[reference] = true
+		d := doc.dictOf(node)
+		if d == nil {
+			return
+		}
+
+		attributes := dict{}
+		for key, value := range inherited {
+			attributes[key] = value
+		}
+		for _, key := range inheritedKeys {
+			if value, ok := d[key]; ok {
+				attributes[key] = value
+			}
+		}
+
+		if kids, ok := doc.resolve(d["Kids"]).(array); ok && d.nameValue("Type") != "Page" {
+			for _, kid := range kids {
+				walk(kid, attributes)
+			}
+			return
+		}
+		if d.nameValue("Type") == "Page" || d["Contents"] != nil {
+			pageDict := dict{}
+			for key, value := range d {
+				pageDict[key] = value
+			}
+			for key, value := range attributes {
+				pageDict[key] = value
+			}
+			pages = append(pages, page{ref: reference, dict: pageDict})
+		}
+	}
+	walk(catalog["Pages"], nil)
+
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("the document has no pages")
+	}
+	return pages, nil
+}
+
+// SplitPages returns each page of a PDF as a document of its own. A page keeps its content,
+// resources and size; annotations and links to the rest of the document are left out.
+func SplitPages(data []byte) ([][]byte, error) {
+	return SplitPagesUpTo(data, 0)
+}
+
+// SplitPagesUpTo splits a PDF like SplitPages, but returns a *PageLimitError without writing any
+// page when the document has more than maxPages pages. A maxPages of 0 or less sets no limit.
+func SplitPagesUpTo(data []byte, maxPages int) ([][]byte, error) {
+	doc, err := readDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := doc.pages()
+	if err != nil {
+		return nil, err
+	}
+	if maxPages > 0 && len(pages) > maxPages {
+		return nil, &PageLimitError{Pages: len(pages), Limit: maxPages}
+	}
+
+	split := make([][]byte, len(pages))
+	for i, page := range pages {
+		split[i] = doc.writePage(page)
+	}
+	return split, nil
+}
+
+// writePage writes a document holding a single page and the objects it uses. The objects are
+// numbered anew, starting with the catalog, the page tree and the page.
+func (doc *document) writePage(p page) []byte {
+	pageDict := dict{}
+	for key, value := range p.dict {
+		switch key {
+		case "Parent", "Annots", "B", "StructParents", "Thumb":
+		default:
+			pageDict[key] = value
+		}
+	}
+
+	// Objects that are pages or page tree nodes are never copied, so nothing leads back to the
+	// other pages
+	numbers := map[int]int{p.ref.num: 3}
+	var order []int
+	var collect func(value any)
+	collect = func(value any) {
+		switch value := value.(type) {
+		case ref:
+			if _, ok := numbers[value.num]; ok {
+				return
+			}
+			object, ok := doc.objects[value.num]
+			if !ok {
+				return
+			}
+			if d := doc.dictOf(object); d != nil && (d.nameValue("Type") == "Page" || d.nameValue("Type") == "Pages") {
+				return
+			}
+			numbers[value.num] = 4 + len(order)
+			order = append(order, value.num)
+			collect(object)
+		case array:
+			for _, item := range value {
+				collect(item)
+			}
+		case dict:
+			for _, key := range sortedKeys(value) {
+				collect(value[key])
+			}
+		case *stream:
+			// The length is written directly, so an object holding it is not needed
+			for _, key := range sortedKeys(value.dict) {
+				if key != "Length" {
+					collect(value.dict[key])
+				}
+			}
+		}
+	}
+	collect(pageDict)
+
+	renumber := func(reference ref) (int, bool) {
+		num, ok := numbers[reference.num]
+		return num, ok
+	}
+	pageDict["Parent"] = outputRef(2)
+	objects := []any{
+		dict{"Type": name("Catalog"), "Pages": outputRef(2)},
+		dict{"Type": name("Pages"), "Kids": array{outputRef(3)}, "Count": number("1")},
+		pageDict,
+	}
+	for _, num := range order {
+		objects = append(objects, doc.objects[num])
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		if s, ok := object.(*stream); ok {
+			streamDict := dict{}
+			for key, value := range s.dict {
+				streamDict[key] = value
+			}
+			streamDict["Length"] = number(strconv.Itoa(len(s.data)))
+			write(&buf, streamDict, renumber)
+			buf.WriteString("\nstream\n")
+			buf.Write(s.data)
+			buf.WriteString("\nendstream")
+		} else {
+			write(&buf, object, renumber)
+		}
+		buf.WriteString("\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<</Root 1 0 R /Size %d>>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// sortedKeys returns the keys of a dictionary in order, so output does not depend on map order
+func sortedKeys(d dict) []string {
+	keys := make([]string, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
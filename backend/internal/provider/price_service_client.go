@@ -21,6 +21,9 @@ type PriceServiceClient interface {
 	GetCurrentPrices(ctx context.Context, symbols []string) ([]SymbolCurrentPrice, error)
 	GetHistoricalPrices(ctx context.Context, symbols []string, resolution Resolution, fromDate, toDate string) ([]SymbolHistoricalPrice, error)
 	GetHistoricalPriceAtDate(ctx context.Context, symbol string, date string) (*SymbolHistoricalPrice, error)
+	GetCurrentFXRates(ctx context.Context, base string, quotes []string) ([]FXRate, error)
+	GetHistoricalFXRates(ctx context.Context, base, quote, fromDate, toDate string) (*FXHistoricalRates, error)
+	GetSecurityMetadata(ctx context.Context, symbols []string) ([]SecurityMetadata, error)
 	HealthCheck(ctx context.Context) (*HealthResponse, error)
 	IsHealthy() bool
 }
@@ -233,40 +236,49 @@ func (c *priceServiceClient) GetCurrentPrices(ctx context.Context, symbols []str
 	return response.Data, nil
 }
 
-// GetHistoricalPrices retrieves historical prices for the specified symbols
+// GetHistoricalPrices retrieves historical prices for the specified symbols. The price service
+// serves one symbol per request, so each symbol is fetched in turn; a date range takes precedence
+// over the resolution.
 func (c *priceServiceClient) GetHistoricalPrices(ctx context.Context, symbols []string, resolution Resolution, fromDate, toDate string) ([]SymbolHistoricalPrice, error) {
 	if len(symbols) == 0 {
 		return nil, fmt.Errorf("symbols list cannot be empty")
 	}
 
-	// Build query parameters
-	params := url.Values{}
-	params.Set("symbols", strings.Join(symbols, ","))
-	params.Set("resolution", string(resolution))
-	if fromDate != "" {
-		params.Set("from_date", fromDate)
-	}
-	if toDate != "" {
-		params.Set("to_date", toDate)
-	}
+	results := make([]SymbolHistoricalPrice, 0, len(symbols))
+	for _, symbol := range symbols {
+		// Build query parameters
+		params := url.Values{}
+		params.Set("symbol", symbol)
+		if fromDate != "" && toDate != "" {
+			params.Set("from", fromDate)
+			params.Set("to", toDate)
+		} else {
+			params.Set("resolution", string(resolution))
+		}
 
-	endpoint := fmt.Sprintf("/api/v1/price/historical?%s", params.Encode())
+		endpoint := fmt.Sprintf("/api/v1/price/historical?%s", params.Encode())
 
-	respBody, err := c.makeRequest(ctx, "GET", endpoint, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get historical prices: %w", err)
-	}
+		respBody, err := c.makeRequest(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get historical prices for %s: %w", symbol, err)
+		}
 
-	var response HistoricalPricesResponse
-	if err := json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
+		var response struct {
+			Success bool                  `json:"success"`
+			Data    SymbolHistoricalPrice `json:"data"`
+		}
+		if err := json.Unmarshal(respBody, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
 
-	if !response.Success {
-		return nil, fmt.Errorf("price service returned unsuccessful response")
+		if !response.Success {
+			return nil, fmt.Errorf("price service returned unsuccessful response")
+		}
+
+		results = append(results, response.Data)
 	}
 
-	return response.Data, nil
+	return results, nil
 }
 
 // GetHistoricalPriceAtDate retrieves historical price for a single symbol at a specific date
@@ -305,6 +317,97 @@ func (c *priceServiceClient) GetHistoricalPriceAtDate(ctx context.Context, symbo
 	return &response.Data, nil
 }
 
+// GetCurrentFXRates retrieves the current rates converting base into each quote currency
+func (c *priceServiceClient) GetCurrentFXRates(ctx context.Context, base string, quotes []string) ([]FXRate, error) {
+	if base == "" {
+		return nil, fmt.Errorf("base currency cannot be empty")
+	}
+	if len(quotes) == 0 {
+		return nil, fmt.Errorf("quote currencies cannot be empty")
+	}
+
+	params := url.Values{}
+	params.Set("base", base)
+	params.Set("quotes", strings.Join(quotes, ","))
+
+	endpoint := fmt.Sprintf("/api/v1/fx/current?%s", params.Encode())
+
+	respBody, err := c.makeRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current FX rates: %w", err)
+	}
+
+	var response CurrentFXRatesResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if !response.Success {
+		return nil, fmt.Errorf("price service returned unsuccessful response")
+	}
+
+	return response.Data, nil
+}
+
+// GetHistoricalFXRates retrieves the historical rates of a currency pair, optionally limited to a date range
+func (c *priceServiceClient) GetHistoricalFXRates(ctx context.Context, base, quote, fromDate, toDate string) (*FXHistoricalRates, error) {
+	if base == "" || quote == "" {
+		return nil, fmt.Errorf("base and quote currencies cannot be empty")
+	}
+
+	params := url.Values{}
+	params.Set("base", base)
+	params.Set("quote", quote)
+	if fromDate != "" && toDate != "" {
+		params.Set("from", fromDate)
+		params.Set("to", toDate)
+	}
+
+	endpoint := fmt.Sprintf("/api/v1/fx/historical?%s", params.Encode())
+
+	respBody, err := c.makeRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get historical FX rates: %w", err)
+	}
+
+	var response HistoricalFXRatesResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if !response.Success {
+		return nil, fmt.Errorf("price service returned unsuccessful response")
+	}
+
+	return &response.Data, nil
+}
+
+// GetSecurityMetadata retrieves the metadata of the specified symbols. Symbols the price service
+// has no metadata for are left out.
+func (c *priceServiceClient) GetSecurityMetadata(ctx context.Context, symbols []string) ([]SecurityMetadata, error) {
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("symbols list cannot be empty")
+	}
+
+	endpoint := fmt.Sprintf("/api/v1/metadata?symbols=%s", url.QueryEscape(strings.Join(symbols, ",")))
+
+	respBody, err := c.makeRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get security metadata: %w", err)
+	}
+
+	var response SecurityMetadataResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if !response.Success {
+		return nil, fmt.Errorf("price service returned unsuccessful response")
+	}
+
+	return response.Data, nil
+}
+
 // HealthCheck checks the health of the Price Service
 func (c *priceServiceClient) HealthCheck(ctx context.Context) (*HealthResponse, error) {
 	respBody, err := c.makeRequest(ctx, "GET", "/health", nil)
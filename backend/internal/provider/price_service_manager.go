@@ -3,10 +3,14 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/transaction-tracker/backend/config"
 )
 
+// maxSymbolsPerRequest matches the price service's default limit on symbols per request
+const maxSymbolsPerRequest = 50
+
 // PriceServiceManager manages Price Service integration with error handling
 type PriceServiceManager struct {
 	client PriceServiceClient
@@ -53,6 +57,50 @@ func (psm *PriceServiceManager) GetCurrentPrices(ctx context.Context, symbols []
 	return prices, nil
 }
 
+// GetCurrentPriceBatch retrieves the current prices of many symbols in as few requests as the price
+// service allows. A failed request or a symbol missing from the response only fails those symbols,
+// which are returned with the reason alongside the prices that were found.
+func (psm *PriceServiceManager) GetCurrentPriceBatch(ctx context.Context, symbols []string) (map[string]SymbolCurrentPrice, map[string]error) {
+	prices := make(map[string]SymbolCurrentPrice)
+	failures := make(map[string]error)
+
+	seen := make(map[string]bool)
+	var unique []string
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		unique = append(unique, symbol)
+	}
+
+	for start := 0; start < len(unique); start += maxSymbolsPerRequest {
+		end := min(start+maxSymbolsPerRequest, len(unique))
+		chunk := unique[start:end]
+
+		quotes, err := psm.client.GetCurrentPrices(ctx, chunk)
+		if err != nil {
+			for _, symbol := range chunk {
+				failures[symbol] = fmt.Errorf("failed to get current price for %s: %w", symbol, err)
+			}
+			continue
+		}
+		for _, quote := range quotes {
+			if quote.CurrentPrice > 0 {
+				prices[strings.ToUpper(quote.Symbol)] = quote
+			}
+		}
+		for _, symbol := range chunk {
+			if _, ok := prices[symbol]; !ok {
+				failures[symbol] = fmt.Errorf("no price data returned for symbol %s", symbol)
+			}
+		}
+	}
+
+	return prices, failures
+}
+
 // GetHistoricalPrices retrieves historical prices
 func (psm *PriceServiceManager) GetHistoricalPrices(ctx context.Context, symbols []string, resolution Resolution, fromDate, toDate string) ([]SymbolHistoricalPrice, error) {
 	return psm.client.GetHistoricalPrices(ctx, symbols, resolution, fromDate, toDate)
@@ -63,6 +111,71 @@ func (psm *PriceServiceManager) GetHistoricalPriceAtDate(ctx context.Context, sy
 	return psm.client.GetHistoricalPriceAtDate(ctx, symbol, date)
 }
 
+// GetCurrentFXRates returns the current rate converting each currency into base, keyed by currency.
+// A currency equal to base always converts at 1.
+func (psm *PriceServiceManager) GetCurrentFXRates(ctx context.Context, base string, currencies []string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	var foreign []string
+	for _, currency := range currencies {
+		if currency == base {
+			rates[currency] = 1
+			continue
+		}
+		if _, seen := rates[currency]; !seen {
+			rates[currency] = 0
+			foreign = append(foreign, currency)
+		}
+	}
+	if len(foreign) == 0 {
+		return rates, nil
+	}
+
+	// One unit of base buys Rate units of the foreign currency, so conversion into base divides
+	fxRates, err := psm.client.GetCurrentFXRates(ctx, base, foreign)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current FX rates into %s: %w", base, err)
+	}
+	for _, fxRate := range fxRates {
+		if fxRate.Rate > 0 {
+			rates[fxRate.Quote] = 1 / fxRate.Rate
+		}
+	}
+
+	for _, currency := range foreign {
+		if rates[currency] <= 0 {
+			return nil, fmt.Errorf("no FX rate returned for %s/%s", currency, base)
+		}
+	}
+
+	return rates, nil
+}
+
+// GetHistoricalFXRates retrieves the historical rates converting one unit of currency into base
+func (psm *PriceServiceManager) GetHistoricalFXRates(ctx context.Context, currency, base, fromDate, toDate string) (*FXHistoricalRates, error) {
+	return psm.client.GetHistoricalFXRates(ctx, currency, base, fromDate, toDate)
+}
+
+// GetSecurityMetadata retrieves the metadata of the specified symbols, keyed by symbol
+func (psm *PriceServiceManager) GetSecurityMetadata(ctx context.Context, symbols []string) (map[string]SecurityMetadata, error) {
+	metadata := make(map[string]SecurityMetadata)
+	if len(symbols) == 0 {
+		return metadata, nil
+	}
+
+	for start := 0; start < len(symbols); start += maxSymbolsPerRequest {
+		end := min(start+maxSymbolsPerRequest, len(symbols))
+		securities, err := psm.client.GetSecurityMetadata(ctx, symbols[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, security := range securities {
+			metadata[security.Symbol] = security
+		}
+	}
+
+	return metadata, nil
+}
+
 // HealthCheck performs a health check on the Price Service
 func (psm *PriceServiceManager) HealthCheck(ctx context.Context) (*HealthResponse, error) {
 	return psm.client.HealthCheck(ctx)
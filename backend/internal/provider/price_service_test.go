@@ -3,8 +3,10 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -109,6 +111,52 @@ func TestPriceServiceClient_GetCurrentPrices_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "INVALID_INPUT")
 }
 
+func TestPriceServiceClient_GetHistoricalPrices(t *testing.T) {
+	// Mock server serving one symbol per request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/price/historical", r.URL.Path)
+		assert.Equal(t, "2025-07-01", r.URL.Query().Get("from"))
+		assert.Equal(t, "2025-07-03", r.URL.Query().Get("to"))
+
+		symbol := r.URL.Query().Get("symbol")
+		response := map[string]interface{}{
+			"success": true,
+			"data": SymbolHistoricalPrice{
+				Symbol:     symbol,
+				Resolution: ResolutionDaily,
+				HistoricalPrices: []ClosePrice{
+					{Date: "2025-07-03", Price: 101},
+					{Date: "2025-07-01", Price: 100},
+				},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		PriceService: config.PriceServiceConfig{
+			BaseURL:            server.URL,
+			PriceServiceApiKey: "test-key",
+			Timeout:            30 * time.Second,
+			MaxRetries:         0,
+		},
+	}
+
+	client := NewPriceServiceClient(cfg)
+	prices, err := client.GetHistoricalPrices(context.Background(), []string{"SPY", "QQQ"}, ResolutionDaily, "2025-07-01", "2025-07-03")
+	require.NoError(t, err)
+	require.Len(t, prices, 2)
+	assert.Equal(t, "SPY", prices[0].Symbol)
+	assert.Equal(t, "QQQ", prices[1].Symbol)
+	assert.Len(t, prices[1].HistoricalPrices, 2)
+}
+
 func TestPriceServiceClient_HealthCheck(t *testing.T) {
 	// Mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -189,6 +237,160 @@ func TestPriceServiceManager_GetCurrentPrice(t *testing.T) {
 	assert.Equal(t, 150.00, price.CurrentPrice)
 }
 
+func TestPriceServiceManager_GetCurrentPriceBatch(t *testing.T) {
+	// Mock server pricing every symbol except DELISTED, and failing any request that includes BROKEN
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/price/current", r.URL.Path)
+		requests++
+
+		symbols := strings.Split(r.URL.Query().Get("symbols"), ",")
+		var data []SymbolCurrentPrice
+		for _, symbol := range symbols {
+			if symbol == "BROKEN" {
+				http.Error(w, "upstream failure", http.StatusBadGateway)
+				return
+			}
+			if symbol != "DELISTED" {
+				data = append(data, SymbolCurrentPrice{Symbol: symbol, CurrentPrice: 10, Currency: "USD"})
+			}
+		}
+		response := CurrentPricesResponse{Success: true, Data: data, Timestamp: time.Now()}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		PriceService: config.PriceServiceConfig{
+			BaseURL:            server.URL,
+			PriceServiceApiKey: "test-key",
+			Timeout:            30 * time.Second,
+			MaxRetries:         0,
+		},
+	}
+
+	// Duplicates are dropped, so the first request carries DELISTED and SYM0 to SYM48 and
+	// the second carries BROKEN
+	symbols := []string{"DELISTED", "sym0", "SYM0"}
+	for i := 1; i < 49; i++ {
+		symbols = append(symbols, fmt.Sprintf("SYM%d", i))
+	}
+	symbols = append(symbols, "BROKEN", "SYM49")
+
+	manager := NewPriceServiceManager(cfg)
+	prices, failures := manager.GetCurrentPriceBatch(context.Background(), symbols)
+	assert.Equal(t, 2, requests)
+	assert.Len(t, prices, 49)
+	assert.Equal(t, 10.0, prices["SYM0"].CurrentPrice)
+
+	// A failed request only fails its own symbols
+	require.Len(t, failures, 3)
+	assert.Contains(t, failures["DELISTED"].Error(), "no price data returned")
+	assert.Contains(t, failures["BROKEN"].Error(), "failed to get current price for BROKEN")
+	assert.Contains(t, failures["SYM49"].Error(), "failed to get current price for SYM49")
+}
+
+func TestPriceServiceManager_GetCurrentFXRates(t *testing.T) {
+	// Mock server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/fx/current", r.URL.Path)
+		assert.Equal(t, "USD", r.URL.Query().Get("base"))
+		assert.Equal(t, "TWD,EUR", r.URL.Query().Get("quotes"))
+
+		response := CurrentFXRatesResponse{
+			Success: true,
+			Data: []FXRate{
+				{Base: "USD", Quote: "TWD", Rate: 32, Date: "2025-07-14", Timestamp: time.Now()},
+				{Base: "USD", Quote: "EUR", Rate: 0.8, Date: "2025-07-14", Timestamp: time.Now()},
+			},
+			Timestamp: time.Now(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		PriceService: config.PriceServiceConfig{
+			BaseURL:            server.URL,
+			PriceServiceApiKey: "test-key",
+			Timeout:            30 * time.Second,
+			MaxRetries:         0,
+		},
+	}
+
+	manager := NewPriceServiceManager(cfg)
+	ctx := context.Background()
+
+	rates, err := manager.GetCurrentFXRates(ctx, "USD", []string{"USD", "TWD", "EUR", "TWD"})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, rates["USD"])
+	assert.InDelta(t, 1.0/32, rates["TWD"], 1e-12)
+	assert.InDelta(t, 1.25, rates["EUR"], 1e-12)
+
+	// Only the base currency needs no request
+	rates, err = manager.GetCurrentFXRates(ctx, "USD", []string{"USD"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"USD": 1}, rates)
+}
+
+func TestPriceServiceManager_GetSecurityMetadata(t *testing.T) {
+	// Mock server returning metadata for every symbol except UNKNOWN
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/metadata", r.URL.Path)
+		requests++
+
+		var data []SecurityMetadata
+		for _, symbol := range strings.Split(r.URL.Query().Get("symbols"), ",") {
+			if symbol != "UNKNOWN" {
+				data = append(data, SecurityMetadata{Symbol: symbol, Sector: "Technology", AssetClass: "equity"})
+			}
+		}
+		response := SecurityMetadataResponse{Success: true, Data: data, Timestamp: time.Now()}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		PriceService: config.PriceServiceConfig{
+			BaseURL:            server.URL,
+			PriceServiceApiKey: "test-key",
+			Timeout:            30 * time.Second,
+			MaxRetries:         0,
+		},
+	}
+
+	// More symbols than fit in one request are split across several
+	symbols := []string{"UNKNOWN"}
+	for i := 0; i < 60; i++ {
+		symbols = append(symbols, fmt.Sprintf("SYM%d", i))
+	}
+
+	manager := NewPriceServiceManager(cfg)
+	metadata, err := manager.GetSecurityMetadata(context.Background(), symbols)
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Len(t, metadata, 60)
+	assert.Equal(t, "Technology", metadata["SYM0"].Sector)
+	_, ok := metadata["UNKNOWN"]
+	assert.False(t, ok)
+}
+
 func TestCircuitBreaker(t *testing.T) {
 	cb := NewCircuitBreaker(2, 100*time.Millisecond)
 
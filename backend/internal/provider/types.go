@@ -12,6 +12,9 @@ const (
 	ResolutionIntraday Resolution = "intraday"
 )
 
+// IntradayTimeFormat is the format of intraday price timestamps, always in UTC
+const IntradayTimeFormat = "2006-01-02T15:04:05Z"
+
 // SymbolCurrentPrice represents current price data for a symbol
 type SymbolCurrentPrice struct {
 	Symbol        string    `json:"symbol"`
@@ -25,7 +28,7 @@ type SymbolCurrentPrice struct {
 
 // ClosePrice represents a date-price pair
 type ClosePrice struct {
-	Date  string  `json:"date"` // YYYY-MM-DD format
+	Date  string  `json:"date"` // YYYY-MM-DD format, or IntradayTimeFormat for intraday prices
 	Price float64 `json:"price"`
 }
 
@@ -36,6 +39,39 @@ type SymbolHistoricalPrice struct {
 	HistoricalPrices []ClosePrice `json:"historical_prices"`
 }
 
+// FXRate represents the rate converting one unit of Base into Quote
+type FXRate struct {
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Rate      float64   `json:"rate"`
+	Date      string    `json:"date"` // YYYY-MM-DD format
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FXRatePoint represents a date-rate pair
+type FXRatePoint struct {
+	Date string  `json:"date"` // YYYY-MM-DD format
+	Rate float64 `json:"rate"`
+}
+
+// FXHistoricalRates represents the historical rates of a currency pair, newest first
+type FXHistoricalRates struct {
+	Base  string        `json:"base"`
+	Quote string        `json:"quote"`
+	Rates []FXRatePoint `json:"rates"`
+}
+
+// SecurityMetadata represents descriptive data about a security
+type SecurityMetadata struct {
+	Symbol     string `json:"symbol"`
+	Name       string `json:"name"`
+	Sector     string `json:"sector"`
+	Industry   string `json:"industry"`
+	Country    string `json:"country"` // ISO 3166-1 alpha-2 code
+	AssetClass string `json:"asset_class"`
+	Exchange   string `json:"exchange"`
+}
+
 // ErrorCode represents error codes from Price Service
 type ErrorCode string
 
@@ -46,6 +82,7 @@ const (
 	ErrServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
 	ErrInvalidInput       ErrorCode = "INVALID_INPUT"
 	ErrUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrCurrencyNotFound   ErrorCode = "CURRENCY_NOT_FOUND"
 )
 
 // ErrorResponse represents the standard error response format from Price Service
@@ -80,6 +117,27 @@ type HistoricalPricesResponse struct {
 	Timestamp time.Time               `json:"timestamp"`
 }
 
+// CurrentFXRatesResponse represents the response from /api/v1/fx/current
+type CurrentFXRatesResponse struct {
+	Success   bool      `json:"success"`
+	Data      []FXRate  `json:"data"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// HistoricalFXRatesResponse represents the response from /api/v1/fx/historical
+type HistoricalFXRatesResponse struct {
+	Success   bool              `json:"success"`
+	Data      FXHistoricalRates `json:"data"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// SecurityMetadataResponse represents the response from /api/v1/metadata
+type SecurityMetadataResponse struct {
+	Success   bool               `json:"success"`
+	Data      []SecurityMetadata `json:"data"`
+	Timestamp time.Time          `json:"timestamp"`
+}
+
 // HealthResponse represents the response from /health endpoint
 type HealthResponse struct {
 	Status    string    `json:"status"`
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AccountRepository handles brokerage account database operations
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository creates a new account repository
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// Create creates a single account
+func (r *AccountRepository) Create(account *models.Account) error {
+	if err := r.db.Create(account).Error; err != nil {
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+	return nil
+}
+
+// GetByUserID retrieves all accounts of a user, ordered by name
+func (r *AccountRepository) GetByUserID(userID uuid.UUID) ([]models.Account, error) {
+	var accounts []models.Account
+	if err := r.db.Where("user_id = ?", userID).Order("name ASC").Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get accounts for user %s: %w", userID, err)
+	}
+	return accounts, nil
+}
+
+// GetByIDAndUserID retrieves an account by account_id and user_id
+func (r *AccountRepository) GetByIDAndUserID(id uuid.UUID, userID uuid.UUID) (*models.Account, error) {
+	var account models.Account
+	err := r.db.Where("account_id = ? AND user_id = ?", id, userID).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Update saves all fields of an account
+func (r *AccountRepository) Update(account *models.Account) error {
+	if err := r.db.Save(account).Error; err != nil {
+		return fmt.Errorf("failed to update account %s: %w", account.AccountID, err)
+	}
+	return nil
+}
+
+// DeleteByIDAndUserID deletes an account by account_id and user_id
+func (r *AccountRepository) DeleteByIDAndUserID(id uuid.UUID, userID uuid.UUID) error {
+	if err := r.db.Where("account_id = ? AND user_id = ?", id, userID).Delete(&models.Account{}).Error; err != nil {
+		return fmt.Errorf("failed to delete account %s: %w", id, err)
+	}
+	return nil
+}
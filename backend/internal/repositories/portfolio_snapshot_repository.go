@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// snapshotDateFormat is how snapshot dates are compared in queries
+const snapshotDateFormat = "2006-01-02"
+
+// asSnapshotDate keeps the calendar date of a time in the given location, since the driver
+// converts times into its configured location before writing a DATE column
+func asSnapshotDate(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// PortfolioSnapshotRepository handles portfolio snapshot database operations
+type PortfolioSnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewPortfolioSnapshotRepository creates a new portfolio snapshot repository
+func NewPortfolioSnapshotRepository(db *gorm.DB) *PortfolioSnapshotRepository {
+	return &PortfolioSnapshotRepository{db: db}
+}
+
+// GetByUserIDInRange retrieves a user's snapshots dated between two days inclusive, oldest first
+func (r *PortfolioSnapshotRepository) GetByUserIDInRange(userID uuid.UUID, from, to time.Time) ([]models.PortfolioSnapshot, error) {
+	var snapshots []models.PortfolioSnapshot
+	err := r.db.Where("user_id = ? AND snapshot_date >= ? AND snapshot_date <= ?", userID, from.Format(snapshotDateFormat), to.Format(snapshotDateFormat)).
+		Order("snapshot_date ASC").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio snapshots for user %s: %w", userID, err)
+	}
+	for i := range snapshots {
+		snapshots[i].SnapshotDate = asSnapshotDate(snapshots[i].SnapshotDate, time.UTC)
+	}
+	return snapshots, nil
+}
+
+// GetLatestByUserID retrieves a user's most recent snapshot, or nil when there is none
+func (r *PortfolioSnapshotRepository) GetLatestByUserID(userID uuid.UUID) (*models.PortfolioSnapshot, error) {
+	var snapshots []models.PortfolioSnapshot
+	err := r.db.Where("user_id = ?", userID).Order("snapshot_date DESC").Limit(1).Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest portfolio snapshot for user %s: %w", userID, err)
+	}
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+	snapshots[0].SnapshotDate = asSnapshotDate(snapshots[0].SnapshotDate, time.UTC)
+	return &snapshots[0], nil
+}
+
+// ReplaceFrom replaces a user's snapshots dated on or after a day in a single database transaction
+func (r *PortfolioSnapshotRepository) ReplaceFrom(userID uuid.UUID, from time.Time, snapshots []models.PortfolioSnapshot) error {
+	_, err := r.ReplaceFromIf(userID, from, snapshots, func() bool { return true })
+	return err
+}
+
+// ReplaceFromIf is ReplaceFrom that asks current, just before committing, whether the snapshots are
+// still wanted, and rolls back when they are not. It reports whether the snapshots were written.
+// The replaced rows stay locked until the commit, so a concurrent delete of them waits for it.
+func (r *PortfolioSnapshotRepository) ReplaceFromIf(userID uuid.UUID, from time.Time, snapshots []models.PortfolioSnapshot, current func() bool) (bool, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	// Ensure rollback on any error
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("user_id = ? AND snapshot_date >= ?", userID, from.Format(snapshotDateFormat)).Delete(&models.PortfolioSnapshot{}).Error; err != nil {
+		tx.Rollback()
+		return false, fmt.Errorf("failed to delete portfolio snapshots: %w", err)
+	}
+
+	for i := range snapshots {
+		snapshots[i].UserID = userID
+		snapshots[i].SnapshotDate = asSnapshotDate(snapshots[i].SnapshotDate, time.Local)
+	}
+	if len(snapshots) > 0 {
+		if err := tx.CreateInBatches(&snapshots, 500).Error; err != nil {
+			tx.Rollback()
+			return false, fmt.Errorf("failed to create portfolio snapshots: %w", err)
+		}
+	}
+
+	if !current() {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// DeleteFrom deletes a user's snapshots dated on or after a day
+func (r *PortfolioSnapshotRepository) DeleteFrom(userID uuid.UUID, from time.Time) error {
+	err := r.db.Where("user_id = ? AND snapshot_date >= ?", userID, from.Format(snapshotDateFormat)).Delete(&models.PortfolioSnapshot{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete portfolio snapshots for user %s: %w", userID, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// TargetAllocationRepository handles target allocation database operations
+type TargetAllocationRepository struct {
+	db *gorm.DB
+}
+
+// NewTargetAllocationRepository creates a new target allocation repository
+func NewTargetAllocationRepository(db *gorm.DB) *TargetAllocationRepository {
+	return &TargetAllocationRepository{db: db}
+}
+
+// GetByUserID retrieves all target allocations of a user, largest weight first
+func (r *TargetAllocationRepository) GetByUserID(userID uuid.UUID) ([]models.TargetAllocation, error) {
+	var targets []models.TargetAllocation
+	err := r.db.Where("user_id = ?", userID).
+		Order("weight DESC").
+		Order("target_type ASC").
+		Order("target_key ASC").
+		Find(&targets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target allocations for user %s: %w", userID, err)
+	}
+	return targets, nil
+}
+
+// ReplaceForUser replaces all target allocations of a user in a single database transaction
+func (r *TargetAllocationRepository) ReplaceForUser(userID uuid.UUID, targets []models.TargetAllocation) error {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	// Ensure rollback on any error
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.TargetAllocation{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to delete target allocations: %w", err)
+	}
+
+	for i := range targets {
+		targets[i].UserID = userID
+		if err := tx.Create(&targets[i]).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to create target allocation %d: %w", i+1, err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// TaxLotRepository handles tax lot ledger database operations
+type TaxLotRepository struct {
+	db *gorm.DB
+}
+
+// NewTaxLotRepository creates a new tax lot repository
+func NewTaxLotRepository(db *gorm.DB) *TaxLotRepository {
+	return &TaxLotRepository{db: db}
+}
+
+// ReplaceForSymbol replaces all lots and disposals of a user's symbol in a single database transaction
+func (r *TaxLotRepository) ReplaceForSymbol(userID uuid.UUID, symbol string, lots []models.TaxLot) error {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	// Ensure rollback on any error
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("user_id = ? AND symbol = ?", userID, symbol).Delete(&models.TaxLotDisposal{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to delete tax lot disposals for %s: %w", symbol, err)
+	}
+
+	if err := tx.Where("user_id = ? AND symbol = ?", userID, symbol).Delete(&models.TaxLot{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to delete tax lots for %s: %w", symbol, err)
+	}
+
+	for i := range lots {
+		// Disposals are created with their lot through the association
+		if err := tx.Create(&lots[i]).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to create tax lot %d for %s: %w", i+1, symbol, err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetByUserIDAndSymbol retrieves all lots of a user's symbol with their disposals, oldest first
+func (r *TaxLotRepository) GetByUserIDAndSymbol(userID uuid.UUID, symbol string) ([]models.TaxLot, error) {
+	var lots []models.TaxLot
+	err := r.db.Where("user_id = ? AND symbol = ?", userID, symbol).
+		Preload("Disposals", func(db *gorm.DB) *gorm.DB {
+			return db.Order("disposed_at ASC")
+		}).
+		Order("acquired_at ASC").
+		Find(&lots).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax lots for user %s and symbol %s: %w", userID, symbol, err)
+	}
+	return lots, nil
+}
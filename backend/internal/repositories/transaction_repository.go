@@ -78,6 +78,47 @@ func (r *TransactionRepository) GetByIDAndUserID(id uuid.UUID, userID uuid.UUID)
 	return &transaction, nil
 }
 
+// GetByIDsAndUserID retrieves the transactions among the given transaction_ids that belong to user_id (UUID)
+func (r *TransactionRepository) GetByIDsAndUserID(ids []uuid.UUID, userID uuid.UUID) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	if len(ids) == 0 {
+		return transactions, nil
+	}
+	err := r.db.Where("transaction_id IN ? AND user_id = ?", ids, userID).Find(&transactions).Error
+	return transactions, err
+}
+
+// GetLinkedByIDsAndUserID retrieves the user's transactions whose linked_transaction_id is one of the given IDs
+func (r *TransactionRepository) GetLinkedByIDsAndUserID(ids []uuid.UUID, userID uuid.UUID) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	if len(ids) == 0 {
+		return transactions, nil
+	}
+	err := r.db.Where("linked_transaction_id IN ? AND user_id = ?", ids, userID).Find(&transactions).Error
+	return transactions, err
+}
+
+// GetIDsByExternalIDs maps each of the given external IDs already stored for user_id to its transaction_id
+func (r *TransactionRepository) GetIDsByExternalIDs(userID uuid.UUID, externalIDs []string) (map[string]uuid.UUID, error) {
+	ids := make(map[string]uuid.UUID)
+	if len(externalIDs) == 0 {
+		return ids, nil
+	}
+	var transactions []models.Transaction
+	err := r.db.Select("transaction_id", "external_id").
+		Where("user_id = ? AND external_id IN ?", userID, externalIDs).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range transactions {
+		if transaction.ExternalID != nil {
+			ids[*transaction.ExternalID] = transaction.TransactionID
+		}
+	}
+	return ids, nil
+}
+
 // GetByUserID retrieves all transactions for a user by user_id (UUID)
 func (r *TransactionRepository) GetByUserID(userID uuid.UUID) ([]models.Transaction, error) {
 	var transactions []models.Transaction
@@ -85,6 +126,21 @@ func (r *TransactionRepository) GetByUserID(userID uuid.UUID) ([]models.Transact
 	return transactions, err
 }
 
+// GetByUserIDAndDateRange retrieves the transactions for a user dated from start up to, but not including, end
+func (r *TransactionRepository) GetByUserIDAndDateRange(userID uuid.UUID, start, end time.Time) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.db.Where("user_id = ? AND transaction_date >= ? AND transaction_date < ?", userID, start, end).
+		Order("transaction_date ASC").Find(&transactions).Error
+	return transactions, err
+}
+
+// GetUserIDs retrieves the IDs of every user with at least one transaction
+func (r *TransactionRepository) GetUserIDs() ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	err := r.db.Model(&models.Transaction{}).Distinct().Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
 // UpdateByID updates a transaction by transaction_id (UUID)
 func (r *TransactionRepository) UpdateByID(id uuid.UUID, updates map[string]interface{}) error {
 	return r.db.Model(&models.Transaction{}).Where("transaction_id = ?", id).Updates(updates).Error
@@ -143,48 +199,8 @@ func (r *TransactionRepository) GetWithFilters(userID *uuid.UUID, symbols []stri
 	orderBy string, orderDirection string, limit int, offset int) ([]models.Transaction, error) {
 
 	var transactions []models.Transaction
-	query := r.db.Model(&models.Transaction{})
-
-	// Apply filters
-	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
-	}
-	if len(symbols) > 0 {
-		query = query.Where("symbol IN ?", symbols)
-	}
-	if len(types) > 0 {
-		query = query.Where("trade_type IN ?", types)
-	}
-	if len(exchanges) > 0 {
-		query = query.Where("exchange IN ?", exchanges)
-	}
-	if len(brokers) > 0 {
-		query = query.Where("broker IN ?", brokers)
-	}
-	if len(currencies) > 0 {
-		query = query.Where("currency IN ?", currencies)
-	}
-	if startDate != nil {
-		query = query.Where("transaction_date >= ?", *startDate)
-	}
-	if endDate != nil {
-		query = query.Where("transaction_date <= ?", *endDate)
-	}
-	if minAmount != nil {
-		query = query.Where("amount >= ?", *minAmount)
-	}
-	if maxAmount != nil {
-		query = query.Where("amount <= ?", *maxAmount)
-	}
-
-	// Apply ordering
-	if orderBy == "" {
-		orderBy = "transaction_date"
-	}
-	if orderDirection == "" {
-		orderDirection = "DESC"
-	}
-	query = query.Order(fmt.Sprintf("%s %s", orderBy, orderDirection))
+	query := r.filteredQuery(userID, symbols, types, exchanges, brokers, currencies, startDate, endDate, minAmount, maxAmount)
+	query = orderedQuery(query, orderBy, orderDirection)
 
 	// Apply pagination
 	if limit > 0 {
@@ -204,11 +220,41 @@ func (r *TransactionRepository) GetWithFilters(userID *uuid.UUID, symbols []stri
 	return transactions, nil
 }
 
-// CountWithFilters returns the count of transactions based on filters
-func (r *TransactionRepository) CountWithFilters(userID *uuid.UUID, symbols []string, types []string, exchanges []string, brokers []string, currencies []string,
-	startDate *time.Time, endDate *time.Time, minAmount *float64, maxAmount *float64) (int64, error) {
+// StreamWithFilters calls fn with each transaction matching the filters, in order, reading them
+// one row at a time so that large histories are never held in memory. An error from fn stops the
+// stream and is returned.
+func (r *TransactionRepository) StreamWithFilters(userID *uuid.UUID, symbols []string, types []string, exchanges []string, brokers []string, currencies []string,
+	startDate *time.Time, endDate *time.Time, minAmount *float64, maxAmount *float64,
+	orderBy string, orderDirection string, fn func(models.Transaction) error) error {
+
+	query := r.filteredQuery(userID, symbols, types, exchanges, brokers, currencies, startDate, endDate, minAmount, maxAmount)
+	query = orderedQuery(query, orderBy, orderDirection)
+
+	rows, err := query.Rows()
+	if err != nil {
+		return fmt.Errorf("failed to stream filtered transactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := r.db.ScanRows(rows, &transaction); err != nil {
+			return fmt.Errorf("failed to read transaction: %w", err)
+		}
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to stream filtered transactions: %w", err)
+	}
+	return nil
+}
+
+// filteredQuery builds the transaction query shared by the filtered reads
+func (r *TransactionRepository) filteredQuery(userID *uuid.UUID, symbols []string, types []string, exchanges []string, brokers []string, currencies []string,
+	startDate *time.Time, endDate *time.Time, minAmount *float64, maxAmount *float64) *gorm.DB {
 
-	var count int64
 	query := r.db.Model(&models.Transaction{})
 
 	// Apply filters
@@ -242,6 +288,29 @@ func (r *TransactionRepository) CountWithFilters(userID *uuid.UUID, symbols []st
 	if maxAmount != nil {
 		query = query.Where("amount <= ?", *maxAmount)
 	}
+	return query
+}
+
+// orderedQuery orders a filtered query, newest first by default
+func orderedQuery(query *gorm.DB, orderBy string, orderDirection string) *gorm.DB {
+	switch orderBy {
+	case "":
+		orderBy = "transaction_date"
+	case "trade_amount":
+		orderBy = "amount"
+	}
+	if orderDirection == "" {
+		orderDirection = "DESC"
+	}
+	return query.Order(fmt.Sprintf("%s %s", orderBy, orderDirection))
+}
+
+// CountWithFilters returns the count of transactions based on filters
+func (r *TransactionRepository) CountWithFilters(userID *uuid.UUID, symbols []string, types []string, exchanges []string, brokers []string, currencies []string,
+	startDate *time.Time, endDate *time.Time, minAmount *float64, maxAmount *float64) (int64, error) {
+
+	var count int64
+	query := r.filteredQuery(userID, symbols, types, exchanges, brokers, currencies, startDate, endDate, minAmount, maxAmount)
 
 	if err := query.Count(&count).Error; err != nil {
 		return 0, fmt.Errorf("failed to count filtered transactions: %w", err)
@@ -344,3 +413,37 @@ func (r *TransactionRepository) GetByUserIDAndSymbol(userID uuid.UUID, symbol st
 	}
 	return transactions, nil
 }
+
+// GetByUserIDAndAccountID retrieves all transactions of a user made in a specific account
+func (r *TransactionRepository) GetByUserIDAndAccountID(userID uuid.UUID, accountID uuid.UUID) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.db.Where("user_id = ? AND account_id = ?", userID, accountID).
+		Order("transaction_date ASC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions for user %s and account %s: %w", userID, accountID, err)
+	}
+	return transactions, nil
+}
+
+// GetByUserIDAccountIDAndSymbol retrieves all transactions of a user for a symbol in a specific account
+func (r *TransactionRepository) GetByUserIDAccountIDAndSymbol(userID uuid.UUID, accountID uuid.UUID, symbol string) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.db.Where("user_id = ? AND account_id = ? AND symbol = ?", userID, accountID, symbol).
+		Order("transaction_date ASC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions for user %s, account %s and symbol %s: %w", userID, accountID, symbol, err)
+	}
+	return transactions, nil
+}
+
+// CountByAccountID returns the number of a user's transactions referencing an account
+func (r *TransactionRepository) CountByAccountID(accountID uuid.UUID, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Transaction{}).Where("account_id = ? AND user_id = ?", accountID, userID).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count transactions for account %s: %w", accountID, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// ErrAccountNotFound reports an account that does not exist or belongs to another user
+var ErrAccountNotFound = errors.New("account not found")
+
+// checkAccount verifies that an account exists and belongs to the user
+func checkAccount(accountRepo *repositories.AccountRepository, userID, accountID uuid.UUID) (*models.Account, error) {
+	account, err := accountRepo.GetByIDAndUserID(accountID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// AccountService handles brokerage account business logic
+type AccountService struct {
+	accountRepo     *repositories.AccountRepository
+	transactionRepo *repositories.TransactionRepository
+}
+
+// NewAccountService creates a new account service
+func NewAccountService(accountRepo *repositories.AccountRepository, transactionRepo *repositories.TransactionRepository) *AccountService {
+	return &AccountService{
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// GetAccounts returns all accounts of a user
+func (s *AccountService) GetAccounts(userID uuid.UUID) ([]models.Account, error) {
+	return s.accountRepo.GetByUserID(userID)
+}
+
+// GetAccount returns a single account of a user
+func (s *AccountService) GetAccount(userID, accountID uuid.UUID) (*models.Account, error) {
+	account, err := s.accountRepo.GetByIDAndUserID(accountID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("not_found")
+	}
+	return account, nil
+}
+
+// CreateAccount creates an account for a user
+func (s *AccountService) CreateAccount(userID uuid.UUID, name, broker string, accountType models.AccountType, baseCurrency string) (*models.Account, error) {
+	account := &models.Account{
+		UserID:       userID,
+		Name:         strings.TrimSpace(name),
+		Broker:       strings.TrimSpace(broker),
+		AccountType:  accountType,
+		BaseCurrency: strings.ToUpper(baseCurrency),
+	}
+	if err := s.accountRepo.Create(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// UpdateAccount updates the details of a user's account
+func (s *AccountService) UpdateAccount(userID, accountID uuid.UUID, name, broker string, accountType models.AccountType, baseCurrency string) (*models.Account, error) {
+	account, err := s.GetAccount(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	account.Name = strings.TrimSpace(name)
+	account.Broker = strings.TrimSpace(broker)
+	account.AccountType = accountType
+	account.BaseCurrency = strings.ToUpper(baseCurrency)
+
+	if err := s.accountRepo.Update(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// DeleteAccount deletes a user's account. Accounts still referenced by transactions are kept.
+func (s *AccountService) DeleteAccount(userID, accountID uuid.UUID) error {
+	if _, err := s.GetAccount(userID, accountID); err != nil {
+		return err
+	}
+
+	count, err := s.transactionRepo.CountByAccountID(accountID, userID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("in_use")
+	}
+
+	return s.accountRepo.DeleteByIDAndUserID(accountID, userID)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// AllocationExposure is the market value of a holding, or part of one, assigned to an allocation group
+type AllocationExposure struct {
+	Key         string
+	Symbol      string
+	MarketValue float64
+}
+
+// BuildAllocationReport sums exposures into groups and weighs each group and holding against the
+// total, largest group first
+func BuildAllocationReport(groupBy models.AllocationGrouping, currency string, exposures []AllocationExposure, now time.Time) *models.AllocationReport {
+	report := &models.AllocationReport{
+		GroupBy:   groupBy,
+		Currency:  currency,
+		Groups:    []models.AllocationGroup{},
+		Timestamp: now,
+	}
+
+	var total float64
+	groupIndex := make(map[string]int)
+	for _, exposure := range exposures {
+		if exposure.MarketValue <= 0 {
+			continue
+		}
+		key := exposure.Key
+		if key == "" {
+			key = models.AllocationUnknown
+		}
+
+		idx, ok := groupIndex[key]
+		if !ok {
+			idx = len(report.Groups)
+			groupIndex[key] = idx
+			report.Groups = append(report.Groups, models.AllocationGroup{Key: key})
+		}
+		group := &report.Groups[idx]
+		group.MarketValue += exposure.MarketValue
+		group.Holdings = append(group.Holdings, models.AllocationHolding{
+			Symbol:      exposure.Symbol,
+			MarketValue: exposure.MarketValue,
+		})
+		total += exposure.MarketValue
+	}
+
+	for i := range report.Groups {
+		group := &report.Groups[i]
+		sort.SliceStable(group.Holdings, func(a, b int) bool {
+			return group.Holdings[a].MarketValue > group.Holdings[b].MarketValue
+		})
+		for j := range group.Holdings {
+			group.Holdings[j].Weight = utils.RoundTo4(group.Holdings[j].MarketValue / total * 100)
+			group.Holdings[j].MarketValue = utils.RoundTo4(group.Holdings[j].MarketValue)
+		}
+		group.Weight = utils.RoundTo4(group.MarketValue / total * 100)
+		group.MarketValue = utils.RoundTo4(group.MarketValue)
+	}
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		return report.Groups[i].MarketValue > report.Groups[j].MarketValue
+	})
+	report.MarketValue = utils.RoundTo4(total)
+
+	return report
+}
+
+// BrokerPositions returns the shares of each symbol held at each broker, keyed by symbol then broker
+func BrokerPositions(transactions []models.Transaction) map[string]map[string]float64 {
+	ordered := make([]models.Transaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].TransactionDate.Before(ordered[j].TransactionDate)
+	})
+
+	positions := make(map[string]map[string]float64)
+	for _, tx := range ordered {
+		if tx.TradeType.IsCashMovement() {
+			continue
+		}
+		if positions[tx.Symbol] == nil {
+			positions[tx.Symbol] = make(map[string]float64)
+		}
+		positions[tx.Symbol][tx.Broker] = ApplyToPosition(positions[tx.Symbol][tx.Broker], tx)
+	}
+
+	return positions
+}
+
+// metadataAllocationKey returns the value of a metadata-backed grouping dimension
+func metadataAllocationKey(metadata provider.SecurityMetadata, groupBy models.AllocationGrouping) string {
+	switch groupBy {
+	case models.AllocationGroupingSector:
+		return metadata.Sector
+	case models.AllocationGroupingAssetClass:
+		return metadata.AssetClass
+	case models.AllocationGroupingCountry:
+		return metadata.Country
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// PriceSeries holds the daily closes of a symbol, oldest first
+type PriceSeries struct {
+	dates    []string
+	prices   []float64
+	intraday bool
+}
+
+// NewPriceSeries builds a series from close prices in any order
+func NewPriceSeries(points []provider.ClosePrice) *PriceSeries {
+	sorted := make([]provider.ClosePrice, 0, len(points))
+	for _, point := range points {
+		if point.Price > 0 {
+			sorted = append(sorted, point)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Date < sorted[j].Date
+	})
+
+	series := &PriceSeries{
+		dates:  make([]string, len(sorted)),
+		prices: make([]float64, len(sorted)),
+	}
+	for i, point := range sorted {
+		series.dates[i] = point.Date
+		series.prices[i] = point.Price
+	}
+	return series
+}
+
+// NewIntradayPriceSeries builds a series from intraday closes stamped with UTC timestamps
+func NewIntradayPriceSeries(points []provider.ClosePrice) *PriceSeries {
+	series := NewPriceSeries(points)
+	series.intraday = true
+	return series
+}
+
+// Len returns the number of closes in the series
+func (s *PriceSeries) Len() int {
+	return len(s.dates)
+}
+
+// PriceOn returns the latest close on or before the given date, carrying the previous close
+// over weekends and holidays. An intraday series returns the latest close at or before the time.
+func (s *PriceSeries) PriceOn(date time.Time) (float64, bool) {
+	key := date.Format("2006-01-02")
+	if s.intraday {
+		key = date.UTC().Format(provider.IntradayTimeFormat)
+	}
+	idx := sort.SearchStrings(s.dates, key)
+	if idx < len(s.dates) && s.dates[idx] == key {
+		return s.prices[idx], true
+	}
+	if idx == 0 {
+		return 0, false
+	}
+	return s.prices[idx-1], true
+}
+
+// SimulateBenchmark returns the value at each time point of a benchmark position that starts with
+// the portfolio's first value and then buys or sells the benchmark with every later external flow.
+// Flows must be in the same currency as startValue; the benchmark is treated as an index, so its
+// own quote currency does not matter.
+func SimulateBenchmark(prices *PriceSeries, timePoints []time.Time, startValue float64, flows []ExternalCashFlow) []float64 {
+	values := make([]float64, len(timePoints))
+	if len(timePoints) == 0 {
+		return values
+	}
+
+	var units float64
+	if price, ok := prices.PriceOn(timePoints[0]); ok {
+		units = startValue / price
+	}
+
+	next := 0
+	for i, timePoint := range timePoints {
+		for ; next < len(flows) && !flows[next].Date.After(timePoint); next++ {
+			// Flows up to the first point are already part of the starting value
+			if !flows[next].Date.After(timePoints[0]) {
+				continue
+			}
+			if price, ok := prices.PriceOn(flows[next].Date); ok {
+				units = math.Max(units+flows[next].Amount/price, 0)
+			}
+		}
+		if price, ok := prices.PriceOn(timePoint); ok {
+			values[i] = units * price
+		}
+	}
+
+	return values
+}
+
+// CompareToBenchmark returns the annualized alpha, the beta and the annualized tracking error of
+// a portfolio's periodic returns against a benchmark's returns over the same periods
+func CompareToBenchmark(portfolioReturns, benchmarkReturns []float64, periodsPerYear float64) (alpha, beta, trackingError float64) {
+	if len(portfolioReturns) < 2 || len(portfolioReturns) != len(benchmarkReturns) {
+		return 0, 0, 0
+	}
+
+	beta = Beta(portfolioReturns, benchmarkReturns)
+	alpha = (utils.Mean(portfolioReturns) - beta*utils.Mean(benchmarkReturns)) * periodsPerYear
+
+	activeReturns := make([]float64, len(portfolioReturns))
+	for i := range portfolioReturns {
+		activeReturns[i] = portfolioReturns[i] - benchmarkReturns[i]
+	}
+	trackingError = utils.StandardDeviation(activeReturns) * math.Sqrt(periodsPerYear)
+
+	return alpha, beta, trackingError
+}
+
+// periodsPerYear returns how many intervals of the average spacing between time points fit in a year
+func periodsPerYear(timePoints []time.Time) float64 {
+	if len(timePoints) < 2 {
+		return 0
+	}
+	span := timePoints[len(timePoints)-1].Sub(timePoints[0]).Hours() / 24
+	if span <= 0 {
+		return 0
+	}
+	return 365.25 / (span / float64(len(timePoints)-1))
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+// CashFlowAmount returns the signed change a transaction makes to its broker's cash balance
+func CashFlowAmount(tx models.Transaction) float64 {
+	switch tx.TradeType {
+	case types.TradeTypeDeposit, types.TradeTypeInterest, types.TradeTypeSell, types.TradeTypeDividend:
+		return tx.Amount
+	case types.TradeTypeWithdrawal, types.TradeTypeFee, types.TradeTypeTax, types.TradeTypeBuy:
+		return -tx.Amount
+	default:
+		return 0
+	}
+}
+
+// cashBalanceKey identifies a cash balance by broker and currency
+type cashBalanceKey struct {
+	broker   string
+	currency string
+}
+
+// cashTrackedBrokers returns the brokers with at least one Deposit or Withdrawal
+func cashTrackedBrokers(transactions []models.Transaction) map[string]bool {
+	tracked := make(map[string]bool)
+	for _, tx := range transactions {
+		if tx.TradeType == types.TradeTypeDeposit || tx.TradeType == types.TradeTypeWithdrawal {
+			tracked[tx.Broker] = true
+		}
+	}
+	return tracked
+}
+
+// CalculateCashBalances returns the cash balance of each broker and currency as of a date.
+// Cash is only tracked for brokers with at least one Deposit or Withdrawal; without them the
+// balance would just be the negative of every purchase.
+func CalculateCashBalances(transactions []models.Transaction, asOf time.Time) []models.CashBalance {
+	tracked := cashTrackedBrokers(transactions)
+	if len(tracked) == 0 {
+		return []models.CashBalance{}
+	}
+
+	balances := make(map[cashBalanceKey]float64)
+	for _, tx := range transactions {
+		if !tracked[tx.Broker] || tx.TransactionDate.After(asOf) {
+			continue
+		}
+		key := cashBalanceKey{broker: tx.Broker, currency: strings.ToUpper(tx.Currency)}
+		balances[key] += CashFlowAmount(tx)
+	}
+
+	result := make([]models.CashBalance, 0, len(balances))
+	for key, balance := range balances {
+		result = append(result, models.CashBalance{
+			Broker:   key.broker,
+			Currency: key.currency,
+			Balance:  balance,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Broker != result[j].Broker {
+			return result[i].Broker < result[j].Broker
+		}
+		return result[i].Currency < result[j].Currency
+	})
+
+	return result
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/repositories"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+// lotQuantityEpsilon is the remaining quantity below which a lot is considered fully relieved
+const lotQuantityEpsilon = 1e-9
+
+// TaxLot represents a single acquisition lot tracked by the cost basis engine
+type TaxLot struct {
+	TransactionID    uuid.UUID
+	Symbol           string
+	AcquiredAt       time.Time
+	OriginalQuantity float64
+	Quantity         float64 // remaining quantity
+	UnitCost         float64
+	ClosedAt         time.Time // zero while the lot is open
+}
+
+// CostBasis returns the remaining cost basis of the lot
+func (l TaxLot) CostBasis() float64 {
+	return l.Quantity * l.UnitCost
+}
+
+// LotDisposal records the portion of a lot relieved by a Sell transaction
+type LotDisposal struct {
+	SellTransactionID uuid.UUID
+	LotTransactionID  uuid.UUID
+	Symbol            string
+	AcquiredAt        time.Time
+	SoldAt            time.Time
+	Quantity          float64
+	CostBasis         float64
+	Proceeds          float64
+	GainLoss          float64 // after adding back any disallowed wash sale loss
+
+	WashSale                  bool
+	DisallowedLoss            float64
+	ReplacementTransactionIDs []uuid.UUID
+}
+
+// CostBasisResult holds the outcome of running transactions through the cost basis engine
+type CostBasisResult struct {
+	Method           models.CostBasisMethod
+	OpenLots         []TaxLot
+	ClosedLots       []TaxLot
+	Disposals        []LotDisposal
+	WashSales        []WashSaleAdjustment
+	TotalQuantity    float64
+	TotalCost        float64
+	RealizedGainLoss float64
+}
+
+// UnitCost returns the weighted unit cost of the remaining open lots
+func (r CostBasisResult) UnitCost() float64 {
+	if r.TotalQuantity <= 0 {
+		return 0
+	}
+	return r.TotalCost / r.TotalQuantity
+}
+
+// resolveCostBasisMethod returns the requested method when valid, otherwise the user's saved default
+func resolveCostBasisMethod(userRepo repositories.UserRepository, userID uuid.UUID, requested models.CostBasisMethod) models.CostBasisMethod {
+	if requested.IsValid() {
+		return requested
+	}
+
+	if userRepo != nil {
+		user, err := userRepo.FindByUserID(userID)
+		if err == nil && user.CostBasisMethod.IsValid() {
+			return user.CostBasisMethod
+		}
+	}
+
+	return models.DefaultCostBasisMethod
+}
+
+// CalculateCostBasis replays a single symbol's transactions in date order and relieves
+// lots on every Sell according to the given method. Losses replaced within the wash sale
+// window are disallowed and added to the replacement lot's basis.
+func CalculateCostBasis(transactions []models.Transaction, method models.CostBasisMethod) CostBasisResult {
+	if !method.IsValid() {
+		method = models.DefaultCostBasisMethod
+	}
+
+	ordered := make([]models.Transaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].TransactionDate.Before(ordered[j].TransactionDate)
+	})
+
+	result := CostBasisResult{Method: method}
+	var lots []TaxLot
+	washSales := newWashSaleTracker(ordered)
+
+	for _, tx := range ordered {
+		switch tx.TradeType {
+		case types.TradeTypeBuy:
+			unitCost := tx.Price
+			if tx.Quantity > 0 {
+				unitCost += washSales.takePending(tx.TransactionID) / tx.Quantity
+			}
+			lots = append(lots, TaxLot{
+				TransactionID:    tx.TransactionID,
+				Symbol:           tx.Symbol,
+				AcquiredAt:       tx.TransactionDate,
+				OriginalQuantity: tx.Quantity,
+				Quantity:         tx.Quantity,
+				UnitCost:         unitCost,
+			})
+		case types.TradeTypeSell:
+			var closed []TaxLot
+			var disposals []LotDisposal
+			lots, closed, disposals = relieveLots(lots, tx, method)
+			result.WashSales = append(result.WashSales, washSales.apply(lots, tx, disposals)...)
+			result.ClosedLots = append(result.ClosedLots, closed...)
+			for _, d := range disposals {
+				result.RealizedGainLoss += d.GainLoss
+			}
+			result.Disposals = append(result.Disposals, disposals...)
+		case types.TradeTypeSplit:
+			applySplit(lots, tx.SplitRatio)
+			washSales.applySplit(tx, tx.SplitRatio)
+		}
+	}
+
+	for _, lot := range lots {
+		if lot.Quantity <= lotQuantityEpsilon {
+			continue
+		}
+		result.OpenLots = append(result.OpenLots, lot)
+		result.TotalQuantity += lot.Quantity
+		result.TotalCost += lot.CostBasis()
+	}
+
+	return result
+}
+
+// applySplit scales the quantity and per-share cost of every open lot by a split ratio,
+// leaving each lot's total cost basis unchanged
+func applySplit(lots []TaxLot, ratio float64) {
+	if ratio <= 0 {
+		return
+	}
+	for i := range lots {
+		lots[i].Quantity *= ratio
+		lots[i].OriginalQuantity *= ratio
+		lots[i].UnitCost /= ratio
+	}
+}
+
+// ApplyToPosition returns the share count after applying a transaction to a position.
+// Cash dividends leave the share count unchanged.
+func ApplyToPosition(quantity float64, tx models.Transaction) float64 {
+	switch tx.TradeType {
+	case types.TradeTypeBuy:
+		return quantity + tx.Quantity
+	case types.TradeTypeSell:
+		return quantity - tx.Quantity
+	case types.TradeTypeSplit:
+		if tx.SplitRatio > 0 {
+			return quantity * tx.SplitRatio
+		}
+		return quantity
+	default:
+		return quantity
+	}
+}
+
+// relieveLots consumes open lots for a Sell transaction and returns the remaining open lots,
+// the lots it closed and the disposals it made
+func relieveLots(lots []TaxLot, sell models.Transaction, method models.CostBasisMethod) ([]TaxLot, []TaxLot, []LotDisposal) {
+	var available float64
+	for _, lot := range lots {
+		available += lot.Quantity
+	}
+	if available <= lotQuantityEpsilon {
+		return lots, nil, nil
+	}
+
+	remaining := sell.Quantity
+	if remaining > available {
+		remaining = available
+	}
+
+	// Under the average method every share carries the pooled unit cost
+	var averageCost float64
+	if method == models.CostBasisAverage {
+		var totalCost float64
+		for _, lot := range lots {
+			totalCost += lot.CostBasis()
+		}
+		averageCost = totalCost / available
+	}
+
+	var disposals []LotDisposal
+	consume := func(idx int, qty float64) {
+		lot := &lots[idx]
+		if qty > lot.Quantity {
+			qty = lot.Quantity
+		}
+		if qty <= lotQuantityEpsilon {
+			return
+		}
+
+		unitCost := lot.UnitCost
+		if method == models.CostBasisAverage {
+			unitCost = averageCost
+		}
+
+		costBasis := qty * unitCost
+		proceeds := qty * sell.Price
+		disposals = append(disposals, LotDisposal{
+			SellTransactionID: sell.TransactionID,
+			LotTransactionID:  lot.TransactionID,
+			Symbol:            sell.Symbol,
+			AcquiredAt:        lot.AcquiredAt,
+			SoldAt:            sell.TransactionDate,
+			Quantity:          qty,
+			CostBasis:         costBasis,
+			Proceeds:          proceeds,
+			GainLoss:          proceeds - costBasis,
+		})
+
+		lot.Quantity -= qty
+		remaining -= qty
+	}
+
+	// Specific lot identification relieves the selected lots first
+	if method == models.CostBasisSpecificLot {
+		for _, selection := range sell.LotSelections {
+			if remaining <= lotQuantityEpsilon {
+				break
+			}
+			lotID, err := uuid.Parse(selection.TransactionID)
+			if err != nil {
+				continue
+			}
+			for i := range lots {
+				if lots[i].TransactionID == lotID {
+					qty := selection.Quantity
+					if qty > remaining {
+						qty = remaining
+					}
+					consume(i, qty)
+					break
+				}
+			}
+		}
+	}
+
+	for _, idx := range lotReliefOrder(lots, method) {
+		if remaining <= lotQuantityEpsilon {
+			break
+		}
+		consume(idx, remaining)
+	}
+
+	if method == models.CostBasisAverage {
+		for i := range lots {
+			lots[i].UnitCost = averageCost
+		}
+	}
+
+	// Split off fully relieved lots
+	var closed []TaxLot
+	open := lots[:0]
+	for _, lot := range lots {
+		if lot.Quantity > lotQuantityEpsilon {
+			open = append(open, lot)
+			continue
+		}
+		lot.Quantity = 0
+		lot.ClosedAt = sell.TransactionDate
+		closed = append(closed, lot)
+	}
+
+	return open, closed, disposals
+}
+
+// lotReliefOrder returns the indices of lots in the order they should be relieved
+func lotReliefOrder(lots []TaxLot, method models.CostBasisMethod) []int {
+	order := make([]int, len(lots))
+	for i := range order {
+		order[i] = i
+	}
+
+	switch method {
+	case models.CostBasisLIFO:
+		sort.SliceStable(order, func(i, j int) bool {
+			return lots[order[i]].AcquiredAt.After(lots[order[j]].AcquiredAt)
+		})
+	case models.CostBasisHIFO:
+		sort.SliceStable(order, func(i, j int) bool {
+			return lots[order[i]].UnitCost > lots[order[j]].UnitCost
+		})
+	default:
+		// FIFO, average and the specific lot fallback relieve the oldest lots first
+		sort.SliceStable(order, func(i, j int) bool {
+			return lots[order[i]].AcquiredAt.Before(lots[order[j]].AcquiredAt)
+		})
+	}
+
+	return order
+}
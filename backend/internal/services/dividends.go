@@ -0,0 +1,101 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/types"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// CalculateDividendIncome returns the lifetime dividend income and the income received in
+// the twelve months up to asOf
+func CalculateDividendIncome(transactions []models.Transaction, asOf time.Time) (total, trailing12M float64) {
+	ttmStart := asOf.AddDate(-1, 0, 0)
+	for _, tx := range transactions {
+		if tx.TradeType != types.TradeTypeDividend || tx.TransactionDate.After(asOf) {
+			continue
+		}
+		total += tx.Amount
+		if tx.TransactionDate.After(ttmStart) {
+			trailing12M += tx.Amount
+		}
+	}
+	return total, trailing12M
+}
+
+// CalculateYieldOnCost returns trailing twelve month dividend income as a percentage of cost basis
+func CalculateYieldOnCost(trailing12M, totalCost float64) float64 {
+	if totalCost <= 0 {
+		return 0
+	}
+	return trailing12M / totalCost * 100
+}
+
+// BuildDividendReport aggregates dividend transactions by period and by symbol
+func BuildDividendReport(transactions []models.Transaction, groupBy models.DividendGrouping, asOf time.Time) *models.DividendIncomeReport {
+	if !groupBy.IsValid() {
+		groupBy = models.DividendGroupingMonth
+	}
+	layout := "2006-01"
+	if groupBy == models.DividendGroupingYear {
+		layout = "2006"
+	}
+
+	report := &models.DividendIncomeReport{
+		GroupBy:   groupBy,
+		Periods:   []models.DividendPeriodIncome{},
+		Symbols:   []models.SymbolDividendIncome{},
+		Timestamp: time.Now(),
+	}
+	report.TotalIncome, report.TTMDividendIncome = CalculateDividendIncome(transactions, asOf)
+
+	byPeriod := make(map[string]map[string]float64)
+	bySymbol := make(map[string]float64)
+	for _, tx := range transactions {
+		if tx.TradeType != types.TradeTypeDividend || tx.TransactionDate.After(asOf) {
+			continue
+		}
+		period := tx.TransactionDate.Format(layout)
+		if byPeriod[period] == nil {
+			byPeriod[period] = make(map[string]float64)
+		}
+		byPeriod[period][tx.Symbol] += tx.Amount
+		bySymbol[tx.Symbol] += tx.Amount
+	}
+
+	for period, symbols := range byPeriod {
+		income := models.DividendPeriodIncome{Period: period}
+		for symbol, amount := range symbols {
+			income.Total += amount
+			income.Symbols = append(income.Symbols, models.SymbolDividendIncome{Symbol: symbol, Amount: utils.RoundTo4(amount)})
+		}
+		income.Total = utils.RoundTo4(income.Total)
+		sortSymbolDividends(income.Symbols)
+		report.Periods = append(report.Periods, income)
+	}
+	sort.Slice(report.Periods, func(i, j int) bool {
+		return report.Periods[i].Period < report.Periods[j].Period
+	})
+
+	for symbol, amount := range bySymbol {
+		report.Symbols = append(report.Symbols, models.SymbolDividendIncome{Symbol: symbol, Amount: utils.RoundTo4(amount)})
+	}
+	sortSymbolDividends(report.Symbols)
+
+	report.TotalIncome = utils.RoundTo4(report.TotalIncome)
+	report.TTMDividendIncome = utils.RoundTo4(report.TTMDividendIncome)
+
+	return report
+}
+
+// sortSymbolDividends orders symbols by income, largest first
+func sortSymbolDividends(symbols []models.SymbolDividendIncome) {
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Amount != symbols[j].Amount {
+			return symbols[i].Amount > symbols[j].Amount
+		}
+		return symbols[i].Symbol < symbols[j].Symbol
+	})
+}
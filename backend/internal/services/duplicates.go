@@ -0,0 +1,81 @@
+package services
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/types"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// TransactionFingerprint identifies a transaction by what a statement shows of it: the user,
+// symbol, date, type, quantity, price and broker. Transactions without a quantity or price, such
+// as cash movements, income and fees, are told apart by their amount instead. A reinvested
+// dividend is stored as a Dividends and a Buy, so it is identified by its Buy. Numbers are
+// compared at the precision of their columns, so a new row matches the rounded copy stored before.
+func TransactionFingerprint(transaction models.Transaction) string {
+	tradeType := transaction.TradeType
+	if tradeType == types.TradeTypeDividendReinvestment {
+		tradeType = types.TradeTypeBuy
+	}
+	fields := []string{
+		transaction.UserID.String(),
+		strings.ToUpper(strings.TrimSpace(transaction.Symbol)),
+		transaction.TransactionDate.Format("2006-01-02"),
+		string(tradeType),
+		strconv.FormatFloat(utils.RoundTo4(transaction.Quantity), 'f', 4, 64),
+		strconv.FormatFloat(utils.RoundTo4(transaction.Price), 'f', 4, 64),
+		strings.ToLower(strings.TrimSpace(transaction.Broker)),
+	}
+	if utils.RoundTo4(transaction.Quantity) == 0 && utils.RoundTo4(transaction.Price) == 0 {
+		fields = append(fields, strconv.FormatFloat(math.Round(transaction.Amount*100)/100, 'f', 2, 64))
+	}
+	return strings.Join(fields, "|")
+}
+
+// MatchDuplicates returns, for each new transaction, the ID of an existing transaction with the
+// same fingerprint, or nil. Each existing transaction matches at most one new transaction, so two
+// identical fills in a statement only both match when both were recorded before.
+func MatchDuplicates(existing, transactions []models.Transaction) []*uuid.UUID {
+	candidates := make(map[string][]uuid.UUID)
+	for _, tx := range existing {
+		fingerprint := TransactionFingerprint(tx)
+		candidates[fingerprint] = append(candidates[fingerprint], tx.TransactionID)
+	}
+
+	matches := make([]*uuid.UUID, len(transactions))
+	for i, tx := range transactions {
+		fingerprint := TransactionFingerprint(tx)
+		if ids := candidates[fingerprint]; len(ids) > 0 {
+			id := ids[0]
+			matches[i] = &id
+			candidates[fingerprint] = ids[1:]
+		}
+	}
+	return matches
+}
+
+// findDuplicates matches transactions against the user's transactions recorded on the same days
+func (s *TransactionService) findDuplicates(userID uuid.UUID, transactions []models.Transaction) ([]*uuid.UUID, error) {
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+	start, end := transactions[0].TransactionDate, transactions[0].TransactionDate
+	for _, tx := range transactions[1:] {
+		if tx.TransactionDate.Before(start) {
+			start = tx.TransactionDate
+		}
+		if tx.TransactionDate.After(end) {
+			end = tx.TransactionDate
+		}
+	}
+
+	existing, err := s.transactionRepo.GetByUserIDAndDateRange(userID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	return MatchDuplicates(existing, transactions), nil
+}
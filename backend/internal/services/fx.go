@@ -0,0 +1,158 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// SymbolCurrency returns the currency a symbol trades in, taken from its most recent transaction
+func SymbolCurrency(transactions []models.Transaction) string {
+	var currency string
+	var latest time.Time
+	for _, tx := range transactions {
+		if tx.Currency == "" {
+			continue
+		}
+		if currency == "" || !tx.TransactionDate.Before(latest) {
+			currency = strings.ToUpper(tx.Currency)
+			latest = tx.TransactionDate
+		}
+	}
+	if currency == "" {
+		return models.DefaultBaseCurrency
+	}
+	return currency
+}
+
+// TransactionCurrencies returns the distinct currencies of the given transactions
+func TransactionCurrencies(transactions []models.Transaction) []string {
+	seen := make(map[string]bool)
+	var currencies []string
+	for _, tx := range transactions {
+		currency := strings.ToUpper(tx.Currency)
+		if currency == "" || seen[currency] {
+			continue
+		}
+		seen[currency] = true
+		currencies = append(currencies, currency)
+	}
+	return currencies
+}
+
+// ConvertTransactionAmounts returns copies of the transactions with amounts converted at the rate
+// rateOn gives for their currency on their transaction date. Transactions in a currency without a
+// rate are left unchanged.
+func ConvertTransactionAmounts(transactions []models.Transaction, rateOn func(currency string, date time.Time) (float64, bool)) []models.Transaction {
+	converted := make([]models.Transaction, len(transactions))
+	for i, tx := range transactions {
+		if rate, ok := rateOn(strings.ToUpper(tx.Currency), tx.TransactionDate); ok && rate > 0 {
+			tx.Price *= rate
+			tx.Amount *= rate
+		}
+		converted[i] = tx
+	}
+	return converted
+}
+
+// ConvertHolding restates a holding's monetary values from its native currency into the base
+// currency at the given rate. Return rates are ratios and stay as computed in the native currency.
+func ConvertHolding(holding models.SingleHolding, baseCurrency string, rate float64) models.SingleHolding {
+	holding.Currency = baseCurrency
+	holding.FXRate = rate
+	if rate == 1 {
+		return holding
+	}
+
+	holding.TotalCost = utils.RoundTo4(holding.TotalCost * rate)
+	holding.UnitCost = utils.RoundTo4(holding.UnitCost * rate)
+	holding.CurrentPrice = utils.RoundTo4(holding.CurrentPrice * rate)
+	holding.MarketValue = utils.RoundTo4(holding.MarketValue * rate)
+	holding.RealizedGainLoss = utils.RoundTo4(holding.RealizedGainLoss * rate)
+	holding.UnrealizedGainLoss = utils.RoundTo4(holding.UnrealizedGainLoss * rate)
+	holding.DividendIncome = utils.RoundTo4(holding.DividendIncome * rate)
+	holding.TTMDividendIncome = utils.RoundTo4(holding.TTMDividendIncome * rate)
+
+	return holding
+}
+
+// FXRateSeries holds the daily rates converting one currency into another, oldest first
+type FXRateSeries struct {
+	dates []string
+	rates []float64
+}
+
+// NewFXRateSeries builds a series from rate points in any order
+func NewFXRateSeries(points []provider.FXRatePoint) *FXRateSeries {
+	sorted := make([]provider.FXRatePoint, 0, len(points))
+	for _, point := range points {
+		if point.Rate > 0 {
+			sorted = append(sorted, point)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Date < sorted[j].Date
+	})
+
+	series := &FXRateSeries{
+		dates: make([]string, len(sorted)),
+		rates: make([]float64, len(sorted)),
+	}
+	for i, point := range sorted {
+		series.dates[i] = point.Date
+		series.rates[i] = point.Rate
+	}
+	return series
+}
+
+// RateOn returns the latest rate on or before the given date. FX markets close on weekends
+// and holidays, so the previous rate is carried forward.
+func (s *FXRateSeries) RateOn(date time.Time) (float64, bool) {
+	key := date.Format("2006-01-02")
+	idx := sort.SearchStrings(s.dates, key)
+	if idx < len(s.dates) && s.dates[idx] == key {
+		return s.rates[idx], true
+	}
+	if idx == 0 {
+		return 0, false
+	}
+	return s.rates[idx-1], true
+}
+
+// fxConverter converts symbol values into the base currency as of a valuation date
+type fxConverter struct {
+	baseCurrency    string
+	symbolCurrency  map[string]string
+	historicalRates map[string]*FXRateSeries
+	currentRates    map[string]float64
+}
+
+// rateFor returns the rate converting a symbol's currency into the base currency on a date,
+// falling back to the current rate when no historical rate is known
+func (c *fxConverter) rateFor(symbol string, date time.Time) (float64, bool) {
+	currency, ok := c.symbolCurrency[symbol]
+	if !ok {
+		return 1, true
+	}
+	return c.rateForCurrency(currency, date)
+}
+
+// rateForCurrency returns the rate converting a currency into the base currency on a date
+func (c *fxConverter) rateForCurrency(currency string, date time.Time) (float64, bool) {
+	if currency == c.baseCurrency {
+		return 1, true
+	}
+	if series, ok := c.historicalRates[currency]; ok {
+		if rate, found := series.RateOn(date); found {
+			return rate, true
+		}
+	}
+	if rate, ok := c.currentRates[currency]; ok && rate > 0 {
+		return rate, true
+	}
+	return 0, false
+}
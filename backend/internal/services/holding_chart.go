@@ -0,0 +1,92 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// BuildHoldingChart values a symbol's position at each time point from a valuer over its
+// transactions. Cost basis is that of the open lots under the given method. Data points are
+// converted into the base currency at the rate of their time, and events at the rate of their day.
+// Transactions dated between the first and last time points become events pointing at the first
+// data point that reflects them.
+func BuildHoldingChart(symbol string, transactions []models.Transaction, valuer *PortfolioValuer, method models.CostBasisMethod, timePoints []time.Time) ([]models.HoldingChartDataPoint, []models.HoldingChartEvent) {
+	var ordered []models.Transaction
+	for _, tx := range transactions {
+		if tx.Symbol == symbol && !tx.TradeType.IsCashMovement() {
+			ordered = append(ordered, tx)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].TransactionDate.Before(ordered[j].TransactionDate)
+	})
+
+	dataPoints := make([]models.HoldingChartDataPoint, 0, len(timePoints))
+	events := []models.HoldingChartEvent{}
+
+	var cost float64
+	next := 0
+	for _, timePoint := range timePoints {
+		// Lots only need replaying when a transaction was added since the previous point
+		added := false
+		for ; next < len(ordered) && !ordered[next].TransactionDate.After(timePoint); next++ {
+			added = true
+		}
+		if added {
+			cost = CalculateCostBasis(ordered[:next], method).TotalCost
+		}
+
+		quantity, price := valuer.Position(symbol, timePoint)
+		if quantity <= lotQuantityEpsilon {
+			quantity = 0
+		}
+		// Without a rate the position cannot be valued, as in the portfolio chart
+		rate, ok := valuer.symbolRate(symbol, timePoint)
+		if !ok {
+			rate = 0
+		}
+		marketValue := quantity * price * rate
+		costBasis := cost * rate
+
+		dataPoints = append(dataPoints, models.HoldingChartDataPoint{
+			Timestamp:          timePoint,
+			Quantity:           utils.RoundTo4(quantity),
+			Price:              utils.RoundTo4(price * rate),
+			MarketValue:        utils.RoundTo4(marketValue),
+			CostBasis:          utils.RoundTo4(costBasis),
+			UnrealizedGainLoss: utils.RoundTo4(marketValue - costBasis),
+		})
+	}
+
+	if len(timePoints) == 0 {
+		return dataPoints, events
+	}
+	first, last := timePoints[0], timePoints[len(timePoints)-1]
+	index := 0
+	for _, tx := range ordered {
+		if tx.TransactionDate.Before(first) || tx.TransactionDate.After(last) {
+			continue
+		}
+		for timePoints[index].Before(tx.TransactionDate) {
+			index++
+		}
+		rate, ok := valuer.symbolRate(symbol, tx.TransactionDate)
+		if !ok {
+			rate = 0
+		}
+		events = append(events, models.HoldingChartEvent{
+			TransactionID:  tx.TransactionID,
+			Timestamp:      tx.TransactionDate,
+			TradeType:      tx.TradeType,
+			Quantity:       utils.RoundTo4(tx.Quantity),
+			Price:          utils.RoundTo4(tx.Price * rate),
+			Amount:         utils.RoundTo4(tx.Amount * rate),
+			DataPointIndex: index,
+		})
+	}
+
+	return dataPoints, events
+}
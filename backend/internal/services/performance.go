@@ -0,0 +1,89 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+// ExternalCashFlow is money moved into (positive) or out of (negative) the portfolio
+type ExternalCashFlow struct {
+	Date     time.Time
+	Currency string
+	Amount   float64
+}
+
+// ExternalCashFlows returns the flows that cross the portfolio boundary, oldest first. At brokers
+// with tracked cash only deposits and withdrawals cross it; elsewhere purchases bring money in
+// and sale proceeds and dividends take it out.
+func ExternalCashFlows(transactions []models.Transaction) []ExternalCashFlow {
+	tracked := cashTrackedBrokers(transactions)
+
+	var flows []ExternalCashFlow
+	for _, tx := range transactions {
+		var amount float64
+		if tracked[tx.Broker] {
+			switch tx.TradeType {
+			case types.TradeTypeDeposit:
+				amount = tx.Amount
+			case types.TradeTypeWithdrawal:
+				amount = -tx.Amount
+			}
+		} else {
+			switch tx.TradeType {
+			case types.TradeTypeBuy:
+				amount = tx.Amount
+			case types.TradeTypeSell, types.TradeTypeDividend:
+				amount = -tx.Amount
+			}
+		}
+		if amount == 0 {
+			continue
+		}
+		flows = append(flows, ExternalCashFlow{
+			Date:     tx.TransactionDate,
+			Currency: strings.ToUpper(tx.Currency),
+			Amount:   amount,
+		})
+	}
+
+	sort.SliceStable(flows, func(i, j int) bool {
+		return flows[i].Date.Before(flows[j].Date)
+	})
+
+	return flows
+}
+
+// sumCashFlows returns the net of the flows dated after one time and up to another
+func sumCashFlows(flows []ExternalCashFlow, after, upTo time.Time) float64 {
+	var total float64
+	for _, flow := range flows {
+		if flow.Date.After(after) && !flow.Date.After(upTo) {
+			total += flow.Amount
+		}
+	}
+	return total
+}
+
+// cashFlowCheckpoints returns the end of each day with a flow strictly between two times. Valuing
+// the portfolio at these points lets the time-weighted return exclude the flows from performance.
+func cashFlowCheckpoints(flows []ExternalCashFlow, after, before time.Time) []time.Time {
+	var checkpoints []time.Time
+	for _, flow := range flows {
+		if !flow.Date.After(after) {
+			continue
+		}
+		year, month, day := flow.Date.Date()
+		endOfDay := time.Date(year, month, day, 23, 59, 59, 0, flow.Date.Location())
+		if !endOfDay.Before(before) {
+			break
+		}
+		if len(checkpoints) == 0 || !checkpoints[len(checkpoints)-1].Equal(endOfDay) {
+			checkpoints = append(checkpoints, endOfDay)
+		}
+	}
+	return checkpoints
+}
@@ -4,36 +4,115 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/logger"
 	"github.com/transaction-tracker/backend/internal/models"
 	"github.com/transaction-tracker/backend/internal/provider"
 	"github.com/transaction-tracker/backend/internal/repositories"
+	"github.com/transaction-tracker/backend/internal/types"
 	"github.com/transaction-tracker/backend/internal/utils"
 )
 
+// PortfolioOptions represents per-request options for portfolio calculations
+type PortfolioOptions struct {
+	// CostBasisMethod overrides the user's default cost basis method when set
+	CostBasisMethod models.CostBasisMethod
+	// AccountID limits calculations to the transactions of one account when set
+	AccountID *uuid.UUID
+	// Benchmark adds a comparison against this symbol to the historical chart when set
+	Benchmark string
+}
+
+// maxChartDataPoints caps the number of data points a historical chart returns
+const maxChartDataPoints = 1000
+
+// ChartRange selects the period and spacing of the historical chart. From replaces the timeframe
+// when set, and an empty granularity is chosen from the length of the period.
+type ChartRange struct {
+	TimeFrame   models.TimeFrame
+	From        *time.Time
+	To          *time.Time // defaults to now, and never goes past it
+	Granularity models.Granularity
+}
+
 // PortfolioService handles portfolio-related business logic
 type PortfolioService struct {
 	transactionRepo *repositories.TransactionRepository
+	userRepo        repositories.UserRepository
+	accountRepo     *repositories.AccountRepository
+	snapshotRepo    *repositories.PortfolioSnapshotRepository
 	priceManager    *provider.PriceServiceManager
 }
 
 // NewPortfolioService creates a new portfolio service
 func NewPortfolioService(
 	transactionRepo *repositories.TransactionRepository,
+	userRepo repositories.UserRepository,
+	accountRepo *repositories.AccountRepository,
+	snapshotRepo *repositories.PortfolioSnapshotRepository,
 	priceManager *provider.PriceServiceManager,
 ) *PortfolioService {
 	return &PortfolioService{
 		transactionRepo: transactionRepo,
+		userRepo:        userRepo,
+		accountRepo:     accountRepo,
+		snapshotRepo:    snapshotRepo,
 		priceManager:    priceManager,
 	}
 }
 
+// resolveCostBasisMethod picks the request's method, falling back to the user's default
+func (s *PortfolioService) resolveCostBasisMethod(userID uuid.UUID, opts PortfolioOptions) models.CostBasisMethod {
+	return resolveCostBasisMethod(s.userRepo, userID, opts.CostBasisMethod)
+}
+
+// getTransactions returns the user's transactions, limited to one account when the options name it
+func (s *PortfolioService) getTransactions(userID uuid.UUID, opts PortfolioOptions) ([]models.Transaction, error) {
+	if opts.AccountID == nil {
+		return s.transactionRepo.GetByUserID(userID)
+	}
+	if err := s.checkAccount(userID, *opts.AccountID); err != nil {
+		return nil, err
+	}
+	return s.transactionRepo.GetByUserIDAndAccountID(userID, *opts.AccountID)
+}
+
+// getSymbolTransactions returns the user's transactions of a symbol, limited to one account when the options name it
+func (s *PortfolioService) getSymbolTransactions(userID uuid.UUID, symbol string, opts PortfolioOptions) ([]models.Transaction, error) {
+	if opts.AccountID == nil {
+		return s.transactionRepo.GetByUserIDAndSymbol(userID, symbol)
+	}
+	if err := s.checkAccount(userID, *opts.AccountID); err != nil {
+		return nil, err
+	}
+	return s.transactionRepo.GetByUserIDAccountIDAndSymbol(userID, *opts.AccountID, symbol)
+}
+
+// checkAccount verifies that an account exists and belongs to the user
+func (s *PortfolioService) checkAccount(userID, accountID uuid.UUID) error {
+	_, err := checkAccount(s.accountRepo, userID, accountID)
+	return err
+}
+
+// resolveBaseCurrency returns the currency the user's portfolio values are reported in
+func (s *PortfolioService) resolveBaseCurrency(userID uuid.UUID) string {
+	if s.userRepo != nil {
+		user, err := s.userRepo.FindByUserID(userID)
+		if err == nil && user.BaseCurrency != "" {
+			return user.BaseCurrency
+		}
+	}
+	return models.DefaultBaseCurrency
+}
+
 // GetSingleHoldingBasicInfo retrieves basic information for a specific stock holding
-func (s *PortfolioService) GetSingleHoldingBasicInfo(ctx context.Context, userID uuid.UUID, symbol string) (*models.SingleHolding, error) {
+func (s *PortfolioService) GetSingleHoldingBasicInfo(ctx context.Context, userID uuid.UUID, symbol string, opts PortfolioOptions) (*models.SingleHolding, error) {
 	// Get all transactions for this user and symbol
-	transactions, err := s.transactionRepo.GetByUserIDAndSymbol(userID, symbol)
+	transactions, err := s.getSymbolTransactions(userID, symbol, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions for symbol %s: %w", symbol, err)
 	}
@@ -43,7 +122,8 @@ func (s *PortfolioService) GetSingleHoldingBasicInfo(ctx context.Context, userID
 	}
 
 	// Calculate basic metrics from transactions
-	totalQuantity, totalCost, unitCost, realizedGainLoss := s.calculateHoldingMetrics(transactions)
+	method := s.resolveCostBasisMethod(userID, opts)
+	totalQuantity, totalCost, unitCost, realizedGainLoss := s.calculateHoldingMetrics(transactions, method)
 
 	// Check if user still holds this stock
 	if totalQuantity <= 0 {
@@ -59,13 +139,23 @@ func (s *PortfolioService) GetSingleHoldingBasicInfo(ctx context.Context, userID
 	currentPrice := currentPriceData.CurrentPrice
 	marketValue := totalQuantity * currentPrice
 	unrealizedGainLoss := marketValue - totalCost
+	dividendIncome, ttmDividendIncome := CalculateDividendIncome(transactions, time.Now())
 
 	// Calculate return rates
-	totalReturnRate := s.calculateTotalReturnRate(realizedGainLoss, unrealizedGainLoss, totalCost)
+	totalReturnRate := s.calculateTotalReturnRate(realizedGainLoss, unrealizedGainLoss, dividendIncome, totalCost)
 	annualizedReturnRate := s.calculateAnnualizedReturnRate(transactions, totalCost, marketValue)
 
-	return &models.SingleHolding{
+	// Convert into the user's base currency at the current rate
+	baseCurrency := s.resolveBaseCurrency(userID)
+	currency := SymbolCurrency(transactions)
+	fxRates, err := s.priceManager.GetCurrentFXRates(ctx, baseCurrency, []string{currency})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get FX rate for %s: %w", symbol, err)
+	}
+
+	holding := ConvertHolding(models.SingleHolding{
 		Symbol:               symbol,
+		NativeCurrency:       currency,
 		TotalQuantity:        utils.RoundTo4(totalQuantity),
 		TotalCost:            utils.RoundTo4(totalCost),
 		UnitCost:             utils.RoundTo4(unitCost),
@@ -75,76 +165,151 @@ func (s *PortfolioService) GetSingleHoldingBasicInfo(ctx context.Context, userID
 		AnnualizedReturnRate: utils.RoundTo4(annualizedReturnRate),
 		RealizedGainLoss:     utils.RoundTo4(realizedGainLoss),
 		UnrealizedGainLoss:   utils.RoundTo4(unrealizedGainLoss),
-	}, nil
+		DividendIncome:       utils.RoundTo4(dividendIncome),
+		TTMDividendIncome:    utils.RoundTo4(ttmDividendIncome),
+		YieldOnCost:          utils.RoundTo4(CalculateYieldOnCost(ttmDividendIncome, totalCost)),
+		CostBasisMethod:      method,
+	}, baseCurrency, fxRates[currency])
+
+	return &holding, nil
 }
 
-// GetAllHoldings retrieves basic information for all current holdings of a user
-func (s *PortfolioService) GetAllHoldings(ctx context.Context, userID uuid.UUID) ([]models.SingleHolding, error) {
+// GetAllHoldings retrieves basic information for all current holdings of a user. Every price is
+// fetched in one batch; holdings that cannot be valued are returned as price failures instead.
+func (s *PortfolioService) GetAllHoldings(ctx context.Context, userID uuid.UUID, opts PortfolioOptions) ([]models.SingleHolding, []models.PriceFailure, error) {
 	// Get all transactions for this user
-	transactions, err := s.transactionRepo.GetByUserID(userID)
+	transactions, err := s.getTransactions(userID, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get transactions for user: %w", err)
+		return nil, nil, fmt.Errorf("failed to get transactions for user: %w", err)
 	}
 
 	if len(transactions) == 0 {
-		return []models.SingleHolding{}, nil
+		return []models.SingleHolding{}, []models.PriceFailure{}, nil
 	}
 
+	method := s.resolveCostBasisMethod(userID, opts)
+
 	// Group transactions by symbol
 	transactionsBySymbol := make(map[string][]models.Transaction)
 	for _, tx := range transactions {
 		transactionsBySymbol[tx.Symbol] = append(transactionsBySymbol[tx.Symbol], tx)
 	}
 
-	var holdings []models.SingleHolding
+	// Only symbols still held need a price
+	type openPosition struct {
+		symbol                                           string
+		totalQuantity, totalCost, unitCost, realizedGain float64
+	}
+	var positions []openPosition
+	var symbols []string
 	for symbol, symbolTransactions := range transactionsBySymbol {
-		// Calculate basic metrics for this symbol
-		totalQuantity, totalCost, unitCost, realizedGainLoss := s.calculateHoldingMetrics(symbolTransactions)
-
-		// Skip if user no longer holds this stock
+		totalQuantity, totalCost, unitCost, realizedGainLoss := s.calculateHoldingMetrics(symbolTransactions, method)
 		if totalQuantity <= 0 {
 			continue
 		}
+		positions = append(positions, openPosition{symbol, totalQuantity, totalCost, unitCost, realizedGainLoss})
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].symbol < positions[j].symbol
+	})
 
-		// Get current price from PriceServiceManager
-		currentPriceData, err := s.priceManager.GetCurrentPrice(ctx, symbol)
-		if err != nil {
-			// Log error but continue with other holdings
-			fmt.Printf("Warning: failed to get current price for %s: %v\n", symbol, err)
+	holdings := []models.SingleHolding{}
+	failures := []models.PriceFailure{}
+	if len(positions) == 0 {
+		return holdings, failures, nil
+	}
+
+	// Fetch the current rate of every currency held into the user's base currency
+	baseCurrency := s.resolveBaseCurrency(userID)
+	symbolCurrencies := make(map[string]string, len(positions))
+	var currencies []string
+	for _, position := range positions {
+		symbolCurrencies[position.symbol] = SymbolCurrency(transactionsBySymbol[position.symbol])
+		currencies = append(currencies, symbolCurrencies[position.symbol])
+	}
+	fxRates, err := s.priceManager.GetCurrentFXRates(ctx, baseCurrency, currencies)
+	if err != nil {
+		// Holdings in the base currency can still be valued
+		logger.Warn("Failed to get FX rates for holdings", logger.H{"base_currency": baseCurrency, "error": err})
+		fxRates = map[string]float64{baseCurrency: 1}
+	}
+
+	prices, priceErrors := s.priceManager.GetCurrentPriceBatch(ctx, symbols)
+
+	for _, position := range positions {
+		symbol := position.symbol
+		symbolTransactions := transactionsBySymbol[symbol]
+
+		currency := symbolCurrencies[symbol]
+		fxRate, ok := fxRates[currency]
+		if !ok {
+			logger.Warn("Skipping holding without an FX rate", logger.H{"symbol": symbol, "currency": currency, "base_currency": baseCurrency})
+			failures = append(failures, models.PriceFailure{
+				Symbol:   symbol,
+				Quantity: utils.RoundTo4(position.totalQuantity),
+				Reason:   fmt.Sprintf("no FX rate from %s to %s", currency, baseCurrency),
+			})
+			continue
+		}
+
+		currentPriceData, ok := prices[strings.ToUpper(symbol)]
+		if !ok {
+			reason := fmt.Sprintf("no price data returned for symbol %s", symbol)
+			if priceErr := priceErrors[strings.ToUpper(symbol)]; priceErr != nil {
+				reason = priceErr.Error()
+			}
+			logger.Warn("Skipping holding without a current price", logger.H{"symbol": symbol, "reason": reason})
+			failures = append(failures, models.PriceFailure{
+				Symbol:   symbol,
+				Quantity: utils.RoundTo4(position.totalQuantity),
+				Reason:   reason,
+			})
 			continue
 		}
 
+		totalQuantity, totalCost := position.totalQuantity, position.totalCost
 		currentPrice := currentPriceData.CurrentPrice
 		marketValue := totalQuantity * currentPrice
 		unrealizedGainLoss := marketValue - totalCost
+		dividendIncome, ttmDividendIncome := CalculateDividendIncome(symbolTransactions, time.Now())
 
 		// Calculate return rates
-		totalReturnRate := s.calculateTotalReturnRate(realizedGainLoss, unrealizedGainLoss, totalCost)
+		totalReturnRate := s.calculateTotalReturnRate(position.realizedGain, unrealizedGainLoss, dividendIncome, totalCost)
 		annualizedReturnRate := s.calculateAnnualizedReturnRate(symbolTransactions, totalCost, marketValue)
 
-		holding := models.SingleHolding{
+		holding := ConvertHolding(models.SingleHolding{
 			Symbol:               symbol,
+			NativeCurrency:       currency,
 			TotalQuantity:        utils.RoundTo4(totalQuantity),
 			TotalCost:            utils.RoundTo4(totalCost),
-			UnitCost:             utils.RoundTo4(unitCost),
+			UnitCost:             utils.RoundTo4(position.unitCost),
 			CurrentPrice:         utils.RoundTo4(currentPrice),
 			MarketValue:          utils.RoundTo4(marketValue),
 			TotalReturnRate:      utils.RoundTo4(totalReturnRate),
 			AnnualizedReturnRate: utils.RoundTo4(annualizedReturnRate),
-			RealizedGainLoss:     utils.RoundTo4(realizedGainLoss),
+			RealizedGainLoss:     utils.RoundTo4(position.realizedGain),
 			UnrealizedGainLoss:   utils.RoundTo4(unrealizedGainLoss),
-		}
+			DividendIncome:       utils.RoundTo4(dividendIncome),
+			TTMDividendIncome:    utils.RoundTo4(ttmDividendIncome),
+			YieldOnCost:          utils.RoundTo4(CalculateYieldOnCost(ttmDividendIncome, totalCost)),
+			CostBasisMethod:      method,
+		}, baseCurrency, fxRate)
 
 		holdings = append(holdings, holding)
 	}
 
-	return holdings, nil
+	return holdings, failures, nil
 }
 
 // GetPortfolioSummary retrieves comprehensive portfolio summary for a user
-func (s *PortfolioService) GetPortfolioSummary(ctx context.Context, userID uuid.UUID) (*models.PortfolioSummary, error) {
+func (s *PortfolioService) GetPortfolioSummary(ctx context.Context, userID uuid.UUID, opts PortfolioOptions) (*models.PortfolioSummary, error) {
+	method := s.resolveCostBasisMethod(userID, opts)
+	baseCurrency := s.resolveBaseCurrency(userID)
+
 	// Get all current holdings
-	holdings, err := s.GetAllHoldings(ctx, userID)
+	opts.CostBasisMethod = method
+	holdings, priceFailures, err := s.GetAllHoldings(ctx, userID, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get holdings for portfolio summary: %w", err)
 	}
@@ -156,12 +321,19 @@ func (s *PortfolioService) GetPortfolioSummary(ctx context.Context, userID uuid.
 	holdingsCount := len(holdings)
 
 	// Check if user has any transactions (not just current holdings)
-	allTransactions, err := s.transactionRepo.GetByUserID(userID)
+	allTransactions, err := s.getTransactions(userID, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all transactions for portfolio summary: %w", err)
 	}
 	hasTransactions := len(allTransactions) > 0
 
+	// Rates converting each transaction currency into the base currency, for cash and cash flows
+	fxRates, err := s.priceManager.GetCurrentFXRates(ctx, baseCurrency, TransactionCurrencies(allTransactions))
+	if err != nil {
+		logger.Warn("Failed to get FX rates for portfolio cash", logger.H{"base_currency": baseCurrency, "error": err})
+		fxRates = map[string]float64{baseCurrency: 1}
+	}
+
 	for _, holding := range holdings {
 		totalMarketValue += holding.MarketValue
 		totalCost += holding.TotalCost
@@ -169,8 +341,18 @@ func (s *PortfolioService) GetPortfolioSummary(ctx context.Context, userID uuid.
 		totalUnrealizedGainLoss += holding.UnrealizedGainLoss
 	}
 
-	// Calculate total return and percentage
-	totalReturn := totalUnrealizedGainLoss + totalRealizedGainLoss
+	// Cash flows in the base currency as of their date. Dividends count as income whether or not
+	// the symbol is still held.
+	var convertedTransactions []models.Transaction
+	var totalDividendIncome, totalTTMDividendIncome float64
+	if hasTransactions {
+		converter := s.newFXConverter(ctx, baseCurrency, allTransactions, allTransactions[0].TransactionDate, now)
+		convertedTransactions = ConvertTransactionAmounts(allTransactions, converter.rateForCurrency)
+		totalDividendIncome, totalTTMDividendIncome = CalculateDividendIncome(convertedTransactions, now)
+	}
+
+	// Calculate total return and percentage, counting dividends as income
+	totalReturn := totalUnrealizedGainLoss + totalRealizedGainLoss + totalDividendIncome
 	var totalReturnPercentage float64
 	if totalCost > 0 {
 		totalReturnPercentage = (totalReturn / totalCost) * 100
@@ -179,14 +361,22 @@ func (s *PortfolioService) GetPortfolioSummary(ctx context.Context, userID uuid.
 	// Calculate annualized return rate (XIRR) for the whole portfolio
 	var annualizedReturnRate float64
 	if hasTransactions && totalCost > 0 && totalMarketValue > 0 {
-		// Gather all transactions for XIRR calculation
-		allTxs := append([]models.Transaction{}, allTransactions...)
-		annualizedReturnRate = s.calculateAnnualizedReturnRate(allTxs, totalCost, totalMarketValue)
+		annualizedReturnRate = s.calculateAnnualizedReturnRate(convertedTransactions, totalCost, totalMarketValue)
+	}
+
+	// Uninvested cash counts towards market value but not towards invested cost or returns
+	cashBalances, totalCashBalance := convertCashBalances(CalculateCashBalances(allTransactions, now), fxRates)
+	totalMarketValue += totalCashBalance
+
+	// Time-weighted return chains the returns between external flows, so it ignores their timing
+	var twr, annualizedTWR float64
+	if hasTransactions && totalMarketValue > 0 {
+		twr, annualizedTWR = s.calculateSinceInceptionTWR(ctx, userID, allTransactions, baseCurrency, totalMarketValue, now, opts)
 	}
 
 	return &models.PortfolioSummary{
 		Timestamp:             now,
-		Currency:              "USD", // Default currency as per requirements
+		Currency:              baseCurrency,
 		MarketValue:           utils.RoundTo4(totalMarketValue),
 		TotalCost:             utils.RoundTo4(totalCost),
 		TotalReturn:           utils.RoundTo4(totalReturn),
@@ -194,57 +384,171 @@ func (s *PortfolioService) GetPortfolioSummary(ctx context.Context, userID uuid.
 		HoldingsCount:         holdingsCount,
 		HasTransactions:       hasTransactions,
 		AnnualizedReturnRate:  utils.RoundTo4(annualizedReturnRate),
+		TWR:                   utils.RoundTo4(twr * 100),
+		AnnualizedTWR:         utils.RoundTo4(annualizedTWR * 100),
+		DividendIncome:        utils.RoundTo4(totalDividendIncome),
+		TTMDividendIncome:     utils.RoundTo4(totalTTMDividendIncome),
+		CashBalance:           utils.RoundTo4(totalCashBalance),
+		CashBalances:          cashBalances,
+		PriceFailures:         priceFailures,
+		CostBasisMethod:       method,
 		LastUpdated:           now,
 	}, nil
 }
 
-// calculateHoldingMetrics calculates total quantity, cost, unit cost, and realized gains/losses using weighted average method
-func (s *PortfolioService) calculateHoldingMetrics(transactions []models.Transaction) (totalQuantity, totalCost, unitCost, realizedGainLoss float64) {
-	// Initialize variables for weighted average calculation
-	var currentTotalQty, currentTotalCost float64
+// GetCashBalance returns the uninvested cash of the portfolio in the user's base currency
+func (s *PortfolioService) GetCashBalance(ctx context.Context, userID uuid.UUID, opts PortfolioOptions) (float64, error) {
+	transactions, err := s.getTransactions(userID, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get transactions for cash balance: %w", err)
+	}
 
-	for _, tx := range transactions {
-		qtyNum := tx.Quantity
-		priceNum := tx.Price // Using price per share instead of total amount
-
-		switch tx.TradeType {
-		case "Buy":
-			// Add to inventory
-			currentTotalQty += qtyNum
-			currentTotalCost += qtyNum * priceNum
-		case "Sell":
-			if currentTotalQty > 0 {
-				// Calculate current weighted average cost
-				avgCost := currentTotalCost / currentTotalQty
-
-				// Calculate realized gain/loss for this sale
-				sellQty := qtyNum
-				realizedGainLoss += sellQty * (priceNum - avgCost)
-
-				// Update remaining inventory
-				currentTotalCost -= sellQty * avgCost
-				currentTotalQty -= sellQty
+	baseCurrency := s.resolveBaseCurrency(userID)
+	fxRates, err := s.priceManager.GetCurrentFXRates(ctx, baseCurrency, TransactionCurrencies(transactions))
+	if err != nil {
+		logger.Warn("Failed to get FX rates for portfolio cash", logger.H{"base_currency": baseCurrency, "error": err})
+		fxRates = map[string]float64{baseCurrency: 1}
+	}
+
+	_, total := convertCashBalances(CalculateCashBalances(transactions, time.Now().UTC()), fxRates)
+	return total, nil
+}
+
+// convertCashBalances fills in the base currency balance of each cash balance and returns their total,
+// skipping balances without an FX rate
+func convertCashBalances(cashBalances []models.CashBalance, fxRates map[string]float64) ([]models.CashBalance, float64) {
+	var total float64
+	for i := range cashBalances {
+		rate, ok := fxRates[cashBalances[i].Currency]
+		if !ok {
+			logger.Warn("Skipping cash balance without an FX rate", logger.H{"broker": cashBalances[i].Broker, "currency": cashBalances[i].Currency})
+			continue
+		}
+		cashBalances[i].BaseBalance = utils.RoundTo4(cashBalances[i].Balance * rate)
+		cashBalances[i].Balance = utils.RoundTo4(cashBalances[i].Balance)
+		total += cashBalances[i].BaseBalance
+	}
+	return cashBalances, total
+}
+
+// GetAllocation returns the weights of the current holdings grouped by sector, asset class, country,
+// broker or currency. Holdings held at several brokers are split by their share count at each.
+func (s *PortfolioService) GetAllocation(ctx context.Context, userID uuid.UUID, groupBy models.AllocationGrouping, opts PortfolioOptions) (*models.AllocationReport, error) {
+	holdings, _, err := s.GetAllHoldings(ctx, userID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holdings for allocation: %w", err)
+	}
+	baseCurrency := s.resolveBaseCurrency(userID)
+
+	var exposures []AllocationExposure
+	switch groupBy {
+	case models.AllocationGroupingCurrency:
+		for _, holding := range holdings {
+			exposures = append(exposures, AllocationExposure{Key: holding.NativeCurrency, Symbol: holding.Symbol, MarketValue: holding.MarketValue})
+		}
+	case models.AllocationGroupingBroker:
+		transactions, err := s.getTransactions(userID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transactions for allocation: %w", err)
+		}
+		positions := BrokerPositions(transactions)
+		for _, holding := range holdings {
+			var totalQuantity float64
+			for _, quantity := range positions[holding.Symbol] {
+				if quantity > 0 {
+					totalQuantity += quantity
+				}
+			}
+			if totalQuantity <= 0 {
+				exposures = append(exposures, AllocationExposure{Symbol: holding.Symbol, MarketValue: holding.MarketValue})
+				continue
+			}
+			for broker, quantity := range positions[holding.Symbol] {
+				if quantity > 0 {
+					exposures = append(exposures, AllocationExposure{Key: broker, Symbol: holding.Symbol, MarketValue: holding.MarketValue * quantity / totalQuantity})
+				}
 			}
 		}
+	default:
+		symbols := make([]string, 0, len(holdings))
+		for _, holding := range holdings {
+			symbols = append(symbols, holding.Symbol)
+		}
+		metadata, err := s.priceManager.GetSecurityMetadata(ctx, symbols)
+		if err != nil {
+			// Holdings are still reported, grouped as unknown
+			logger.Warn("Failed to get security metadata for allocation", logger.H{"error": err})
+			metadata = map[string]provider.SecurityMetadata{}
+		}
+		for _, holding := range holdings {
+			exposures = append(exposures, AllocationExposure{Key: metadataAllocationKey(metadata[holding.Symbol], groupBy), Symbol: holding.Symbol, MarketValue: holding.MarketValue})
+		}
 	}
 
-	// Final results
-	totalQuantity = currentTotalQty
-	totalCost = currentTotalCost
+	return BuildAllocationReport(groupBy, baseCurrency, exposures, time.Now().UTC()), nil
+}
+
+// GetRealizedGains returns the gains realized during a tax year, split into short and long term
+func (s *PortfolioService) GetRealizedGains(ctx context.Context, userID uuid.UUID, year int, opts PortfolioOptions) (*models.RealizedGainsReport, error) {
+	transactions, err := s.getTransactions(userID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions for user: %w", err)
+	}
+
+	method := s.resolveCostBasisMethod(userID, opts)
+
+	// Lots must be replayed from the first acquisition, not just within the year
+	transactionsBySymbol := make(map[string][]models.Transaction)
+	for _, tx := range transactions {
+		transactionsBySymbol[tx.Symbol] = append(transactionsBySymbol[tx.Symbol], tx)
+	}
 
-	if totalQuantity > 0 {
-		unitCost = totalCost / totalQuantity
+	var disposals []LotDisposal
+	for _, symbolTransactions := range transactionsBySymbol {
+		disposals = append(disposals, CalculateCostBasis(symbolTransactions, method).Disposals...)
 	}
 
-	return totalQuantity, totalCost, unitCost, realizedGainLoss
+	return BuildRealizedGainsReport(year, method, disposals), nil
 }
 
-// calculateTotalReturnRate calculates total return rate percentage
-func (s *PortfolioService) calculateTotalReturnRate(realizedGainLoss, unrealizedGainLoss, totalCost float64) float64 {
+// GetWashSaleFlags detects wash sales across the given symbols and returns the flags of every
+// transaction involved, keyed by transaction ID
+func (s *PortfolioService) GetWashSaleFlags(userID uuid.UUID, symbols []string) (map[uuid.UUID]types.WashSaleFlag, error) {
+	method := s.resolveCostBasisMethod(userID, PortfolioOptions{})
+
+	flags := make(map[uuid.UUID]types.WashSaleFlag)
+	seen := make(map[string]bool)
+	for _, symbol := range symbols {
+		if seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+
+		transactions, err := s.transactionRepo.GetByUserIDAndSymbol(userID, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transactions for symbol %s: %w", symbol, err)
+		}
+
+		for id, flag := range BuildWashSaleFlags(CalculateCostBasis(transactions, method).WashSales) {
+			flags[id] = flag
+		}
+	}
+
+	return flags, nil
+}
+
+// calculateHoldingMetrics calculates total quantity, cost, unit cost, and realized gains/losses using the given cost basis method
+func (s *PortfolioService) calculateHoldingMetrics(transactions []models.Transaction, method models.CostBasisMethod) (totalQuantity, totalCost, unitCost, realizedGainLoss float64) {
+	result := CalculateCostBasis(transactions, method)
+	return result.TotalQuantity, result.TotalCost, result.UnitCost(), result.RealizedGainLoss
+}
+
+// calculateTotalReturnRate calculates total return rate percentage, counting dividends as income
+func (s *PortfolioService) calculateTotalReturnRate(realizedGainLoss, unrealizedGainLoss, dividendIncome, totalCost float64) float64 {
 	if totalCost <= 0 {
 		return 0
 	}
-	return ((realizedGainLoss + unrealizedGainLoss) / totalCost) * 100
+	return ((realizedGainLoss + unrealizedGainLoss + dividendIncome) / totalCost) * 100
 }
 
 // calculateAnnualizedReturnRate calculates XIRR (Internal Rate of Return) based on cash flows
@@ -260,9 +564,9 @@ func (s *PortfolioService) calculateAnnualizedReturnRate(transactions []models.T
 	}
 
 	for _, tx := range transactions {
-		amount := tx.Amount
-		if tx.TradeType == "Buy" {
-			amount = -amount
+		amount, ok := xirrCashFlow(tx)
+		if !ok {
+			continue
 		}
 		cashFlows = append(cashFlows, struct {
 			Amount float64
@@ -298,40 +602,119 @@ func (s *PortfolioService) calculateAnnualizedReturnRate(transactions []models.T
 		if holdingPeriodYears <= 0 {
 			return 0
 		}
-		totalReturn := marketValue / totalCost
+		dividendIncome, _ := CalculateDividendIncome(transactions, time.Now())
+		totalReturn := (marketValue + dividendIncome) / totalCost
 		return (math.Pow(totalReturn, 1/holdingPeriodYears) - 1) * 100
 	}
 	return rate * 100
 }
 
-// GetHistoricalPortfolioTotalValue calculates portfolio total value over time
-func (s *PortfolioService) GetHistoricalPortfolioTotalValue(ctx context.Context, userID uuid.UUID, timeframe models.TimeFrame) (*models.HistoricalTotalValueResponse, error) {
-	// Calculate time range based on timeframe
-	endTime := time.Now()
-	startTime, err := s.calculateStartTime(endTime, timeframe)
+// calculateSinceInceptionTWR returns the cumulative and annualized time-weighted return from the
+// first transaction up to the given market value, as fractions. The portfolio is valued from its
+// snapshots where they cover the history, so only the days after the latest snapshot need prices.
+func (s *PortfolioService) calculateSinceInceptionTWR(ctx context.Context, userID uuid.UUID, transactions []models.Transaction, baseCurrency string, marketValue float64, now time.Time, opts PortfolioOptions) (float64, float64) {
+	var firstDate time.Time
+	for _, tx := range transactions {
+		if firstDate.IsZero() || tx.TransactionDate.Before(firstDate) {
+			firstDate = tx.TransactionDate
+		}
+	}
+
+	// Start at the end of the day before the first transaction, when nothing was invested yet, so
+	// that the first day's snapshotted flows fall inside the range
+	startTime := SnapshotDay(firstDate).Add(-time.Second)
+	timeline := s.newValueTimeline(ctx, userID, baseCurrency, transactions, startTime, now, opts)
+	returns := calculateTimeWeightedReturns([]time.Time{startTime, now}, []float64{0, marketValue}, timeline)
+
+	years := now.Sub(firstDate).Hours() / 24 / 365.25
+	return returns[1], utils.AnnualizeReturn(returns[1], years)
+}
+
+// calculateTimeWeightedReturns returns the time-weighted return of each interval between consecutive
+// time points as a fraction, revaluing the portfolio at the end of every day with an external flow
+func calculateTimeWeightedReturns(timePoints []time.Time, values []float64, timeline valueTimeline) []float64 {
+	returns := make([]float64, len(timePoints))
+	for i := 1; i < len(timePoints); i++ {
+		start, end := timePoints[i-1], timePoints[i]
+		flows := timeline.FlowsIn(start, end)
+		chainValues := []float64{values[i-1]}
+		chainFlows := []float64{0}
+
+		previous := start
+		for _, checkpoint := range cashFlowCheckpoints(flows, start, end) {
+			chainValues = append(chainValues, timeline.ValueAt(checkpoint))
+			chainFlows = append(chainFlows, sumCashFlows(flows, previous, checkpoint))
+			previous = checkpoint
+		}
+		chainValues = append(chainValues, values[i])
+		chainFlows = append(chainFlows, sumCashFlows(flows, previous, end))
+
+		returns[i] = utils.TimeWeightedReturn(chainValues, chainFlows)
+	}
+
+	return returns
+}
+
+// xirrCashFlow returns the investor's cash flow for a transaction: purchases are outflows,
+// sale proceeds and dividends received are inflows, and splits move no cash
+func xirrCashFlow(tx models.Transaction) (float64, bool) {
+	switch tx.TradeType {
+	case types.TradeTypeBuy:
+		return -tx.Amount, true
+	case types.TradeTypeSell, types.TradeTypeDividend:
+		return tx.Amount, true
+	default:
+		return 0, false
+	}
+}
+
+// GetDividendIncome returns the user's dividend income aggregated by period and symbol, with each
+// dividend converted into the base currency at the rate of its payment date
+func (s *PortfolioService) GetDividendIncome(ctx context.Context, userID uuid.UUID, groupBy models.DividendGrouping, opts PortfolioOptions) (*models.DividendIncomeReport, error) {
+	transactions, err := s.getTransactions(userID, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to calculate start time: %w", err)
+		return nil, fmt.Errorf("failed to get transactions for user: %w", err)
 	}
 
-	// Determine granularity
-	defaultGranularity := s.determineDefaultGranularity(timeframe)
-	var granularity = &defaultGranularity
+	var dividends []models.Transaction
+	for _, tx := range transactions {
+		if tx.TradeType == types.TradeTypeDividend {
+			dividends = append(dividends, tx)
+		}
+	}
 
+	now := time.Now()
+	baseCurrency := s.resolveBaseCurrency(userID)
+	if len(dividends) > 0 {
+		converter := s.newFXConverter(ctx, baseCurrency, dividends, dividends[0].TransactionDate, now)
+		dividends = ConvertTransactionAmounts(dividends, converter.rateForCurrency)
+	}
+
+	report := BuildDividendReport(dividends, groupBy, now)
+	report.Currency = baseCurrency
+	return report, nil
+}
+
+// GetHistoricalPortfolioTotalValue calculates portfolio total value over time
+func (s *PortfolioService) GetHistoricalPortfolioTotalValue(ctx context.Context, userID uuid.UUID, chartRange ChartRange, opts PortfolioOptions) (*models.HistoricalTotalValueResponse, error) {
 	// Get all transactions for the user up to end time (needed for correct portfolio calculation)
-	allTransactions, err := s.transactionRepo.GetByUserID(userID)
+	allTransactions, err := s.getTransactions(userID, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
 
-	// For ALL timeframe, use first transaction date as start time
-	if timeframe == models.TimeFrameALL && len(allTransactions) > 0 {
-		startTime = allTransactions[0].TransactionDate
+	timeframe, startTime, endTime, granularity, err := s.resolveChartRange(chartRange, allTransactions)
+	if err != nil {
+		return nil, err
 	}
 
+	baseCurrency := s.resolveBaseCurrency(userID)
+
 	if len(allTransactions) == 0 {
 		return &models.HistoricalTotalValueResponse{
 			TimeFrame:   timeframe,
-			Granularity: *granularity,
+			Granularity: granularity,
+			Currency:    baseCurrency,
 			Period: struct {
 				StartDate time.Time `json:"start_date"`
 				EndDate   time.Time `json:"end_date"`
@@ -350,18 +733,26 @@ func (s *PortfolioService) GetHistoricalPortfolioTotalValue(ctx context.Context,
 		}, nil
 	}
 
+	if err := checkPointLimit(startTime, endTime, granularity); err != nil {
+		return nil, err
+	}
+
 	// Generate time points based on granularity
-	timePoints := s.generateTimePoints(startTime, endTime, *granularity)
+	timePoints := s.generateTimePoints(startTime, endTime, granularity)
+	var timeline valueTimeline
+	if granularity == models.GranularityHourly {
+		// Daily snapshots and closes cannot show moves within a day
+		timeline = s.newIntradayPortfolioValuer(ctx, baseCurrency, allTransactions, startTime, endTime)
+	} else {
+		timeline = s.newValueTimeline(ctx, userID, baseCurrency, allTransactions, startTime, endTime, opts)
+	}
 
 	// Calculate total value for each time point
 	dataPoints := make([]models.TotalValueDataPoint, 0, len(timePoints))
 	var previousValue float64
 
 	for i, timePoint := range timePoints {
-		totalValue, err := s.calculateTotalValueAtTime(ctx, allTransactions, timePoint)
-		if err != nil {
-			return nil, fmt.Errorf("failed to calculate total value at %v: %w", timePoint, err)
-		}
+		totalValue := timeline.ValueAt(timePoint)
 
 		// Calculate day change
 		dayChange := 0.0
@@ -381,12 +772,29 @@ func (s *PortfolioService) GetHistoricalPortfolioTotalValue(ctx context.Context,
 		previousValue = totalValue
 	}
 
+	// Link the time-weighted return of each period into a cumulative series
+	values := make([]float64, len(dataPoints))
+	for i, point := range dataPoints {
+		values[i] = point.TotalValue
+	}
+	periodReturns := calculateTimeWeightedReturns(timePoints, values, timeline)
+	growth := 1.0
+	for i := range dataPoints {
+		growth *= 1 + periodReturns[i]
+		dataPoints[i].PeriodReturn = utils.RoundTo4(periodReturns[i] * 100)
+		dataPoints[i].CumulativeReturn = utils.RoundTo4((growth - 1) * 100)
+	}
+
 	// Calculate summary statistics
 	summary := s.calculateSummaryStatistics(dataPoints)
+	if len(dataPoints) > 0 {
+		summary.TWR = dataPoints[len(dataPoints)-1].CumulativeReturn
+	}
 
-	return &models.HistoricalTotalValueResponse{
+	response := &models.HistoricalTotalValueResponse{
 		TimeFrame:   timeframe,
-		Granularity: *granularity,
+		Granularity: granularity,
+		Currency:    baseCurrency,
 		Period: struct {
 			StartDate time.Time `json:"start_date"`
 			EndDate   time.Time `json:"end_date"`
@@ -396,7 +804,322 @@ func (s *PortfolioService) GetHistoricalPortfolioTotalValue(ctx context.Context,
 		},
 		DataPoints: dataPoints,
 		Summary:    summary,
-	}, nil
+	}
+
+	if opts.Benchmark != "" {
+		response.Benchmark, err = s.compareToBenchmark(ctx, opts.Benchmark, timePoints, dataPoints, periodReturns, timeline)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// resolveChartRange returns the timeframe, times and granularity of a chart range. A custom range
+// ends at the requested day or now, whichever is earlier; ALL starts at the first of the charted
+// transactions, which are sorted by date.
+func (s *PortfolioService) resolveChartRange(chartRange ChartRange, transactions []models.Transaction) (timeframe models.TimeFrame, startTime, endTime time.Time, granularity models.Granularity, err error) {
+	timeframe = chartRange.TimeFrame
+	endTime = time.Now()
+	if chartRange.From != nil {
+		timeframe = models.TimeFrameCustom
+		startTime = *chartRange.From
+		if chartRange.To != nil && chartRange.To.Before(endTime) {
+			endTime = *chartRange.To
+		}
+	} else {
+		startTime, err = s.calculateStartTime(endTime, timeframe)
+		if err != nil {
+			return "", time.Time{}, time.Time{}, "", fmt.Errorf("failed to calculate start time: %w", err)
+		}
+	}
+
+	// For ALL timeframe, use first transaction date as start time
+	if timeframe == models.TimeFrameALL && len(transactions) > 0 {
+		startTime = transactions[0].TransactionDate
+	}
+
+	granularity = chartRange.Granularity
+	if granularity == "" {
+		granularity = s.determineDefaultGranularity(timeframe, startTime, endTime)
+	}
+
+	return timeframe, startTime, endTime, granularity, nil
+}
+
+// GetHoldingChart charts the quantity, market value, cost basis and unrealized gain of a holding
+// over the same ranges as the portfolio chart, in the base currency, with its transactions as events
+func (s *PortfolioService) GetHoldingChart(ctx context.Context, userID uuid.UUID, symbol string, chartRange ChartRange, opts PortfolioOptions) (*models.HoldingChartResponse, error) {
+	transactions, err := s.getSymbolTransactions(userID, symbol, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions for symbol %s: %w", symbol, err)
+	}
+
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("no transactions found for symbol %s", symbol)
+	}
+
+	timeframe, startTime, endTime, granularity, err := s.resolveChartRange(chartRange, transactions)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPointLimit(startTime, endTime, granularity); err != nil {
+		return nil, err
+	}
+
+	baseCurrency := s.resolveBaseCurrency(userID)
+	method := s.resolveCostBasisMethod(userID, opts)
+	timePoints := s.generateTimePoints(startTime, endTime, granularity)
+	valuer := s.buildPortfolioValuer(ctx, baseCurrency, transactions, startTime, endTime, granularity == models.GranularityHourly)
+	dataPoints, events := BuildHoldingChart(symbol, transactions, valuer, method, timePoints)
+
+	response := &models.HoldingChartResponse{
+		Symbol:          symbol,
+		TimeFrame:       timeframe,
+		Granularity:     granularity,
+		Currency:        baseCurrency,
+		NativeCurrency:  SymbolCurrency(transactions),
+		CostBasisMethod: method,
+		DataPoints:      dataPoints,
+		Events:          events,
+	}
+	response.Period.StartDate = startTime
+	response.Period.EndDate = endTime
+
+	return response, nil
+}
+
+// compareToBenchmark simulates investing the chart's starting value and later external flows in a
+// benchmark, and measures the portfolio's period returns against the benchmark's
+func (s *PortfolioService) compareToBenchmark(ctx context.Context, symbol string, timePoints []time.Time, dataPoints []models.TotalValueDataPoint, periodReturns []float64, timeline valueTimeline) (*models.BenchmarkComparison, error) {
+	prices, err := s.getPriceSeries(ctx, symbol, timePoints[0], timePoints[len(timePoints)-1])
+	if err != nil {
+		return nil, fmt.Errorf("benchmark_not_found: %w", err)
+	}
+
+	// The benchmark receives the same flows as the portfolio, in the base currency
+	flows := timeline.FlowsIn(timePoints[0], timePoints[len(timePoints)-1])
+	values := SimulateBenchmark(prices, timePoints, dataPoints[0].TotalValue, flows)
+
+	comparison := &models.BenchmarkComparison{
+		Symbol:     symbol,
+		DataPoints: make([]models.BenchmarkDataPoint, len(timePoints)),
+	}
+
+	// Periods before anything was invested carry no portfolio return and are left out
+	var portfolioReturns, benchmarkReturns []float64
+	firstPrice, _ := prices.PriceOn(timePoints[0])
+	previousPrice := firstPrice
+	for i, timePoint := range timePoints {
+		price, ok := prices.PriceOn(timePoint)
+		if !ok {
+			price = previousPrice
+		}
+		var cumulativeReturn float64
+		if firstPrice > 0 {
+			cumulativeReturn = price/firstPrice - 1
+		}
+		comparison.DataPoints[i] = models.BenchmarkDataPoint{
+			Timestamp:        timePoint,
+			TotalValue:       utils.RoundTo4(values[i]),
+			CumulativeReturn: utils.RoundTo4(cumulativeReturn * 100),
+		}
+
+		if i > 0 && previousPrice > 0 && dataPoints[i-1].TotalValue > 0 {
+			portfolioReturns = append(portfolioReturns, periodReturns[i])
+			benchmarkReturns = append(benchmarkReturns, price/previousPrice-1)
+		}
+		previousPrice = price
+	}
+
+	alpha, beta, trackingError := CompareToBenchmark(portfolioReturns, benchmarkReturns, periodsPerYear(timePoints))
+	comparison.TWR = comparison.DataPoints[len(timePoints)-1].CumulativeReturn
+	comparison.Alpha = utils.RoundTo4(alpha * 100)
+	comparison.Beta = utils.RoundTo4(beta)
+	comparison.TrackingError = utils.RoundTo4(trackingError * 100)
+
+	return comparison, nil
+}
+
+// getPriceSeries loads the daily closes of a symbol between two times
+func (s *PortfolioService) getPriceSeries(ctx context.Context, symbol string, startTime, endTime time.Time) (*PriceSeries, error) {
+	// Start a little early so the first day can carry a prior close forward
+	fromDate := startTime.AddDate(0, 0, -14).Format("2006-01-02")
+	history, err := s.priceManager.GetHistoricalPrices(ctx, []string{symbol}, provider.ResolutionDaily, fromDate, endTime.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 || len(history[0].HistoricalPrices) == 0 {
+		return nil, fmt.Errorf("no prices for %s", symbol)
+	}
+	return NewPriceSeries(history[0].HistoricalPrices), nil
+}
+
+// getIntradayPriceSeries loads the latest hourly closes of a symbol
+func (s *PortfolioService) getIntradayPriceSeries(ctx context.Context, symbol string) (*PriceSeries, error) {
+	history, err := s.priceManager.GetHistoricalPrices(ctx, []string{symbol}, provider.ResolutionIntraday, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 || len(history[0].HistoricalPrices) == 0 {
+		return nil, fmt.Errorf("no intraday prices for %s", symbol)
+	}
+	return NewIntradayPriceSeries(history[0].HistoricalPrices), nil
+}
+
+// GetRiskMetrics measures the portfolio's volatility, risk-adjusted returns and drawdown from daily
+// time-weighted returns over a timeframe, and its beta and each holding's against opts.Benchmark
+func (s *PortfolioService) GetRiskMetrics(ctx context.Context, userID uuid.UUID, timeframe models.TimeFrame, riskFreeRate float64, opts PortfolioOptions) (*models.RiskMetrics, error) {
+	endTime := time.Now()
+	startTime, err := s.calculateStartTime(endTime, timeframe)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate start time: %w", err)
+	}
+
+	allTransactions, err := s.getTransactions(userID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	// For ALL timeframe, use first transaction date as start time; without transactions the
+	// timeframe is empty
+	if timeframe == models.TimeFrameALL {
+		startTime = endTime
+		if len(allTransactions) > 0 {
+			startTime = allTransactions[0].TransactionDate
+		}
+	}
+
+	baseCurrency := s.resolveBaseCurrency(userID)
+	metrics := &models.RiskMetrics{
+		TimeFrame:    timeframe,
+		Currency:     baseCurrency,
+		StartDate:    startTime,
+		EndDate:      endTime,
+		Benchmark:    opts.Benchmark,
+		RiskFreeRate: utils.RoundTo4(riskFreeRate * 100),
+		Holdings:     []models.HoldingRisk{},
+		Timestamp:    endTime,
+	}
+
+	if len(allTransactions) == 0 {
+		return metrics, nil
+	}
+
+	days := tradingDays(startTime, endTime)
+	metrics.TradingDays = len(days)
+	if len(days) < 2 {
+		return metrics, nil
+	}
+
+	benchmarkPrices, err := s.getPriceSeries(ctx, opts.Benchmark, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("benchmark_not_found: %w", err)
+	}
+	benchmarkReturns, benchmarkIndices := PriceReturns(benchmarkPrices, days)
+	benchmarkReturnOn := make(map[int]float64, len(benchmarkIndices))
+	for i, idx := range benchmarkIndices {
+		benchmarkReturnOn[idx] = benchmarkReturns[i]
+	}
+
+	// Value the portfolio at every trading day and chain out the external flows
+	valuer := s.newPortfolioValuer(ctx, baseCurrency, allTransactions, startTime, endTime)
+	values := make([]float64, len(days))
+	for i, day := range days {
+		values[i] = valuer.ValueAt(day)
+	}
+	periodReturns := calculateTimeWeightedReturns(days, values, valuer)
+
+	// Days before anything was invested carry no return and are left out
+	var dailyReturns, pairedReturns, pairedBenchmarkReturns []float64
+	var returnDays []int
+	for i := 1; i < len(days); i++ {
+		if values[i-1] <= 0 {
+			continue
+		}
+		dailyReturns = append(dailyReturns, periodReturns[i])
+		returnDays = append(returnDays, i)
+		if benchmarkReturn, ok := benchmarkReturnOn[i]; ok {
+			pairedReturns = append(pairedReturns, periodReturns[i])
+			pairedBenchmarkReturns = append(pairedBenchmarkReturns, benchmarkReturn)
+		}
+	}
+
+	stats := CalculateReturnStatistics(dailyReturns, riskFreeRate)
+	metrics.AnnualizedReturn = utils.RoundTo4(stats.AnnualizedReturn * 100)
+	metrics.AnnualizedVolatility = utils.RoundTo4(stats.AnnualizedVolatility * 100)
+	metrics.DownsideDeviation = utils.RoundTo4(stats.DownsideDeviation * 100)
+	metrics.SharpeRatio = utils.RoundTo4(stats.SharpeRatio)
+	metrics.SortinoRatio = utils.RoundTo4(stats.SortinoRatio)
+	metrics.Beta = utils.RoundTo4(Beta(pairedReturns, pairedBenchmarkReturns))
+
+	// Drawdown is measured on the cumulative return index, so withdrawals do not count as losses
+	if len(returnDays) > 0 {
+		index := []float64{1}
+		indexDays := []time.Time{days[returnDays[0]-1]}
+		for i, r := range dailyReturns {
+			index = append(index, index[i]*(1+r))
+			indexDays = append(indexDays, days[returnDays[i]])
+		}
+		drawdown, peak, trough := utils.MaxDrawdown(index)
+		metrics.MaxDrawdown.Drawdown = utils.RoundTo4(drawdown * 100)
+		if drawdown > 0 {
+			metrics.MaxDrawdown.PeakDate = &indexDays[peak]
+			metrics.MaxDrawdown.TroughDate = &indexDays[trough]
+		}
+	}
+
+	holdingRisks, err := s.calculateHoldingRisks(ctx, userID, opts, days, benchmarkReturnOn)
+	if err != nil {
+		return nil, err
+	}
+	metrics.Holdings = holdingRisks
+
+	return metrics, nil
+}
+
+// calculateHoldingRisks measures each current holding's volatility and beta from its daily price
+// returns. Holdings whose prices cannot be loaded are reported with their weight only.
+func (s *PortfolioService) calculateHoldingRisks(ctx context.Context, userID uuid.UUID, opts PortfolioOptions, days []time.Time, benchmarkReturnOn map[int]float64) ([]models.HoldingRisk, error) {
+	holdings, _, err := s.GetAllHoldings(ctx, userID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holdings for risk metrics: %w", err)
+	}
+
+	var totalMarketValue float64
+	for _, holding := range holdings {
+		totalMarketValue += holding.MarketValue
+	}
+
+	risks := make([]models.HoldingRisk, 0, len(holdings))
+	for _, holding := range holdings {
+		risk := models.HoldingRisk{Symbol: holding.Symbol}
+		if totalMarketValue > 0 {
+			risk.Weight = utils.RoundTo4(holding.MarketValue / totalMarketValue * 100)
+		}
+
+		prices, err := s.getPriceSeries(ctx, holding.Symbol, days[0], days[len(days)-1])
+		if err != nil {
+			logger.Warn("Failed to get prices for holding risk", logger.H{"symbol": holding.Symbol, "error": err})
+			risks = append(risks, risk)
+			continue
+		}
+
+		returns, indices := PriceReturns(prices, days)
+		var pairedReturns, pairedBenchmarkReturns []float64
+		for i, idx := range indices {
+			if benchmarkReturn, ok := benchmarkReturnOn[idx]; ok {
+				pairedReturns = append(pairedReturns, returns[i])
+				pairedBenchmarkReturns = append(pairedBenchmarkReturns, benchmarkReturn)
+			}
+		}
+		risk.AnnualizedVolatility = utils.RoundTo4(utils.StandardDeviation(returns) * math.Sqrt(tradingDaysPerYear) * 100)
+		risk.Beta = utils.RoundTo4(Beta(pairedReturns, pairedBenchmarkReturns))
+		risks = append(risks, risk)
+	}
+
+	return risks, nil
 }
 
 // calculateStartTime determines the start time based on timeframe
@@ -427,40 +1150,25 @@ func (s *PortfolioService) calculateStartTime(endTime time.Time, timeframe model
 	}
 }
 
-// determineDefaultGranularity determines appropriate granularity for timeframe
-func (s *PortfolioService) determineDefaultGranularity(timeframe models.TimeFrame) models.Granularity {
-	switch timeframe {
-	case models.TimeFrame1W:
-		return models.GranularityDaily
-	case models.TimeFrame1M:
-		return models.GranularityWeekly
-	case models.TimeFrame3M, models.TimeFrame6M:
-		return models.GranularityMonthly
-	case models.TimeFrameYTD, models.TimeFrame1Y, models.TimeFrame5Y, models.TimeFrameALL:
-		return models.GranularityMonthly
-	default:
-		return models.GranularityDaily
+// determineDefaultGranularity picks hourly points for 1D, and otherwise the finest of daily, weekly
+// and monthly points that keeps the chart within the point limit
+func (s *PortfolioService) determineDefaultGranularity(timeframe models.TimeFrame, startTime, endTime time.Time) models.Granularity {
+	if timeframe == models.TimeFrame1D {
+		return models.GranularityHourly
 	}
+	for _, granularity := range []models.Granularity{models.GranularityDaily, models.GranularityWeekly} {
+		if fitsPointLimit(startTime, endTime, granularity) {
+			return granularity
+		}
+	}
+	return models.GranularityMonthly
 }
 
 // generateTimePoints creates time points based on granularity
 func (s *PortfolioService) generateTimePoints(startTime, endTime time.Time, granularity models.Granularity) []time.Time {
 	var timePoints []time.Time
-	current := startTime
-
-	for current.Before(endTime) || current.Equal(endTime) {
+	for current := startTime; !current.After(endTime); current = nextTimePoint(current, granularity) {
 		timePoints = append(timePoints, current)
-
-		switch granularity {
-		case models.GranularityHourly:
-			current = current.Add(time.Hour)
-		case models.GranularityDaily:
-			current = current.AddDate(0, 0, 1)
-		case models.GranularityWeekly:
-			current = current.AddDate(0, 0, 7)
-		case models.GranularityMonthly:
-			current = current.AddDate(0, 1, 0)
-		}
 	}
 
 	// Ensure endTime is included for Weekly and Monthly if not already present
@@ -474,73 +1182,171 @@ func (s *PortfolioService) generateTimePoints(startTime, endTime time.Time, gran
 	return timePoints
 }
 
-// calculateTotalValueAtTime calculates portfolio total value at a specific time
-func (s *PortfolioService) calculateTotalValueAtTime(ctx context.Context, transactions []models.Transaction, targetTime time.Time) (float64, error) {
-	// Group transactions by symbol and calculate holdings at target time
-	holdings := make(map[string]float64)
+// nextTimePoint returns the time point one granularity step after another
+func nextTimePoint(current time.Time, granularity models.Granularity) time.Time {
+	switch granularity {
+	case models.GranularityHourly:
+		return current.Add(time.Hour)
+	case models.GranularityWeekly:
+		return current.AddDate(0, 0, 7)
+	case models.GranularityMonthly:
+		return current.AddDate(0, 1, 0)
+	default:
+		return current.AddDate(0, 0, 1)
+	}
+}
 
-	for _, transaction := range transactions {
-		if transaction.TransactionDate.After(targetTime) {
-			continue // Skip future transactions
+// fitsPointLimit reports whether a chart between two times at a granularity stays within
+// maxChartDataPoints, counting the points without generating them all
+func fitsPointLimit(startTime, endTime time.Time, granularity models.Granularity) bool {
+	limit := maxChartDataPoints
+	if granularity == models.GranularityWeekly || granularity == models.GranularityMonthly {
+		limit-- // room for the end time added after the last full step
+	}
+	points := 0
+	for current := startTime; !current.After(endTime); current = nextTimePoint(current, granularity) {
+		points++
+		if points > limit {
+			return false
 		}
+	}
+	return true
+}
 
-		symbol := transaction.Symbol
-		quantity := transaction.Quantity
+// checkPointLimit returns a too_many_points error when a chart would exceed maxChartDataPoints
+func checkPointLimit(startTime, endTime time.Time, granularity models.Granularity) error {
+	if fitsPointLimit(startTime, endTime, granularity) {
+		return nil
+	}
+	return fmt.Errorf("too_many_points: %s data points from %s to %s exceed the limit of %d",
+		granularity, startTime.Format("2006-01-02"), endTime.Format("2006-01-02"), maxChartDataPoints)
+}
 
-		if transaction.TradeType == "Sell" {
-			quantity = -quantity
-		}
+// newFXConverter loads the rates needed to value each symbol in the base currency between two times.
+// Rate lookups that fail are logged and fall back to the current rate.
+func (s *PortfolioService) newFXConverter(ctx context.Context, baseCurrency string, transactions []models.Transaction, startTime, endTime time.Time) *fxConverter {
+	converter := &fxConverter{
+		baseCurrency:    baseCurrency,
+		symbolCurrency:  make(map[string]string),
+		historicalRates: make(map[string]*FXRateSeries),
+		currentRates:    map[string]float64{baseCurrency: 1},
+	}
 
-		holdings[symbol] += quantity
+	transactionsBySymbol := make(map[string][]models.Transaction)
+	for _, tx := range transactions {
+		transactionsBySymbol[tx.Symbol] = append(transactionsBySymbol[tx.Symbol], tx)
 	}
 
-	// Calculate total market value using historical prices at target time
-	totalValue := 0.0
-	targetDateStr := targetTime.Format("2006-01-02")
+	for symbol, symbolTransactions := range transactionsBySymbol {
+		converter.symbolCurrency[symbol] = SymbolCurrency(symbolTransactions)
+	}
 
-	for symbol, quantity := range holdings {
-		if quantity <= 0 {
-			continue // Skip if no holdings
+	// Cash is held in the currency of each transaction, so load every currency seen
+	var foreign []string
+	for _, currency := range TransactionCurrencies(transactions) {
+		if currency == baseCurrency {
+			continue
 		}
 
-		// Get historical price for the symbol at target date
-		historicalPrice, err := s.priceManager.GetHistoricalPriceAtDate(ctx, symbol, targetDateStr)
+		// Start a little early so the first valuation date can carry a prior rate forward
+		fromDate := startTime.AddDate(0, 0, -14).Format("2006-01-02")
+		history, err := s.priceManager.GetHistoricalFXRates(ctx, currency, baseCurrency, fromDate, endTime.Format("2006-01-02"))
 		if err != nil {
-			// If we can't get historical price, fallback to current price as last resort
-			priceData, fallbackErr := s.priceManager.GetCurrentPrice(ctx, symbol)
-			if fallbackErr != nil {
-				// If both historical and current price fail, skip this holding
-				continue
-			}
-			totalValue += quantity * priceData.CurrentPrice
-			continue
+			logger.Warn("Failed to get historical FX rates", logger.H{"currency": currency, "base_currency": baseCurrency, "error": err})
+			history = &provider.FXHistoricalRates{}
 		}
+		converter.historicalRates[currency] = NewFXRateSeries(history.Rates)
+		foreign = append(foreign, currency)
+	}
 
-		// Find the price for the exact date
-		var priceAtDate float64
-		found := false
-
-		for _, pricePoint := range historicalPrice.HistoricalPrices {
-			if pricePoint.Date == targetDateStr {
-				priceAtDate = pricePoint.Price
-				found = true
-				break
-			}
+	if len(foreign) > 0 {
+		currentRates, err := s.priceManager.GetCurrentFXRates(ctx, baseCurrency, foreign)
+		if err != nil {
+			logger.Warn("Failed to get current FX rates", logger.H{"base_currency": baseCurrency, "error": err})
+		} else {
+			converter.currentRates = currentRates
 		}
+	}
+
+	return converter
+}
 
-		if !found {
-			// If exact date not found, fallback to current price
-			priceData, fallbackErr := s.priceManager.GetCurrentPrice(ctx, symbol)
-			if fallbackErr != nil {
+// newValueTimeline values the whole portfolio from its stored snapshots when they cover every day
+// from the start of the chart, or the first transaction if later, up to the latest snapshot; only
+// the days after it then need prices. A single account, or a gap in the snapshots, is valued from
+// prices alone.
+func (s *PortfolioService) newValueTimeline(ctx context.Context, userID uuid.UUID, baseCurrency string, transactions []models.Transaction, startTime, endTime time.Time, opts PortfolioOptions) valueTimeline {
+	if s.snapshotRepo == nil || opts.AccountID != nil || len(transactions) == 0 {
+		return s.newPortfolioValuer(ctx, baseCurrency, transactions, startTime, endTime)
+	}
+
+	from := SnapshotDay(startTime)
+	if first := SnapshotDay(transactions[0].TransactionDate); first.After(from) {
+		from = first
+	}
+	snapshots, err := s.snapshotRepo.GetByUserIDInRange(userID, from, SnapshotDay(endTime))
+	if err != nil {
+		logger.Warn("Failed to get portfolio snapshots", logger.H{"user_id": userID.String(), "error": err})
+		return s.newPortfolioValuer(ctx, baseCurrency, transactions, startTime, endTime)
+	}
+	if len(snapshots) == 0 || !SnapshotsCover(snapshots, baseCurrency, from, snapshots[len(snapshots)-1].SnapshotDate) {
+		return s.newPortfolioValuer(ctx, baseCurrency, transactions, startTime, endTime)
+	}
+
+	coveredUntil := endOfSnapshotDay(snapshots[len(snapshots)-1].SnapshotDate)
+	if !coveredUntil.Before(endTime) {
+		// A range ending on a snapshotted day needs no prices at all
+		return NewSnapshotTimeline(snapshots, NewPortfolioValuer(transactions, nil, nil))
+	}
+	return NewSnapshotTimeline(snapshots, s.newPortfolioValuer(ctx, baseCurrency, transactions, coveredUntil, endTime))
+}
+
+// newPortfolioValuer values the portfolio between two times from the daily closes of every symbol
+// held and the FX rates needed. Symbols whose closes cannot be loaded are logged and valued at their
+// latest trade price.
+func (s *PortfolioService) newPortfolioValuer(ctx context.Context, baseCurrency string, transactions []models.Transaction, startTime, endTime time.Time) *PortfolioValuer {
+	return s.buildPortfolioValuer(ctx, baseCurrency, transactions, startTime, endTime, false)
+}
+
+// newIntradayPortfolioValuer is newPortfolioValuer with hourly closes, falling back to daily closes
+// for symbols the price service has no intraday prices for
+func (s *PortfolioService) newIntradayPortfolioValuer(ctx context.Context, baseCurrency string, transactions []models.Transaction, startTime, endTime time.Time) *PortfolioValuer {
+	return s.buildPortfolioValuer(ctx, baseCurrency, transactions, startTime, endTime, true)
+}
+
+// buildPortfolioValuer loads the FX rates behind newPortfolioValuer and newIntradayPortfolioValuer.
+// Prices are loaded when the valuer first values a position, so a valuer only asked for flows, as
+// after the latest snapshot of a timeline, fetches none.
+func (s *PortfolioService) buildPortfolioValuer(ctx context.Context, baseCurrency string, transactions []models.Transaction, startTime, endTime time.Time, intraday bool) *PortfolioValuer {
+	converter := s.newFXConverter(ctx, baseCurrency, transactions, startTime, endTime)
+	valuer := NewPortfolioValuer(transactions, nil, converter)
+	valuer.loadPrices = func() map[string]*PriceSeries {
+		return s.loadValuationPrices(ctx, transactions, startTime, endTime, intraday)
+	}
+	return valuer
+}
+
+// loadValuationPrices loads the closes of every symbol held between two times
+func (s *PortfolioService) loadValuationPrices(ctx context.Context, transactions []models.Transaction, startTime, endTime time.Time, intraday bool) map[string]*PriceSeries {
+	prices := make(map[string]*PriceSeries)
+	for _, symbol := range HeldSymbols(transactions, startTime, endTime) {
+		if intraday {
+			series, err := s.getIntradayPriceSeries(ctx, symbol)
+			if err == nil {
+				prices[symbol] = series
 				continue
 			}
-			priceAtDate = priceData.CurrentPrice
+			logger.Warn("Failed to get intraday prices for valuation, using daily closes", logger.H{"symbol": symbol, "error": err})
 		}
 
-		totalValue += quantity * priceAtDate
+		series, err := s.getPriceSeries(ctx, symbol, startTime, endTime)
+		if err != nil {
+			logger.Warn("Failed to get historical prices for valuation", logger.H{"symbol": symbol, "error": err})
+			series = NewPriceSeries(nil)
+		}
+		prices[symbol] = series
 	}
-
-	return totalValue, nil
+	return prices
 }
 
 // calculateSummaryStatistics calculates summary statistics for the data points
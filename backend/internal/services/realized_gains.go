@@ -0,0 +1,108 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// ClassifyHoldingTerm returns long term when the shares were held for more than one year
+func ClassifyHoldingTerm(acquiredAt, soldAt time.Time) models.HoldingTerm {
+	acquired := time.Date(acquiredAt.Year(), acquiredAt.Month(), acquiredAt.Day(), 0, 0, 0, 0, time.UTC)
+	sold := time.Date(soldAt.Year(), soldAt.Month(), soldAt.Day(), 0, 0, 0, 0, time.UTC)
+	if sold.After(acquired.AddDate(1, 0, 0)) {
+		return models.HoldingTermLong
+	}
+	return models.HoldingTermShort
+}
+
+// BuildRealizedGainsReport aggregates the disposals made during the given year per symbol and in total
+func BuildRealizedGainsReport(year int, method models.CostBasisMethod, disposals []LotDisposal) *models.RealizedGainsReport {
+	report := &models.RealizedGainsReport{
+		Year:            year,
+		CostBasisMethod: method,
+		Symbols:         []models.SymbolRealizedGains{},
+		Disposals:       []models.RealizedGainDisposal{},
+		Timestamp:       time.Now(),
+	}
+
+	bySymbol := make(map[string]*models.RealizedGainTotals)
+	for _, d := range disposals {
+		if d.SoldAt.Year() != year {
+			continue
+		}
+
+		term := ClassifyHoldingTerm(d.AcquiredAt, d.SoldAt)
+		var replacementIDs []string
+		for _, id := range d.ReplacementTransactionIDs {
+			replacementIDs = append(replacementIDs, id.String())
+		}
+		report.Disposals = append(report.Disposals, models.RealizedGainDisposal{
+			Symbol:            d.Symbol,
+			SellTransactionID: d.SellTransactionID.String(),
+			LotTransactionID:  d.LotTransactionID.String(),
+			AcquiredAt:        d.AcquiredAt,
+			SoldAt:            d.SoldAt,
+			Quantity:          utils.RoundTo4(d.Quantity),
+			Proceeds:          utils.RoundTo4(d.Proceeds),
+			CostBasis:         utils.RoundTo4(d.CostBasis),
+			GainLoss:          utils.RoundTo4(d.GainLoss),
+			Term:              term,
+
+			WashSale:                  d.WashSale,
+			DisallowedLoss:            utils.RoundTo4(d.DisallowedLoss),
+			ReplacementTransactionIDs: replacementIDs,
+		})
+
+		totals, ok := bySymbol[d.Symbol]
+		if !ok {
+			totals = &models.RealizedGainTotals{}
+			bySymbol[d.Symbol] = totals
+		}
+		addRealizedGain(totals, d, term)
+		addRealizedGain(&report.Totals, d, term)
+	}
+
+	for symbol, totals := range bySymbol {
+		report.Symbols = append(report.Symbols, models.SymbolRealizedGains{
+			Symbol:             symbol,
+			RealizedGainTotals: roundRealizedGainTotals(*totals),
+		})
+	}
+	sort.Slice(report.Symbols, func(i, j int) bool {
+		return report.Symbols[i].Symbol < report.Symbols[j].Symbol
+	})
+	sort.SliceStable(report.Disposals, func(i, j int) bool {
+		return report.Disposals[i].SoldAt.Before(report.Disposals[j].SoldAt)
+	})
+	report.Totals = roundRealizedGainTotals(report.Totals)
+
+	return report
+}
+
+// addRealizedGain adds a disposal to the totals under its holding term
+func addRealizedGain(totals *models.RealizedGainTotals, d LotDisposal, term models.HoldingTerm) {
+	totals.Proceeds += d.Proceeds
+	totals.CostBasis += d.CostBasis
+	totals.TotalGainLoss += d.GainLoss
+	totals.DisallowedLoss += d.DisallowedLoss
+	if term == models.HoldingTermLong {
+		totals.LongTermGainLoss += d.GainLoss
+	} else {
+		totals.ShortTermGainLoss += d.GainLoss
+	}
+}
+
+// roundRealizedGainTotals rounds every figure of the totals to 4 decimal places
+func roundRealizedGainTotals(totals models.RealizedGainTotals) models.RealizedGainTotals {
+	return models.RealizedGainTotals{
+		Proceeds:          utils.RoundTo4(totals.Proceeds),
+		CostBasis:         utils.RoundTo4(totals.CostBasis),
+		ShortTermGainLoss: utils.RoundTo4(totals.ShortTermGainLoss),
+		LongTermGainLoss:  utils.RoundTo4(totals.LongTermGainLoss),
+		TotalGainLoss:     utils.RoundTo4(totals.TotalGainLoss),
+		DisallowedLoss:    utils.RoundTo4(totals.DisallowedLoss),
+	}
+}
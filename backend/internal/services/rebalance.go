@@ -0,0 +1,233 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/types"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// shareRoundingEpsilon absorbs floating point noise before rounding a trade to whole shares
+const shareRoundingEpsilon = 1e-9
+
+// RebalanceOptions controls how target weights are turned into trades
+type RebalanceOptions struct {
+	// NoSells only buys with the available cash, leaving overweight positions alone
+	NoSells bool
+	// MinTradeSize drops trades worth less than this amount in the base currency
+	MinTradeSize float64
+	// WholeShares rounds buys down and sells up to whole shares
+	WholeShares bool
+}
+
+// RebalanceHolding is a position the rebalancer may trade, priced in the base currency
+type RebalanceHolding struct {
+	Symbol     string
+	AssetClass string
+	Quantity   float64
+	Price      float64
+}
+
+// BuildRebalancePlan returns the trades that move the holdings towards the target weights. A symbol
+// target takes precedence over its asset class, whose weight is split between the class's other
+// holdings by market value. Holdings without a target are sold, and weight left unassigned stays
+// in cash. Sells run first so their proceeds can fund the buys; when the cash falls short every
+// buy is scaled down by the same factor.
+func BuildRebalancePlan(currency string, holdings []RebalanceHolding, targets []models.TargetAllocation, cash float64, opts RebalanceOptions, now time.Time) *models.RebalancePlan {
+	cash = math.Max(cash, 0)
+	plan := &models.RebalancePlan{
+		Currency:         currency,
+		NoSells:          opts.NoSells,
+		WholeShares:      opts.WholeShares,
+		MinTradeSize:     utils.RoundTo4(opts.MinTradeSize),
+		Positions:        []models.RebalancePosition{},
+		Trades:           []models.RebalanceTrade{},
+		UnmatchedTargets: []models.TargetAllocation{},
+		Timestamp:        now,
+	}
+
+	priced := make([]RebalanceHolding, 0, len(holdings))
+	var marketValue float64
+	for _, holding := range holdings {
+		if holding.Price <= 0 {
+			continue
+		}
+		priced = append(priced, holding)
+		marketValue += holding.Quantity * holding.Price
+	}
+	total := marketValue + cash
+
+	targetWeights, unmatched := resolveTargetWeights(priced, targets)
+	plan.UnmatchedTargets = append(plan.UnmatchedTargets, unmatched...)
+
+	quantities := make([]float64, len(priced))
+	for i, holding := range priced {
+		quantities[i] = holding.Quantity
+	}
+
+	// Sells first, so their proceeds are available to the buys
+	var sells, buys []models.RebalanceTrade
+	available := cash
+	if !opts.NoSells && total > 0 {
+		for i, holding := range priced {
+			excess := holding.Quantity*holding.Price - targetWeights[holding.Symbol]/100*total
+			if excess <= 0 {
+				continue
+			}
+			quantity := excess / holding.Price
+			if opts.WholeShares {
+				quantity = math.Ceil(quantity - shareRoundingEpsilon)
+			}
+			quantity = math.Min(quantity, holding.Quantity)
+			amount := quantity * holding.Price
+			if quantity <= shareRoundingEpsilon || amount < opts.MinTradeSize {
+				continue
+			}
+			quantities[i] -= quantity
+			available += amount
+			sells = append(sells, models.RebalanceTrade{Symbol: holding.Symbol, Action: types.TradeTypeSell, Quantity: quantity, Price: holding.Price, Amount: amount})
+		}
+	}
+
+	// Buys share the available cash in proportion to how far each position is below target
+	shortfalls := make([]float64, len(priced))
+	var totalShortfall float64
+	for i, holding := range priced {
+		shortfall := targetWeights[holding.Symbol]/100*total - holding.Quantity*holding.Price
+		if shortfall > 0 {
+			shortfalls[i] = shortfall
+			totalShortfall += shortfall
+		}
+	}
+	scale := 1.0
+	if totalShortfall > available {
+		scale = available / totalShortfall
+	}
+	for i, holding := range priced {
+		if shortfalls[i] <= 0 {
+			continue
+		}
+		quantity := shortfalls[i] * scale / holding.Price
+		if opts.WholeShares {
+			quantity = math.Floor(quantity + shareRoundingEpsilon)
+		}
+		amount := quantity * holding.Price
+		if quantity <= shareRoundingEpsilon || amount < opts.MinTradeSize {
+			continue
+		}
+		quantities[i] += quantity
+		available -= amount
+		buys = append(buys, models.RebalanceTrade{Symbol: holding.Symbol, Action: types.TradeTypeBuy, Quantity: quantity, Price: holding.Price, Amount: amount})
+	}
+
+	for _, trades := range [][]models.RebalanceTrade{sells, buys} {
+		sort.SliceStable(trades, func(i, j int) bool {
+			return trades[i].Amount > trades[j].Amount
+		})
+		for _, trade := range trades {
+			if trade.Action == types.TradeTypeSell {
+				plan.SellAmount += trade.Amount
+			} else {
+				plan.BuyAmount += trade.Amount
+			}
+			trade.Quantity = utils.RoundTo4(trade.Quantity)
+			trade.Price = utils.RoundTo4(trade.Price)
+			trade.Amount = utils.RoundTo4(trade.Amount)
+			plan.Trades = append(plan.Trades, trade)
+		}
+	}
+
+	weight := func(value float64) float64 {
+		if total <= 0 {
+			return 0
+		}
+		return utils.RoundTo4(value / total * 100)
+	}
+	for i, holding := range priced {
+		value := holding.Quantity * holding.Price
+		plan.Positions = append(plan.Positions, models.RebalancePosition{
+			Symbol:            holding.Symbol,
+			AssetClass:        holding.AssetClass,
+			Price:             utils.RoundTo4(holding.Price),
+			Quantity:          utils.RoundTo4(holding.Quantity),
+			MarketValue:       utils.RoundTo4(value),
+			CurrentWeight:     weight(value),
+			TargetWeight:      utils.RoundTo4(targetWeights[holding.Symbol]),
+			ProjectedQuantity: utils.RoundTo4(quantities[i]),
+			ProjectedWeight:   weight(quantities[i] * holding.Price),
+		})
+	}
+	sort.SliceStable(plan.Positions, func(i, j int) bool {
+		if plan.Positions[i].TargetWeight != plan.Positions[j].TargetWeight {
+			return plan.Positions[i].TargetWeight > plan.Positions[j].TargetWeight
+		}
+		return plan.Positions[i].Symbol < plan.Positions[j].Symbol
+	})
+
+	plan.MarketValue = utils.RoundTo4(marketValue)
+	plan.Cash = utils.RoundTo4(cash)
+	plan.TotalValue = utils.RoundTo4(total)
+	plan.BuyAmount = utils.RoundTo4(plan.BuyAmount)
+	plan.SellAmount = utils.RoundTo4(plan.SellAmount)
+	plan.CashAfter = utils.RoundTo4(math.Max(available, 0))
+
+	return plan
+}
+
+// resolveTargetWeights returns the target weight of each holding's symbol, and the targets that
+// match no holding. A symbol targeted but not yet held matches when it is passed in as a holding
+// with no quantity.
+func resolveTargetWeights(holdings []RebalanceHolding, targets []models.TargetAllocation) (map[string]float64, []models.TargetAllocation) {
+	weights := make(map[string]float64)
+	symbolTargeted := make(map[string]bool)
+	var unmatched []models.TargetAllocation
+
+	held := make(map[string]bool, len(holdings))
+	for _, holding := range holdings {
+		held[holding.Symbol] = true
+	}
+	for _, target := range targets {
+		if target.Type != models.TargetTypeSymbol {
+			continue
+		}
+		if !held[target.Key] {
+			unmatched = append(unmatched, target)
+			continue
+		}
+		weights[target.Key] += target.Weight
+		symbolTargeted[target.Key] = true
+	}
+
+	for _, target := range targets {
+		if target.Type != models.TargetTypeAssetClass {
+			continue
+		}
+		var members []RebalanceHolding
+		var classValue float64
+		for _, holding := range holdings {
+			if symbolTargeted[holding.Symbol] || !strings.EqualFold(holding.AssetClass, target.Key) {
+				continue
+			}
+			members = append(members, holding)
+			classValue += holding.Quantity * holding.Price
+		}
+		if len(members) == 0 {
+			unmatched = append(unmatched, target)
+			continue
+		}
+		for _, member := range members {
+			// Split equally when the class is only held through new positions
+			share := 1 / float64(len(members))
+			if classValue > 0 {
+				share = member.Quantity * member.Price / classValue
+			}
+			weights[member.Symbol] += target.Weight * share
+		}
+	}
+
+	return weights, unmatched
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/logger"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/repositories"
+)
+
+// RebalanceService manages target allocations and suggests the trades that reach them
+type RebalanceService struct {
+	targetRepo       *repositories.TargetAllocationRepository
+	portfolioService *PortfolioService
+	priceManager     *provider.PriceServiceManager
+}
+
+// NewRebalanceService creates a new rebalance service
+func NewRebalanceService(
+	targetRepo *repositories.TargetAllocationRepository,
+	portfolioService *PortfolioService,
+	priceManager *provider.PriceServiceManager,
+) *RebalanceService {
+	return &RebalanceService{
+		targetRepo:       targetRepo,
+		portfolioService: portfolioService,
+		priceManager:     priceManager,
+	}
+}
+
+// GetTargets returns the target allocation of a user
+func (s *RebalanceService) GetTargets(userID uuid.UUID) ([]models.TargetAllocation, error) {
+	return s.targetRepo.GetByUserID(userID)
+}
+
+// ReplaceTargets replaces the target allocation of a user
+func (s *RebalanceService) ReplaceTargets(userID uuid.UUID, targets []models.TargetAllocation) ([]models.TargetAllocation, error) {
+	if err := s.targetRepo.ReplaceForUser(userID, targets); err != nil {
+		return nil, err
+	}
+	return s.targetRepo.GetByUserID(userID)
+}
+
+// Rebalance returns the trades that move the user's current holdings towards the saved targets,
+// using the portfolio's uninvested cash plus any extra cash to be invested
+func (s *RebalanceService) Rebalance(ctx context.Context, userID uuid.UUID, extraCash float64, rebalanceOpts RebalanceOptions, opts PortfolioOptions) (*models.RebalancePlan, error) {
+	targets, err := s.targetRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no_targets")
+	}
+
+	holdings, priceFailures, err := s.portfolioService.GetAllHoldings(ctx, userID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holdings for rebalancing: %w", err)
+	}
+	cash, err := s.portfolioService.GetCashBalance(ctx, userID, opts)
+	if err != nil {
+		return nil, err
+	}
+	baseCurrency := s.portfolioService.resolveBaseCurrency(userID)
+
+	positions := make([]RebalanceHolding, 0, len(holdings))
+	held := make(map[string]bool, len(holdings))
+	for _, holding := range holdings {
+		held[holding.Symbol] = true
+		positions = append(positions, RebalanceHolding{
+			Symbol:   holding.Symbol,
+			Quantity: holding.TotalQuantity,
+			Price:    holding.CurrentPrice,
+		})
+	}
+
+	// Holdings that could not be valued are left out rather than treated as empty positions
+	for _, failure := range priceFailures {
+		held[failure.Symbol] = true
+	}
+
+	// Symbols targeted but not yet held start from an empty position at the current price
+	var unheld []string
+	for _, target := range targets {
+		if target.Type != models.TargetTypeSymbol || held[target.Key] {
+			continue
+		}
+		held[target.Key] = true
+		unheld = append(unheld, target.Key)
+	}
+	positions = append(positions, s.newPositions(ctx, unheld, baseCurrency)...)
+
+	if hasAssetClassTargets(targets) {
+		symbols := make([]string, 0, len(positions))
+		for _, position := range positions {
+			symbols = append(symbols, position.Symbol)
+		}
+		metadata, err := s.priceManager.GetSecurityMetadata(ctx, symbols)
+		if err != nil {
+			// Asset class targets are then reported as unmatched
+			logger.Warn("Failed to get security metadata for rebalancing", logger.H{"error": err})
+			metadata = map[string]provider.SecurityMetadata{}
+		}
+		for i := range positions {
+			positions[i].AssetClass = strings.ToLower(metadata[positions[i].Symbol].AssetClass)
+		}
+	}
+
+	return BuildRebalancePlan(baseCurrency, positions, targets, cash+extraCash, rebalanceOpts, time.Now().UTC()), nil
+}
+
+// newPositions returns an empty position for each symbol, priced at its current price converted
+// into the base currency. Prices are fetched in one batch; a symbol that cannot be priced is logged
+// and left out, so its target is reported as unmatched.
+func (s *RebalanceService) newPositions(ctx context.Context, symbols []string, baseCurrency string) []RebalanceHolding {
+	if len(symbols) == 0 {
+		return nil
+	}
+
+	quotes, failures := s.priceManager.GetCurrentPriceBatch(ctx, symbols)
+	for symbol, err := range failures {
+		logger.Warn("Failed to price target symbol for rebalancing", logger.H{"symbol": symbol, "error": err})
+	}
+
+	quoteCurrencies := make(map[string]string, len(quotes))
+	var currencies []string
+	for symbol, quote := range quotes {
+		currency := strings.ToUpper(quote.Currency)
+		if currency == "" {
+			currency = baseCurrency
+		}
+		quoteCurrencies[symbol] = currency
+		currencies = append(currencies, currency)
+	}
+	if len(currencies) == 0 {
+		return nil
+	}
+	rates, err := s.priceManager.GetCurrentFXRates(ctx, baseCurrency, currencies)
+	if err != nil {
+		logger.Warn("Failed to get FX rates for target symbols", logger.H{"base_currency": baseCurrency, "error": err})
+		return nil
+	}
+
+	var positions []RebalanceHolding
+	for _, symbol := range symbols {
+		quote, ok := quotes[symbol]
+		if !ok || quote.CurrentPrice <= 0 {
+			continue
+		}
+		positions = append(positions, RebalanceHolding{Symbol: symbol, Price: quote.CurrentPrice * rates[quoteCurrencies[symbol]]})
+	}
+	return positions
+}
+
+// hasAssetClassTargets reports whether any target applies to an asset class
+func hasAssetClassTargets(targets []models.TargetAllocation) bool {
+	for _, target := range targets {
+		if target.Type == models.TargetTypeAssetClass {
+			return true
+		}
+	}
+	return false
+}
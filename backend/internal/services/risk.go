@@ -0,0 +1,100 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// tradingDaysPerYear annualizes statistics computed from daily returns
+const tradingDaysPerYear = 252
+
+// ReturnStatistics summarizes a series of daily returns. Rates are annualized fractions.
+type ReturnStatistics struct {
+	AnnualizedReturn     float64
+	AnnualizedVolatility float64
+	DownsideDeviation    float64
+	SharpeRatio          float64
+	SortinoRatio         float64
+}
+
+// CalculateReturnStatistics annualizes daily returns and measures them against an annual
+// risk-free rate. Downside deviation counts only days below the daily risk-free rate.
+func CalculateReturnStatistics(dailyReturns []float64, riskFreeRate float64) ReturnStatistics {
+	var stats ReturnStatistics
+	if len(dailyReturns) < 2 {
+		return stats
+	}
+
+	growth := 1.0
+	for _, r := range dailyReturns {
+		growth *= 1 + r
+	}
+	if growth > 0 {
+		stats.AnnualizedReturn = math.Pow(growth, tradingDaysPerYear/float64(len(dailyReturns))) - 1
+	} else {
+		stats.AnnualizedReturn = -1
+	}
+
+	dailyRiskFree := math.Pow(1+riskFreeRate, 1.0/tradingDaysPerYear) - 1
+	stats.AnnualizedVolatility = utils.StandardDeviation(dailyReturns) * math.Sqrt(tradingDaysPerYear)
+	stats.DownsideDeviation = utils.DownsideDeviation(dailyReturns, dailyRiskFree) * math.Sqrt(tradingDaysPerYear)
+
+	excessReturn := stats.AnnualizedReturn - riskFreeRate
+	if stats.AnnualizedVolatility > 0 {
+		stats.SharpeRatio = excessReturn / stats.AnnualizedVolatility
+	}
+	if stats.DownsideDeviation > 0 {
+		stats.SortinoRatio = excessReturn / stats.DownsideDeviation
+	}
+
+	return stats
+}
+
+// Beta returns the sensitivity of returns to benchmark returns over the same periods
+func Beta(returns, benchmarkReturns []float64) float64 {
+	if len(returns) < 2 || len(returns) != len(benchmarkReturns) {
+		return 0
+	}
+	benchmarkDeviation := utils.StandardDeviation(benchmarkReturns)
+	if benchmarkDeviation == 0 {
+		return 0
+	}
+	return utils.Covariance(returns, benchmarkReturns) / (benchmarkDeviation * benchmarkDeviation)
+}
+
+// PriceReturns returns the change in price between consecutive time points, skipping the periods
+// without a known price at both ends. The second result holds the index of each period's end point.
+func PriceReturns(prices *PriceSeries, timePoints []time.Time) ([]float64, []int) {
+	var returns []float64
+	var indices []int
+	for i := 1; i < len(timePoints); i++ {
+		previous, ok := prices.PriceOn(timePoints[i-1])
+		if !ok {
+			continue
+		}
+		current, ok := prices.PriceOn(timePoints[i])
+		if !ok {
+			continue
+		}
+		returns = append(returns, current/previous-1)
+		indices = append(indices, i)
+	}
+	return returns, indices
+}
+
+// tradingDays returns the weekdays between two times, each at the time of day of the end time
+func tradingDays(startTime, endTime time.Time) []time.Time {
+	var days []time.Time
+	for day := endTime; !day.Before(startTime); day = day.AddDate(0, 0, -1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		days = append(days, day)
+	}
+	for i, j := 0, len(days)-1; i < j; i, j = i+1, j-1 {
+		days[i], days[j] = days[j], days[i]
+	}
+	return days
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// snapshotDateFormat keys snapshots by their day
+const snapshotDateFormat = "2006-01-02"
+
+// SnapshotDay returns the start of the UTC day a time falls on, the day its snapshot is dated
+func SnapshotDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// endOfSnapshotDay returns the time a snapshot dated on a day values the portfolio at
+func endOfSnapshotDay(day time.Time) time.Time {
+	return SnapshotDay(day).Add(24*time.Hour - time.Second)
+}
+
+// BuildPortfolioSnapshots values the portfolio at the end of every day between two days inclusive.
+// Cost basis is that of the open lots under the given method, converted at each day's rate, and
+// net flows are the external flows of the day in the base currency.
+func BuildPortfolioSnapshots(currency string, transactions []models.Transaction, valuer *PortfolioValuer, method models.CostBasisMethod, from, to time.Time) []models.PortfolioSnapshot {
+	ordered := make([]models.Transaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].TransactionDate.Before(ordered[j].TransactionDate)
+	})
+
+	snapshots := []models.PortfolioSnapshot{}
+	bySymbol := make(map[string][]models.Transaction)
+	costs := make(map[string]float64)
+	next := 0
+	for day := SnapshotDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		end := endOfSnapshotDay(day)
+
+		// Only symbols traded up to this day need their lots replayed again
+		touched := make(map[string]bool)
+		for ; next < len(ordered) && !ordered[next].TransactionDate.After(end); next++ {
+			tx := ordered[next]
+			if tx.TradeType.IsCashMovement() || tx.Symbol == "" {
+				continue
+			}
+			bySymbol[tx.Symbol] = append(bySymbol[tx.Symbol], tx)
+			touched[tx.Symbol] = true
+		}
+		for symbol := range touched {
+			costs[symbol] = CalculateCostBasis(bySymbol[symbol], method).TotalCost
+		}
+
+		var costBasis float64
+		for symbol, cost := range costs {
+			if rate, ok := valuer.symbolRate(symbol, end); ok {
+				costBasis += cost * rate
+			}
+		}
+
+		marketValue, cash := valuer.Valuation(end)
+		previousEnd := end.Add(-24 * time.Hour)
+		netFlows := sumCashFlows(valuer.FlowsIn(previousEnd, end), previousEnd, end)
+
+		snapshots = append(snapshots, models.PortfolioSnapshot{
+			SnapshotDate: day,
+			Currency:     currency,
+			MarketValue:  utils.RoundTo4(marketValue),
+			CostBasis:    utils.RoundTo4(costBasis),
+			Cash:         utils.RoundTo4(cash),
+			NetFlows:     utils.RoundTo4(netFlows),
+		})
+	}
+
+	return snapshots
+}
+
+// SnapshotsCover reports whether the snapshots, in the given currency, hold every day between two
+// days inclusive
+func SnapshotsCover(snapshots []models.PortfolioSnapshot, currency string, from, to time.Time) bool {
+	days := make(map[string]bool, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.Currency == currency {
+			days[snapshot.SnapshotDate.Format(snapshotDateFormat)] = true
+		}
+	}
+	for day := SnapshotDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !days[day.Format(snapshotDateFormat)] {
+			return false
+		}
+	}
+	return true
+}
+
+// SnapshotTimeline values the portfolio from stored end of day snapshots up to the last of them,
+// and from a valuer after it. A time before the first snapshot is valued at nothing.
+type SnapshotTimeline struct {
+	snapshots    []models.PortfolioSnapshot
+	byDay        map[string]models.PortfolioSnapshot
+	coveredUntil time.Time
+	valuer       *PortfolioValuer
+}
+
+// NewSnapshotTimeline creates a timeline over the snapshots, valuing later times with the valuer
+func NewSnapshotTimeline(snapshots []models.PortfolioSnapshot, valuer *PortfolioValuer) *SnapshotTimeline {
+	ordered := make([]models.PortfolioSnapshot, len(snapshots))
+	copy(ordered, snapshots)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].SnapshotDate.Before(ordered[j].SnapshotDate)
+	})
+
+	timeline := &SnapshotTimeline{
+		snapshots: ordered,
+		byDay:     make(map[string]models.PortfolioSnapshot, len(ordered)),
+		valuer:    valuer,
+	}
+	for _, snapshot := range ordered {
+		timeline.byDay[snapshot.SnapshotDate.Format(snapshotDateFormat)] = snapshot
+	}
+	if len(ordered) > 0 {
+		timeline.coveredUntil = endOfSnapshotDay(ordered[len(ordered)-1].SnapshotDate)
+	}
+	return timeline
+}
+
+// ValueAt returns the value of the portfolio at a time, from the snapshot of its day when covered
+func (t *SnapshotTimeline) ValueAt(at time.Time) float64 {
+	if len(t.snapshots) == 0 || at.After(t.coveredUntil) {
+		return t.valuer.ValueAt(at)
+	}
+	return t.byDay[SnapshotDay(at).Format(snapshotDateFormat)].TotalValue()
+}
+
+// FlowsIn returns the external flows after one time and up to another. Snapshotted flows are dated
+// at the start of their day, matching the end of day value that already includes them.
+func (t *SnapshotTimeline) FlowsIn(after, upTo time.Time) []ExternalCashFlow {
+	var flows []ExternalCashFlow
+	for _, snapshot := range t.snapshots {
+		date := SnapshotDay(snapshot.SnapshotDate)
+		if snapshot.NetFlows == 0 || !date.After(after) {
+			continue
+		}
+		if date.After(upTo) {
+			break
+		}
+		flows = append(flows, ExternalCashFlow{Date: date, Currency: snapshot.Currency, Amount: snapshot.NetFlows})
+	}
+
+	if upTo.After(t.coveredUntil) {
+		from := after
+		if len(t.snapshots) > 0 && t.coveredUntil.After(from) {
+			from = t.coveredUntil
+		}
+		flows = append(flows, t.valuer.FlowsIn(from, upTo)...)
+	}
+	return flows
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/logger"
+	"github.com/transaction-tracker/backend/internal/repositories"
+)
+
+// SnapshotService keeps the daily snapshots of every user's portfolio up to date in the background
+type SnapshotService struct {
+	snapshotRepo     *repositories.PortfolioSnapshotRepository
+	transactionRepo  *repositories.TransactionRepository
+	portfolioService *PortfolioService
+
+	// generations counts the invalidations of each user, so a refresh that raced with one is
+	// discarded. mu only guards the map and is never held during database I/O.
+	mu          sync.Mutex
+	generations map[uuid.UUID]uint64
+}
+
+// NewSnapshotService creates a new snapshot service
+func NewSnapshotService(
+	snapshotRepo *repositories.PortfolioSnapshotRepository,
+	transactionRepo *repositories.TransactionRepository,
+	portfolioService *PortfolioService,
+) *SnapshotService {
+	return &SnapshotService{
+		snapshotRepo:     snapshotRepo,
+		transactionRepo:  transactionRepo,
+		portfolioService: portfolioService,
+		generations:      make(map[uuid.UUID]uint64),
+	}
+}
+
+// Start refreshes every user's snapshots right away and then at every interval, until the context
+// is done. A non-positive interval disables the job.
+func (s *SnapshotService) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		logger.Info("Portfolio snapshot job disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.RefreshAll(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RefreshAll refreshes the snapshots of every user with transactions. A failure is logged and the
+// job moves on to the next user.
+func (s *SnapshotService) RefreshAll(ctx context.Context) {
+	userIDs, err := s.transactionRepo.GetUserIDs()
+	if err != nil {
+		logger.Error("Failed to list users for portfolio snapshots", err, logger.H{})
+		return
+	}
+
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.RefreshUser(ctx, userID); err != nil {
+			logger.Warn("Failed to refresh portfolio snapshots", logger.H{
+				"user_id": userID.String(),
+				"error":   err.Error(),
+			})
+		}
+	}
+}
+
+// RefreshUser fills in a user's snapshots from the day after the latest one up to yesterday. Today
+// is never snapshotted since its closes are not final. Snapshots in a currency other than the
+// user's base currency are rebuilt from the first transaction.
+func (s *SnapshotService) RefreshUser(ctx context.Context, userID uuid.UUID) error {
+	generation := s.generation(userID)
+
+	transactions, err := s.transactionRepo.GetByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get transactions: %w", err)
+	}
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	baseCurrency := s.portfolioService.resolveBaseCurrency(userID)
+	method := s.portfolioService.resolveCostBasisMethod(userID, PortfolioOptions{})
+
+	from := SnapshotDay(transactions[0].TransactionDate)
+	latest, err := s.snapshotRepo.GetLatestByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.Currency == baseCurrency {
+		from = latest.SnapshotDate.AddDate(0, 0, 1)
+	}
+	to := SnapshotDay(time.Now()).AddDate(0, 0, -1)
+	if from.After(to) {
+		return nil
+	}
+
+	valuer := s.portfolioService.newPortfolioValuer(ctx, baseCurrency, transactions, from, endOfSnapshotDay(to))
+	snapshots := BuildPortfolioSnapshots(baseCurrency, transactions, valuer, method, from, to)
+
+	// A transaction that changed while the snapshots were computed or written discards them and the
+	// next run starts over. Its invalidation waits for this write to commit before deleting.
+	_, err = s.snapshotRepo.ReplaceFromIf(userID, from, snapshots, func() bool {
+		return s.generation(userID) == generation
+	})
+	return err
+}
+
+// InvalidateFrom deletes a user's snapshots from the day of a date affected by a transaction
+// change onwards. The job fills them in again on its next run.
+func (s *SnapshotService) InvalidateFrom(userID uuid.UUID, date time.Time) error {
+	s.mu.Lock()
+	s.generations[userID]++
+	s.mu.Unlock()
+
+	return s.snapshotRepo.DeleteFrom(userID, SnapshotDay(date))
+}
+
+// generation returns the number of times a user's snapshots have been invalidated
+func (s *SnapshotService) generation(userID uuid.UUID) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generations[userID]
+}
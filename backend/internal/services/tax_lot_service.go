@@ -0,0 +1,245 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/repositories"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// TaxLotService maintains the persisted tax lot ledger
+type TaxLotService struct {
+	transactionRepo *repositories.TransactionRepository
+	taxLotRepo      *repositories.TaxLotRepository
+	accountRepo     *repositories.AccountRepository
+	userRepo        repositories.UserRepository
+
+	// stale holds, per user, the symbols whose ledger could not be rebuilt after a write and is
+	// rebuilt on the next read instead
+	mu    sync.Mutex
+	stale map[uuid.UUID]map[string]bool
+}
+
+// NewTaxLotService creates a new tax lot service
+func NewTaxLotService(
+	transactionRepo *repositories.TransactionRepository,
+	taxLotRepo *repositories.TaxLotRepository,
+	accountRepo *repositories.AccountRepository,
+	userRepo repositories.UserRepository,
+) *TaxLotService {
+	return &TaxLotService{
+		transactionRepo: transactionRepo,
+		taxLotRepo:      taxLotRepo,
+		accountRepo:     accountRepo,
+		userRepo:        userRepo,
+		stale:           make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+// RebuildSymbols recomputes the ledger of each given symbol from the user's transactions. A symbol
+// whose ledger cannot be rebuilt is marked stale, so the next read rebuilds it.
+func (s *TaxLotService) RebuildSymbols(userID uuid.UUID, symbols []string) error {
+	method := resolveCostBasisMethod(s.userRepo, userID, "")
+
+	var firstErr error
+	seen := make(map[string]bool)
+	for _, symbol := range symbols {
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+
+		if _, err := s.rebuildSymbol(userID, symbol, method); err != nil {
+			s.setStale(userID, symbol, true)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// setStale marks or clears a symbol's ledger as needing a rebuild
+func (s *TaxLotService) setStale(userID uuid.UUID, symbol string, stale bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stale {
+		if s.stale[userID] == nil {
+			s.stale[userID] = make(map[string]bool)
+		}
+		s.stale[userID][symbol] = true
+		return
+	}
+	delete(s.stale[userID], symbol)
+	if len(s.stale[userID]) == 0 {
+		delete(s.stale, userID)
+	}
+}
+
+// isStale reports whether a symbol's ledger failed to rebuild after a write
+func (s *TaxLotService) isStale(userID uuid.UUID, symbol string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stale[userID][symbol]
+}
+
+// GetTaxLots returns the open and closed lots of a symbol under the requested cost basis method, or
+// the user's preferred one when none is requested. The ledger holds the lots of the preferred method
+// and is rebuilt when it is missing, stale or was built under a different method; lots of any other
+// method are computed on the fly.
+func (s *TaxLotService) GetTaxLots(userID uuid.UUID, symbol string, requested models.CostBasisMethod) (*models.TaxLotsResponse, error) {
+	method := resolveCostBasisMethod(s.userRepo, userID, "")
+	if requested.IsValid() && requested != method {
+		lots, err := s.calculateLots(userID, symbol, requested)
+		if err != nil {
+			return nil, err
+		}
+		if len(lots) == 0 {
+			return nil, fmt.Errorf("no transactions found for symbol %s", symbol)
+		}
+		return buildTaxLotsResponse(symbol, requested, lots), nil
+	}
+
+	lots, err := s.taxLotRepo.GetByUserIDAndSymbol(userID, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lots) == 0 || lots[0].CostBasisMethod != method || s.isStale(userID, symbol) {
+		lots, err = s.rebuildSymbol(userID, symbol, method)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(lots) == 0 {
+		return nil, fmt.Errorf("no transactions found for symbol %s", symbol)
+	}
+
+	return buildTaxLotsResponse(symbol, method, lots), nil
+}
+
+// buildTaxLotsResponse splits a symbol's lots into open and closed lots
+func buildTaxLotsResponse(symbol string, method models.CostBasisMethod, lots []models.TaxLot) *models.TaxLotsResponse {
+	response := &models.TaxLotsResponse{
+		Symbol:          symbol,
+		CostBasisMethod: method,
+		OpenLots:        []models.TaxLot{},
+		ClosedLots:      []models.TaxLot{},
+		Timestamp:       time.Now(),
+	}
+	for _, lot := range lots {
+		if lot.IsOpen() {
+			response.OpenLots = append(response.OpenLots, lot)
+		} else {
+			response.ClosedLots = append(response.ClosedLots, lot)
+		}
+	}
+
+	return response
+}
+
+// GetAccountTaxLots returns the lots of a symbol held in one account under the requested cost basis
+// method, or the user's preferred one. Lots are relieved only by sells in the same account, so they
+// are computed on the fly rather than read from the ledger.
+func (s *TaxLotService) GetAccountTaxLots(userID, accountID uuid.UUID, symbol string, requested models.CostBasisMethod) (*models.TaxLotsResponse, error) {
+	if _, err := checkAccount(s.accountRepo, userID, accountID); err != nil {
+		return nil, err
+	}
+	method := resolveCostBasisMethod(s.userRepo, userID, requested)
+
+	transactions, err := s.transactionRepo.GetByUserIDAccountIDAndSymbol(userID, accountID, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("no transactions found for symbol %s", symbol)
+	}
+
+	return buildTaxLotsResponse(symbol, method, buildLedgerLots(userID, CalculateCostBasis(transactions, method))), nil
+}
+
+// calculateLots replays a symbol's transactions through the cost basis engine
+func (s *TaxLotService) calculateLots(userID uuid.UUID, symbol string, method models.CostBasisMethod) ([]models.TaxLot, error) {
+	transactions, err := s.transactionRepo.GetByUserIDAndSymbol(userID, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions for symbol %s: %w", symbol, err)
+	}
+
+	return buildLedgerLots(userID, CalculateCostBasis(transactions, method)), nil
+}
+
+// rebuildSymbol recomputes a symbol's lots and persists them as its ledger
+func (s *TaxLotService) rebuildSymbol(userID uuid.UUID, symbol string, method models.CostBasisMethod) ([]models.TaxLot, error) {
+	lots, err := s.calculateLots(userID, symbol, method)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taxLotRepo.ReplaceForSymbol(userID, symbol, lots); err != nil {
+		return nil, fmt.Errorf("failed to rebuild tax lots for symbol %s: %w", symbol, err)
+	}
+	s.setStale(userID, symbol, false)
+
+	return lots, nil
+}
+
+// buildLedgerLots converts cost basis engine output into persistable lots with their disposals
+func buildLedgerLots(userID uuid.UUID, result CostBasisResult) []models.TaxLot {
+	disposalsByLot := make(map[uuid.UUID][]models.TaxLotDisposal)
+	for _, d := range result.Disposals {
+		disposalsByLot[d.LotTransactionID] = append(disposalsByLot[d.LotTransactionID], models.TaxLotDisposal{
+			UserID:            userID,
+			SellTransactionID: d.SellTransactionID,
+			Symbol:            d.Symbol,
+			DisposedAt:        d.SoldAt,
+			Quantity:          utils.RoundTo4(d.Quantity),
+			CostBasis:         utils.RoundTo2(d.CostBasis),
+			Proceeds:          utils.RoundTo2(d.Proceeds),
+			GainLoss:          utils.RoundTo2(d.GainLoss),
+		})
+	}
+
+	toModel := func(lot TaxLot) models.TaxLot {
+		model := models.TaxLot{
+			UserID:            userID,
+			TransactionID:     lot.TransactionID,
+			Symbol:            lot.Symbol,
+			CostBasisMethod:   result.Method,
+			AcquiredAt:        lot.AcquiredAt,
+			OriginalQuantity:  utils.RoundTo4(lot.OriginalQuantity),
+			RemainingQuantity: utils.RoundTo4(lot.Quantity),
+			UnitCost:          utils.RoundTo4(lot.UnitCost),
+			CostBasis:         utils.RoundTo2(lot.CostBasis()),
+			Disposals:         disposalsByLot[lot.TransactionID],
+		}
+		if !lot.ClosedAt.IsZero() {
+			closedAt := lot.ClosedAt
+			model.ClosedAt = &closedAt
+		}
+		if model.Disposals == nil {
+			model.Disposals = []models.TaxLotDisposal{}
+		}
+		return model
+	}
+
+	lots := make([]models.TaxLot, 0, len(result.OpenLots)+len(result.ClosedLots))
+	for _, lot := range result.ClosedLots {
+		lots = append(lots, toModel(lot))
+	}
+	for _, lot := range result.OpenLots {
+		lots = append(lots, toModel(lot))
+	}
+	sort.SliceStable(lots, func(i, j int) bool {
+		return lots[i].AcquiredAt.Before(lots[j].AcquiredAt)
+	})
+
+	return lots
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func ledgerTestTransaction(tradeType types.TradeType, quantity, price float64, date time.Time) models.Transaction {
+	return models.Transaction{
+		TransactionID:   uuid.New(),
+		Symbol:          "AAPL",
+		TradeType:       tradeType,
+		Quantity:        quantity,
+		Price:           price,
+		Amount:          quantity * price,
+		Currency:        "USD",
+		TransactionDate: date,
+	}
+}
+
+func TestBuildLedgerLots(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	userID := uuid.New()
+	transactions := []models.Transaction{
+		ledgerTestTransaction(types.TradeTypeBuy, 10, 100, day(1, 2)),
+		ledgerTestTransaction(types.TradeTypeBuy, 5, 120.12345, day(2, 1)),
+		ledgerTestTransaction(types.TradeTypeSell, 12, 150, day(3, 1)),
+		ledgerTestTransaction(types.TradeTypeBuy, 1, 130, day(4, 1)),
+	}
+
+	lots := buildLedgerLots(userID, CalculateCostBasis(transactions, models.CostBasisFIFO))
+	require.Len(t, lots, 3)
+	for i, lot := range lots {
+		assert.Equal(t, userID, lot.UserID)
+		assert.Equal(t, models.CostBasisFIFO, lot.CostBasisMethod)
+		assert.Equal(t, "AAPL", lot.Symbol)
+		// Closed and open lots are merged in acquisition order
+		assert.Equal(t, transactions[[]int{0, 1, 3}[i]].TransactionID, lot.TransactionID)
+	}
+
+	// The first lot was sold in full and is closed on the day of the sale
+	closed := lots[0]
+	require.NotNil(t, closed.ClosedAt)
+	assert.True(t, closed.ClosedAt.Equal(day(3, 1)))
+	assert.Equal(t, 10.0, closed.OriginalQuantity)
+	assert.Equal(t, 0.0, closed.RemainingQuantity)
+	assert.Equal(t, 0.0, closed.CostBasis)
+	require.Len(t, closed.Disposals, 1)
+	disposal := closed.Disposals[0]
+	assert.Equal(t, userID, disposal.UserID)
+	assert.Equal(t, transactions[2].TransactionID, disposal.SellTransactionID)
+	assert.True(t, disposal.DisposedAt.Equal(day(3, 1)))
+	assert.Equal(t, 10.0, disposal.Quantity)
+	assert.Equal(t, 1000.0, disposal.CostBasis)
+	assert.Equal(t, 1500.0, disposal.Proceeds)
+	assert.Equal(t, 500.0, disposal.GainLoss)
+
+	// The second lot was partly sold; quantities and unit costs are rounded to the 4 decimals they
+	// are stored with, and money to cents
+	partial := lots[1]
+	assert.Nil(t, partial.ClosedAt)
+	assert.Equal(t, 5.0, partial.OriginalQuantity)
+	assert.Equal(t, 3.0, partial.RemainingQuantity)
+	assert.Equal(t, 120.1235, partial.UnitCost)
+	assert.Equal(t, 360.37, partial.CostBasis)
+	require.Len(t, partial.Disposals, 1)
+	assert.Equal(t, 2.0, partial.Disposals[0].Quantity)
+	assert.Equal(t, 240.25, partial.Disposals[0].CostBasis)
+	assert.Equal(t, 59.75, partial.Disposals[0].GainLoss)
+
+	// A lot without disposals serializes them as an empty list
+	assert.Nil(t, lots[2].ClosedAt)
+	assert.NotNil(t, lots[2].Disposals)
+	assert.Empty(t, lots[2].Disposals)
+}
@@ -1,12 +1,15 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/logger"
 	"github.com/transaction-tracker/backend/internal/models"
 	"github.com/transaction-tracker/backend/internal/repositories"
+	"github.com/transaction-tracker/backend/internal/types"
 )
 
 // TransactionFilter represents filters for transaction queries
@@ -27,27 +30,198 @@ type TransactionFilter struct {
 	OrderDirection string
 }
 
+// ErrLinkedTransaction is returned when one half of a reinvested dividend pair is edited on its own
+var ErrLinkedTransaction = errors.New("transaction is part of a reinvested dividend")
+
 // TransactionService handles transaction-related business logic
 type TransactionService struct {
 	transactionRepo *repositories.TransactionRepository
+	taxLotService   *TaxLotService
+	accountRepo     *repositories.AccountRepository
+	snapshotService *SnapshotService
 }
 
 // NewTransactionService creates a new transaction service
-func NewTransactionService(transactionRepo *repositories.TransactionRepository) *TransactionService {
+func NewTransactionService(transactionRepo *repositories.TransactionRepository, taxLotService *TaxLotService, accountRepo *repositories.AccountRepository, snapshotService *SnapshotService) *TransactionService {
 	return &TransactionService{
 		transactionRepo: transactionRepo,
+		taxLotService:   taxLotService,
+		accountRepo:     accountRepo,
+		snapshotService: snapshotService,
+	}
+}
+
+// syncTaxLots rebuilds the tax lot ledger of the symbols touched by a write.
+// The ledger is derived data, so a failure is logged rather than failing the write; the
+// symbols that failed are rebuilt on their next read.
+func (s *TransactionService) syncTaxLots(userID uuid.UUID, symbols ...string) {
+	if s.taxLotService == nil {
+		return
+	}
+	if err := s.taxLotService.RebuildSymbols(userID, symbols); err != nil {
+		logger.Warn("Failed to rebuild tax lot ledger", logger.H{
+			"user_id": userID.String(),
+			"symbols": symbols,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// invalidateSnapshots drops the portfolio snapshots from the earliest date touched by a write.
+// Snapshots are derived data, so a failure is logged rather than failing the write.
+func (s *TransactionService) invalidateSnapshots(userID uuid.UUID, transactions ...models.Transaction) {
+	if s.snapshotService == nil || len(transactions) == 0 {
+		return
+	}
+	earliest := transactions[0].TransactionDate
+	for _, tx := range transactions[1:] {
+		if tx.TransactionDate.Before(earliest) {
+			earliest = tx.TransactionDate
+		}
 	}
+	if err := s.snapshotService.InvalidateFrom(userID, earliest); err != nil {
+		logger.Warn("Failed to invalidate portfolio snapshots", logger.H{
+			"user_id": userID.String(),
+			"from":    earliest,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// CreateTransactionsResult holds the outcome of a batch create
+type CreateTransactionsResult struct {
+	Created []models.Transaction
+	// DuplicateOf holds, for each given transaction, the ID of an existing transaction with the same
+	// fingerprint, or nil
+	DuplicateOf []*uuid.UUID
+	Skipped     int // possible duplicates that were not created
 }
 
-// CreateTransactions creates multiple transactions in a batch (business logic)
-func (s *TransactionService) CreateTransactions(userID uuid.UUID, transactions []models.Transaction) ([]models.Transaction, error) {
+// CreateTransactions creates multiple transactions in a batch (business logic). Each transaction is
+// checked against the user's existing transactions by TransactionFingerprint; with skipDuplicates
+// the possible duplicates are not created, so importing the same statement twice is harmless.
+func (s *TransactionService) CreateTransactions(userID uuid.UUID, transactions []models.Transaction, skipDuplicates bool) (*CreateTransactionsResult, error) {
 	// Set user ID for each transaction (business logic)
 	for i := range transactions {
 		transactions[i].UserID = userID
+		if transactions[i].Symbol == "" && transactions[i].TradeType.IsCashMovement() {
+			transactions[i].Symbol = types.CashSymbol
+		}
+
+		// Transactions may only reference the user's own accounts
+		if transactions[i].AccountID != nil {
+			account, err := checkAccount(s.accountRepo, userID, *transactions[i].AccountID)
+			if err != nil {
+				return nil, err
+			}
+			if transactions[i].Broker == "" {
+				transactions[i].Broker = account.Broker
+			}
+		}
+	}
+
+	duplicateOf, err := s.findDuplicates(userID, transactions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicate transactions: %w", err)
+	}
+	result := &CreateTransactionsResult{Created: []models.Transaction{}, DuplicateOf: duplicateOf}
+	if skipDuplicates {
+		kept := make([]models.Transaction, 0, len(transactions))
+		for i, tx := range transactions {
+			if duplicateOf[i] != nil {
+				result.Skipped++
+				continue
+			}
+			kept = append(kept, tx)
+		}
+		transactions = kept
+	}
+	if len(transactions) == 0 {
+		return result, nil
 	}
 
+	// Reinvested dividends become a Dividends and a Buy row, created in the same database transaction
+	transactions = ExpandReinvestedDividends(transactions)
+
 	// Delegate to repository for database operations
-	return s.transactionRepo.CreateMany(transactions)
+	created, err := s.transactionRepo.CreateMany(transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(created))
+	for _, tx := range created {
+		symbols = append(symbols, tx.Symbol)
+	}
+	s.syncTaxLots(userID, symbols...)
+	s.invalidateSnapshots(userID, created...)
+
+	result.Created = created
+	return result, nil
+}
+
+// ExpandReinvestedDividends replaces every DividendReinvestment with the cash dividend received
+// and the purchase of the reinvested shares. The Buy links back to the dividend, and the two
+// cash flows offset each other so the reinvested cash is only counted once as income.
+func ExpandReinvestedDividends(transactions []models.Transaction) []models.Transaction {
+	expanded := make([]models.Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		if tx.TradeType != types.TradeTypeDividendReinvestment {
+			expanded = append(expanded, tx)
+			continue
+		}
+
+		dividend := tx
+		dividend.TransactionID = uuid.New()
+		dividend.TradeType = types.TradeTypeDividend
+		dividend.Quantity = 0
+		dividend.Price = 0
+
+		purchase := tx
+		purchase.TransactionID = uuid.New()
+		purchase.TradeType = types.TradeTypeBuy
+		purchase.LinkedTransactionID = &dividend.TransactionID
+
+		expanded = append(expanded, dividend, purchase)
+	}
+	return expanded
+}
+
+// withLinkedTransactions returns the given transactions plus the other half of any reinvested dividend pair
+func (s *TransactionService) withLinkedTransactions(userID uuid.UUID, transactions []models.Transaction) ([]models.Transaction, error) {
+	seen := make(map[uuid.UUID]bool)
+	var ids, partnerIDs []uuid.UUID
+	for _, tx := range transactions {
+		seen[tx.TransactionID] = true
+		ids = append(ids, tx.TransactionID)
+		if tx.LinkedTransactionID != nil {
+			partnerIDs = append(partnerIDs, *tx.LinkedTransactionID)
+		}
+	}
+
+	partners, err := s.transactionRepo.GetByIDsAndUserID(partnerIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	linked, err := s.transactionRepo.GetLinkedByIDsAndUserID(ids, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	group := transactions
+	for _, tx := range append(partners, linked...) {
+		if !seen[tx.TransactionID] {
+			seen[tx.TransactionID] = true
+			group = append(group, tx)
+		}
+	}
+	return group, nil
+}
+
+// GetImportedIDs maps the external IDs among the given ones that the user has already imported to
+// the transactions they were imported as
+func (s *TransactionService) GetImportedIDs(userID uuid.UUID, externalIDs []string) (map[string]uuid.UUID, error) {
+	return s.transactionRepo.GetIDsByExternalIDs(userID, externalIDs)
 }
 
 // GetTransactionsWithFilter retrieves transactions with advanced filtering (business logic method)
@@ -70,6 +244,26 @@ func (s *TransactionService) GetTransactionsWithFilter(filter TransactionFilter)
 	)
 }
 
+// StreamTransactionsWithFilter calls fn with each transaction matching the filter, without loading
+// them all; the filter's limit and offset are ignored
+func (s *TransactionService) StreamTransactionsWithFilter(filter TransactionFilter, fn func(models.Transaction) error) error {
+	return s.transactionRepo.StreamWithFilters(
+		filter.UserID,
+		filter.Symbols,
+		filter.TradeTypes,
+		filter.Exchanges,
+		filter.Brokers,
+		filter.Currencies,
+		filter.StartDate,
+		filter.EndDate,
+		filter.MinAmount,
+		filter.MaxAmount,
+		filter.OrderBy,
+		filter.OrderDirection,
+		fn,
+	)
+}
+
 // CountTransactions returns the count of transactions based on filter
 func (s *TransactionService) CountTransactions(filter TransactionFilter) (int64, error) {
 	return s.transactionRepo.CountWithFilters(
@@ -86,8 +280,10 @@ func (s *TransactionService) CountTransactions(filter TransactionFilter) (int64,
 	)
 }
 
-// UpdateTransaction updates a transaction by ID for a specific user
-func (s *TransactionService) UpdateTransaction(userID uuid.UUID, transactionID uuid.UUID, symbol, exchange, broker, currency, tradeDate string, tradeType string, quantity, price, amount float64, userNotes string) (*models.Transaction, error) {
+// UpdateTransaction updates a transaction by ID for a specific user. The two halves of a reinvested
+// dividend offset each other's cash flows, so neither can be edited on its own; the pair is deleted
+// and created again instead.
+func (s *TransactionService) UpdateTransaction(userID uuid.UUID, transactionID uuid.UUID, symbol, exchange, broker, currency, tradeDate string, tradeType string, quantity, price, amount float64, userNotes string, lotSelections []types.LotSelection, splitRatio float64, accountID *uuid.UUID) (*models.Transaction, error) {
 	// Get transaction and check ownership
 	tx, err := s.transactionRepo.GetByID(transactionID)
 	if err != nil {
@@ -97,6 +293,28 @@ func (s *TransactionService) UpdateTransaction(userID uuid.UUID, transactionID u
 		return nil, fmt.Errorf("forbidden")
 	}
 
+	group, err := s.withLinkedTransactions(userID, []models.Transaction{*tx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked transactions: %w", err)
+	}
+	if len(group) > 1 {
+		return nil, ErrLinkedTransaction
+	}
+
+	if symbol == "" && types.TradeType(tradeType).IsCashMovement() {
+		symbol = types.CashSymbol
+	}
+
+	if accountID != nil {
+		account, err := checkAccount(s.accountRepo, userID, *accountID)
+		if err != nil {
+			return nil, err
+		}
+		if broker == "" {
+			broker = account.Broker
+		}
+	}
+
 	// Prepare updates
 	updates := map[string]interface{}{
 		"symbol":           symbol,
@@ -109,6 +327,9 @@ func (s *TransactionService) UpdateTransaction(userID uuid.UUID, transactionID u
 		"price":            price,
 		"amount":           amount,
 		"user_notes":       userNotes,
+		"lot_selections":   models.LotSelections(lotSelections),
+		"split_ratio":      splitRatio,
+		"account_id":       accountID,
 	}
 
 	// Update transaction
@@ -116,8 +337,17 @@ func (s *TransactionService) UpdateTransaction(userID uuid.UUID, transactionID u
 		return nil, err
 	}
 
+	s.syncTaxLots(userID, tx.Symbol, symbol)
+
 	// Return updated transaction
-	return s.transactionRepo.GetByID(transactionID)
+	updated, err := s.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The portfolio changes from whichever of the old and new dates comes first
+	s.invalidateSnapshots(userID, *tx, *updated)
+	return updated, nil
 }
 
 // DeleteTransaction deletes a transaction by ID for a specific user
@@ -131,12 +361,66 @@ func (s *TransactionService) DeleteTransaction(userID uuid.UUID, transactionID u
 		return fmt.Errorf("forbidden")
 	}
 
-	// Delete the transaction
-	return s.transactionRepo.DeleteByIDAndUserID(transactionID, userID)
+	// A reinvested dividend is deleted together with its paired transaction
+	group, err := s.withLinkedTransactions(userID, []models.Transaction{*tx})
+	if err != nil {
+		return fmt.Errorf("failed to get linked transactions: %w", err)
+	}
+	if len(group) == 1 {
+		if err := s.transactionRepo.DeleteByIDAndUserID(transactionID, userID); err != nil {
+			return err
+		}
+	} else {
+		ids := make([]uuid.UUID, 0, len(group))
+		for _, member := range group {
+			ids = append(ids, member.TransactionID)
+		}
+		if _, err := s.transactionRepo.DeleteByIDsAndUserID(ids, userID); err != nil {
+			return err
+		}
+	}
+
+	s.syncTaxLots(userID, tx.Symbol)
+	s.invalidateSnapshots(userID, group...)
+	return nil
 }
 
 // DeleteTransactions deletes multiple transactions by IDs for a specific user
 func (s *TransactionService) DeleteTransactions(userID uuid.UUID, transactionIDs []uuid.UUID) ([]uuid.UUID, error) {
+	// Look up symbols before deleting so their tax lots can be rebuilt afterwards
+	existing, err := s.transactionRepo.GetByIDsAndUserID(transactionIDs, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	// A reinvested dividend is deleted together with its paired transaction
+	existing, err = s.withLinkedTransactions(userID, existing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked transactions: %w", err)
+	}
+	requested := make(map[uuid.UUID]bool, len(transactionIDs))
+	for _, id := range transactionIDs {
+		requested[id] = true
+	}
+	for _, tx := range existing {
+		if !requested[tx.TransactionID] {
+			requested[tx.TransactionID] = true
+			transactionIDs = append(transactionIDs, tx.TransactionID)
+		}
+	}
+
 	// Use repository method that handles batch deletion with ownership checks
-	return s.transactionRepo.DeleteByIDsAndUserID(transactionIDs, userID)
+	deletedIDs, err := s.transactionRepo.DeleteByIDsAndUserID(transactionIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(existing))
+	for _, tx := range existing {
+		symbols = append(symbols, tx.Symbol)
+	}
+	s.syncTaxLots(userID, symbols...)
+	s.invalidateSnapshots(userID, existing...)
+
+	return deletedIDs, nil
 }
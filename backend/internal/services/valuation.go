@@ -0,0 +1,237 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+// valueTimeline values the portfolio over time and reports the external flows between valuations,
+// both in the base currency
+type valueTimeline interface {
+	ValueAt(at time.Time) float64
+	FlowsIn(after, upTo time.Time) []ExternalCashFlow
+}
+
+// PortfolioValuer values a portfolio, cash included, from prefetched daily closes. Positions and
+// cash are built up incrementally as valuation times advance, so valuing a series of increasing
+// times replays every transaction once; an earlier time starts the replay over.
+type PortfolioValuer struct {
+	transactions []models.Transaction
+	prices       map[string]*PriceSeries
+	converter    *fxConverter
+	tracked      map[string]bool
+	// loadPrices, when set, fetches the prices on their first use
+	loadPrices func() map[string]*PriceSeries
+	flows      []ExternalCashFlow
+
+	next       int
+	valuedAt   time.Time
+	positions  map[string]float64
+	lastTrades map[string]float64
+	cash       map[cashBalanceKey]float64
+}
+
+// NewPortfolioValuer creates a valuer over the given transactions and daily closes keyed by symbol.
+// A nil converter values everything as if it were already in the base currency.
+func NewPortfolioValuer(transactions []models.Transaction, prices map[string]*PriceSeries, converter *fxConverter) *PortfolioValuer {
+	ordered := make([]models.Transaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].TransactionDate.Before(ordered[j].TransactionDate)
+	})
+
+	valuer := &PortfolioValuer{
+		transactions: ordered,
+		prices:       prices,
+		converter:    converter,
+		tracked:      cashTrackedBrokers(ordered),
+		flows:        ExternalCashFlows(ordered),
+	}
+	valuer.reset()
+	return valuer
+}
+
+// reset clears the replayed state so valuation starts again from the first transaction
+func (v *PortfolioValuer) reset() {
+	v.next = 0
+	v.valuedAt = time.Time{}
+	v.positions = make(map[string]float64)
+	v.lastTrades = make(map[string]float64)
+	v.cash = make(map[cashBalanceKey]float64)
+}
+
+// ValueAt returns the value of the portfolio in the base currency at a time. Each position is priced
+// at the latest close on or before that day, carrying closes over weekends and holidays; a symbol
+// without any close yet falls back to its latest trade price.
+func (v *PortfolioValuer) ValueAt(at time.Time) float64 {
+	marketValue, cash := v.Valuation(at)
+	return marketValue + cash
+}
+
+// Valuation returns the market value of the positions and the tracked cash, in the base currency, at a time
+func (v *PortfolioValuer) Valuation(at time.Time) (marketValue, cash float64) {
+	if at.Before(v.valuedAt) {
+		v.reset()
+	}
+	v.advance(at)
+
+	for key, balance := range v.cash {
+		if rate, ok := v.currencyRate(key.currency, at); ok {
+			cash += balance * rate
+		}
+	}
+
+	for symbol, quantity := range v.positions {
+		if quantity <= 0 {
+			continue
+		}
+		price, ok := v.priceOn(symbol, at)
+		if !ok {
+			continue
+		}
+		if rate, ok := v.symbolRate(symbol, at); ok {
+			marketValue += quantity * price * rate
+		}
+	}
+
+	return marketValue, cash
+}
+
+// Position returns the quantity held of a symbol at a time and the price it is valued at, in the
+// symbol's own currency
+func (v *PortfolioValuer) Position(symbol string, at time.Time) (quantity, price float64) {
+	if at.Before(v.valuedAt) {
+		v.reset()
+	}
+	v.advance(at)
+
+	price, _ = v.priceOn(symbol, at)
+	return v.positions[symbol], price
+}
+
+// FlowsIn returns the external flows dated after one time and up to another, converted into the
+// base currency at the rate of their day. Flows in a currency without a rate are left out.
+func (v *PortfolioValuer) FlowsIn(after, upTo time.Time) []ExternalCashFlow {
+	var flows []ExternalCashFlow
+	for _, flow := range v.flows {
+		if !flow.Date.After(after) {
+			continue
+		}
+		if flow.Date.After(upTo) {
+			break
+		}
+		rate, ok := v.currencyRate(flow.Currency, flow.Date)
+		if !ok {
+			continue
+		}
+		if v.converter != nil {
+			flow.Currency = v.converter.baseCurrency
+		}
+		flow.Amount *= rate
+		flows = append(flows, flow)
+	}
+	return flows
+}
+
+// advance applies every transaction dated up to a time to the positions and cash balances
+func (v *PortfolioValuer) advance(at time.Time) {
+	for ; v.next < len(v.transactions) && !v.transactions[v.next].TransactionDate.After(at); v.next++ {
+		tx := v.transactions[v.next]
+
+		if v.tracked[tx.Broker] {
+			v.cash[cashBalanceKey{broker: tx.Broker, currency: strings.ToUpper(tx.Currency)}] += CashFlowAmount(tx)
+		}
+		if tx.TradeType.IsCashMovement() {
+			continue
+		}
+
+		v.positions[tx.Symbol] = ApplyToPosition(v.positions[tx.Symbol], tx)
+		switch tx.TradeType {
+		case types.TradeTypeBuy, types.TradeTypeSell:
+			if tx.Price > 0 {
+				v.lastTrades[tx.Symbol] = tx.Price
+			}
+		case types.TradeTypeSplit:
+			if tx.SplitRatio > 0 {
+				v.lastTrades[tx.Symbol] /= tx.SplitRatio
+			}
+		}
+	}
+	v.valuedAt = at
+}
+
+// priceOn returns the close of a symbol on or before a day, or its latest trade price
+func (v *PortfolioValuer) priceOn(symbol string, at time.Time) (float64, bool) {
+	if v.loadPrices != nil {
+		v.prices = v.loadPrices()
+		v.loadPrices = nil
+	}
+	if series, ok := v.prices[symbol]; ok {
+		if price, found := series.PriceOn(at); found {
+			return price, true
+		}
+	}
+	price, ok := v.lastTrades[symbol]
+	return price, ok && price > 0
+}
+
+// currencyRate returns the rate converting a currency into the base currency on a day
+func (v *PortfolioValuer) currencyRate(currency string, at time.Time) (float64, bool) {
+	if v.converter == nil {
+		return 1, true
+	}
+	return v.converter.rateForCurrency(currency, at)
+}
+
+// symbolRate returns the rate converting a symbol's currency into the base currency on a day
+func (v *PortfolioValuer) symbolRate(symbol string, at time.Time) (float64, bool) {
+	if v.converter == nil {
+		return 1, true
+	}
+	return v.converter.rateFor(symbol, at)
+}
+
+// HeldSymbols returns the symbols with an open position at some point between two times, sorted
+func HeldSymbols(transactions []models.Transaction, startTime, endTime time.Time) []string {
+	ordered := make([]models.Transaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].TransactionDate.Before(ordered[j].TransactionDate)
+	})
+
+	positions := make(map[string]float64)
+	held := make(map[string]bool)
+	for _, tx := range ordered {
+		if tx.TransactionDate.After(endTime) {
+			break
+		}
+		if tx.TradeType.IsCashMovement() || tx.Symbol == "" {
+			continue
+		}
+		// A position is held in the window when it is open just before or after a transaction
+		// inside it, or still open at the end; one closed before the start never is
+		if tx.TransactionDate.After(startTime) && positions[tx.Symbol] > lotQuantityEpsilon {
+			held[tx.Symbol] = true
+		}
+		positions[tx.Symbol] = ApplyToPosition(positions[tx.Symbol], tx)
+		if tx.TransactionDate.After(startTime) && positions[tx.Symbol] > lotQuantityEpsilon {
+			held[tx.Symbol] = true
+		}
+	}
+	for symbol, quantity := range positions {
+		if quantity > lotQuantityEpsilon {
+			held[symbol] = true
+		}
+	}
+
+	symbols := make([]string, 0, len(held))
+	for symbol := range held {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/types"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// washSaleWindowDays is how many calendar days before or after a loss sale a purchase counts as a replacement
+const washSaleWindowDays = 30
+
+// withinWashSaleWindow reports whether a purchase falls within the wash sale window of a sale.
+// Like the holding period, the window is counted in calendar days, whatever the time of day.
+func withinWashSaleWindow(boughtAt, soldAt time.Time) bool {
+	bought := time.Date(boughtAt.Year(), boughtAt.Month(), boughtAt.Day(), 0, 0, 0, 0, time.UTC)
+	sold := time.Date(soldAt.Year(), soldAt.Month(), soldAt.Day(), 0, 0, 0, 0, time.UTC)
+	return !bought.Before(sold.AddDate(0, 0, -washSaleWindowDays)) && !bought.After(sold.AddDate(0, 0, washSaleWindowDays))
+}
+
+// WashSaleAdjustment records a loss disallowed by a wash sale and the replacement lot that absorbed it
+type WashSaleAdjustment struct {
+	SellTransactionID        uuid.UUID
+	LotTransactionID         uuid.UUID // lot sold at a loss
+	ReplacementTransactionID uuid.UUID // Buy whose basis was increased
+	Quantity                 float64
+	DisallowedLoss           float64
+}
+
+// washSaleTracker matches loss disposals against purchases within the wash sale window
+type washSaleTracker struct {
+	buys []models.Transaction
+	// unmatched replacement shares left on each Buy
+	available map[uuid.UUID]float64
+	// disallowed losses waiting for a Buy that has not been replayed yet
+	pending map[uuid.UUID]float64
+}
+
+// newWashSaleTracker creates a tracker over the date ordered transactions of a single symbol
+func newWashSaleTracker(ordered []models.Transaction) *washSaleTracker {
+	t := &washSaleTracker{
+		available: make(map[uuid.UUID]float64),
+		pending:   make(map[uuid.UUID]float64),
+	}
+	for _, tx := range ordered {
+		if tx.TradeType == types.TradeTypeBuy {
+			t.buys = append(t.buys, tx)
+			t.available[tx.TransactionID] = tx.Quantity
+		}
+	}
+	return t
+}
+
+// applySplit scales the unmatched replacement shares of purchases made before a split
+func (t *washSaleTracker) applySplit(split models.Transaction, ratio float64) {
+	if ratio <= 0 {
+		return
+	}
+	for _, buy := range t.buys {
+		if !buy.TransactionDate.After(split.TransactionDate) {
+			t.available[buy.TransactionID] *= ratio
+		}
+	}
+}
+
+// takePending returns and clears the disallowed loss to add to a Buy's basis
+func (t *washSaleTracker) takePending(buyID uuid.UUID) float64 {
+	adjustment := t.pending[buyID]
+	delete(t.pending, buyID)
+	return adjustment
+}
+
+// apply flags the loss disposals of a Sell, disallowing the loss on shares replaced within the window.
+// Replacement lots already held get their unit cost raised immediately, later purchases when replayed.
+func (t *washSaleTracker) apply(lots []TaxLot, sell models.Transaction, disposals []LotDisposal) []WashSaleAdjustment {
+	var adjustments []WashSaleAdjustment
+
+	for i := range disposals {
+		d := &disposals[i]
+		if d.GainLoss >= 0 || d.Quantity <= lotQuantityEpsilon {
+			continue
+		}
+
+		unitLoss := -d.GainLoss / d.Quantity
+		unmatched := d.Quantity
+
+		for _, buy := range t.buys {
+			if unmatched <= lotQuantityEpsilon {
+				break
+			}
+			if buy.TransactionID == d.LotTransactionID {
+				continue
+			}
+			if !withinWashSaleWindow(buy.TransactionDate, sell.TransactionDate) {
+				continue
+			}
+
+			available := t.available[buy.TransactionID]
+			lotIdx := -1
+			if !buy.TransactionDate.After(sell.TransactionDate) {
+				// An earlier purchase only replaces shares that are still held after the sale
+				lotIdx = findLot(lots, buy.TransactionID)
+				if lotIdx < 0 {
+					continue
+				}
+				if lots[lotIdx].Quantity < available {
+					available = lots[lotIdx].Quantity
+				}
+			}
+			if available <= lotQuantityEpsilon {
+				continue
+			}
+
+			matched := unmatched
+			if matched > available {
+				matched = available
+			}
+			disallowed := matched * unitLoss
+
+			if lotIdx >= 0 {
+				lots[lotIdx].UnitCost += disallowed / lots[lotIdx].Quantity
+			} else {
+				t.pending[buy.TransactionID] += disallowed
+			}
+
+			t.available[buy.TransactionID] -= matched
+			unmatched -= matched
+
+			d.WashSale = true
+			d.DisallowedLoss += disallowed
+			d.GainLoss += disallowed
+			d.ReplacementTransactionIDs = append(d.ReplacementTransactionIDs, buy.TransactionID)
+
+			adjustments = append(adjustments, WashSaleAdjustment{
+				SellTransactionID:        sell.TransactionID,
+				LotTransactionID:         d.LotTransactionID,
+				ReplacementTransactionID: buy.TransactionID,
+				Quantity:                 matched,
+				DisallowedLoss:           disallowed,
+			})
+		}
+	}
+
+	return adjustments
+}
+
+// findLot returns the index of the open lot created by a Buy transaction, or -1
+func findLot(lots []TaxLot, transactionID uuid.UUID) int {
+	for i := range lots {
+		if lots[i].TransactionID == transactionID && lots[i].Quantity > lotQuantityEpsilon {
+			return i
+		}
+	}
+	return -1
+}
+
+// BuildWashSaleFlags converts wash sale adjustments into per-transaction flags for both
+// the loss sale and the replacement purchase
+func BuildWashSaleFlags(adjustments []WashSaleAdjustment) map[uuid.UUID]types.WashSaleFlag {
+	flags := make(map[uuid.UUID]types.WashSaleFlag)
+
+	add := func(id uuid.UUID, role types.WashSaleRole, amount float64, related uuid.UUID) {
+		flag, ok := flags[id]
+		if !ok {
+			flag = types.WashSaleFlag{Role: role, RelatedTransactionIDs: []string{}}
+		}
+		flag.Amount = utils.RoundTo4(flag.Amount + amount)
+		relatedID := related.String()
+		for _, existing := range flag.RelatedTransactionIDs {
+			if existing == relatedID {
+				relatedID = ""
+				break
+			}
+		}
+		if relatedID != "" {
+			flag.RelatedTransactionIDs = append(flag.RelatedTransactionIDs, relatedID)
+		}
+		flags[id] = flag
+	}
+
+	for _, adj := range adjustments {
+		add(adj.SellTransactionID, types.WashSaleRoleLossSale, adj.DisallowedLoss, adj.ReplacementTransactionID)
+		add(adj.ReplacementTransactionID, types.WashSaleRoleReplacement, adj.DisallowedLoss, adj.SellTransactionID)
+	}
+
+	return flags
+}
@@ -11,13 +11,85 @@ const (
 	TradeTypeBuy      TradeType = "Buy"
 	TradeTypeSell     TradeType = "Sell"
 	TradeTypeDividend TradeType = "Dividends"
+	// TradeTypeSplit is a stock split or reverse split corporate action
+	TradeTypeSplit TradeType = "Split"
+	// TradeTypeDividendReinvestment is accepted on create only and is stored as a
+	// Dividends transaction paired with the Buy of the reinvested shares
+	TradeTypeDividendReinvestment TradeType = "DividendReinvestment"
+
+	// Cash movements change a broker account's cash balance without trading shares
+	TradeTypeDeposit    TradeType = "Deposit"
+	TradeTypeWithdrawal TradeType = "Withdrawal"
+	TradeTypeFee        TradeType = "Fee"
+	TradeTypeInterest   TradeType = "Interest"
+	TradeTypeTax        TradeType = "Tax"
+)
+
+// TradeTypes lists the trade types transactions are stored with. DividendReinvestment is not one
+// of them, as it is only accepted on create.
+var TradeTypes = []TradeType{
+	TradeTypeBuy,
+	TradeTypeSell,
+	TradeTypeDividend,
+	TradeTypeSplit,
+	TradeTypeDeposit,
+	TradeTypeWithdrawal,
+	TradeTypeFee,
+	TradeTypeInterest,
+	TradeTypeTax,
+}
+
+// IsValid reports whether the trade type is one transactions are stored with
+func (t TradeType) IsValid() bool {
+	for _, tradeType := range TradeTypes {
+		if t == tradeType {
+			return true
+		}
+	}
+	return false
+}
+
+// CashSymbol is stored as the symbol of cash movements that do not relate to a security
+const CashSymbol = "$CASH"
+
+// IsCashMovement reports whether the trade type only moves cash
+func (t TradeType) IsCashMovement() bool {
+	switch t {
+	case TradeTypeDeposit, TradeTypeWithdrawal, TradeTypeFee, TradeTypeInterest, TradeTypeTax:
+		return true
+	default:
+		return false
+	}
+}
+
+// LotSelection identifies an acquisition lot (by its Buy transaction ID) and the
+// quantity a Sell should relieve from it under specific lot identification
+type LotSelection struct {
+	TransactionID string  `json:"transaction_id"`
+	Quantity      float64 `json:"quantity"`
+}
+
+// WashSaleRole identifies which side of a wash sale a transaction is on
+type WashSaleRole string
+
+const (
+	WashSaleRoleLossSale    WashSaleRole = "loss_sale"   // Sell whose loss was disallowed
+	WashSaleRoleReplacement WashSaleRole = "replacement" // Buy whose basis absorbed the disallowed loss
 )
 
+// WashSaleFlag marks a transaction involved in a wash sale
+type WashSaleFlag struct {
+	Role                  WashSaleRole `json:"role"`
+	Amount                float64      `json:"amount"` // disallowed loss on a sale, basis increase on a replacement
+	RelatedTransactionIDs []string     `json:"related_transaction_ids"`
+}
+
 // ExtractResponseData represents the data part of extract response
 type ExtractResponseData struct {
 	Transactions     []TransactionData `json:"transactions"`
 	TransactionCount int               `json:"transaction_count"`
 	FileName         string            `json:"file_name"`
+	PageCount        int               `json:"page_count,omitempty"` // pages of an extracted PDF
 }
 
 // ExtractResponse represents the response from AI model
@@ -30,17 +102,24 @@ type ExtractResponse struct {
 // TransactionData represents extracted transaction information from AI
 // Uses fields that map to the Transaction model structure
 type TransactionData struct {
-	ID              string    `json:"transaction_id"`   // Unique identifier for frontend/backend sync
-	Symbol          string    `json:"symbol"`           // Maps to Transaction.Symbol
-	TradeType       TradeType `json:"trade_type"`       // Maps to Transaction.Type
-	Quantity        float64   `json:"quantity"`         // Maps to Transaction.Quantity
-	Price           float64   `json:"price"`            // Maps to Transaction.Price
-	Amount          float64   `json:"amount"`           // Maps to Transaction.Amount
-	Currency        string    `json:"currency"`         // Maps to Transaction.Currency
-	Broker          string    `json:"broker"`           // Maps to Transaction.Broker
-	TransactionDate string    `json:"transaction_date"` // Maps to Transaction.TransactionDate (as string for JSON)
-	UserNotes       string    `json:"user_notes"`       // Maps to Transaction.UserNotes
-	Exchange        string    `json:"exchange"`         // Maps to Transaction.Exchange
+	ID              string         `json:"transaction_id"`                  // Unique identifier for frontend/backend sync
+	Symbol          string         `json:"symbol"`                          // Maps to Transaction.Symbol
+	TradeType       TradeType      `json:"trade_type"`                      // Maps to Transaction.Type
+	Quantity        float64        `json:"quantity"`                        // Maps to Transaction.Quantity
+	Price           float64        `json:"price"`                           // Maps to Transaction.Price
+	Amount          float64        `json:"amount"`                          // Maps to Transaction.Amount
+	Currency        string         `json:"currency"`                        // Maps to Transaction.Currency
+	Broker          string         `json:"broker"`                          // Maps to Transaction.Broker
+	AccountID       string         `json:"account_id,omitempty"`            // Maps to Transaction.AccountID
+	TransactionDate string         `json:"transaction_date"`                // Maps to Transaction.TransactionDate (as string for JSON)
+	UserNotes       string         `json:"user_notes"`                      // Maps to Transaction.UserNotes
+	Exchange        string         `json:"exchange"`                        // Maps to Transaction.Exchange
+	LotSelections   []LotSelection `json:"lot_selections,omitempty"`        // Maps to Transaction.LotSelections
+	SplitRatio      float64        `json:"split_ratio,omitempty"`           // Maps to Transaction.SplitRatio
+	LinkedID        string         `json:"linked_transaction_id,omitempty"` // Maps to Transaction.LinkedTransactionID
+	ExternalID      string         `json:"external_id,omitempty"`           // Maps to Transaction.ExternalID
+	Page            int            `json:"page,omitempty"`                  // Page of the PDF the transaction was extracted from
+	WashSale        *WashSaleFlag  `json:"wash_sale,omitempty"`             // Derived, set on transaction history entries
 }
 
 // FileInput represents an image file for processing
@@ -6,14 +6,9 @@ import (
 
 // TradeTypeFromString converts string to TradeType
 func TradeTypeFromString(s string) (types.TradeType, bool) {
-	switch s {
-	case "Buy":
-		return types.TradeTypeBuy, true
-	case "Sell":
-		return types.TradeTypeSell, true
-	case "Dividends":
-		return types.TradeTypeDividend, true
-	default:
+	tradeType := types.TradeType(s)
+	if !tradeType.IsValid() {
 		return "", false
 	}
+	return tradeType, true
 }
@@ -47,6 +47,31 @@ func XIRR(cashFlows []struct {
 	return 0
 }
 
+// Mean calculates the arithmetic mean of a slice of float64.
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// Covariance calculates the population covariance of two equally long slices of float64.
+func Covariance(x, y []float64) float64 {
+	if len(x) == 0 || len(x) != len(y) {
+		return 0
+	}
+	meanX, meanY := Mean(x), Mean(y)
+	sum := 0.0
+	for i := range x {
+		sum += (x[i] - meanX) * (y[i] - meanY)
+	}
+	return sum / float64(len(x))
+}
+
 // StandardDeviation calculates the standard deviation of a slice of float64.
 func StandardDeviation(values []float64) float64 {
 	if len(values) == 0 {
@@ -66,6 +91,62 @@ func StandardDeviation(values []float64) float64 {
 	return math.Sqrt(variance)
 }
 
+// DownsideDeviation calculates the root mean square of the returns falling short of a target.
+func DownsideDeviation(returns []float64, target float64) float64 {
+	if len(returns) == 0 {
+		return 0
+	}
+	sumSquaredShortfall := 0.0
+	for _, r := range returns {
+		if r < target {
+			shortfall := r - target
+			sumSquaredShortfall += shortfall * shortfall
+		}
+	}
+	return math.Sqrt(sumSquaredShortfall / float64(len(returns)))
+}
+
+// MaxDrawdown finds the largest fall from a running peak in a series of values. It returns the
+// fall as a fraction of the peak together with the indices of the peak and the trough.
+func MaxDrawdown(values []float64) (drawdown float64, peak, trough int) {
+	runningPeak := 0
+	for i, value := range values {
+		if value > values[runningPeak] {
+			runningPeak = i
+		}
+		if values[runningPeak] <= 0 {
+			continue
+		}
+		if fall := (values[runningPeak] - value) / values[runningPeak]; fall > drawdown {
+			drawdown, peak, trough = fall, runningPeak, i
+		}
+	}
+	return drawdown, peak, trough
+}
+
+// TimeWeightedReturn links the sub-period returns of a series of valuations. flows[i] is the net
+// external cash flow received after values[i-1] and already included in values[i]. Sub-periods
+// starting from a zero value have no capital at risk and are skipped.
+func TimeWeightedReturn(values, flows []float64) float64 {
+	growth := 1.0
+	for i := 1; i < len(values) && i < len(flows); i++ {
+		if values[i-1] <= 0 {
+			continue
+		}
+		growth *= (values[i] - flows[i]) / values[i-1]
+	}
+	return growth - 1
+}
+
+// AnnualizeReturn converts a cumulative return over a number of years into an annual rate.
+// Periods shorter than a year are returned unchanged rather than extrapolated.
+func AnnualizeReturn(totalReturn, years float64) float64 {
+	if years < 1 || totalReturn <= -1 {
+		return totalReturn
+	}
+	return math.Pow(1+totalReturn, 1/years) - 1
+}
+
 // Abs returns the absolute value of x
 func Abs(x float64) float64 {
 	if x < 0 {
@@ -88,6 +169,11 @@ func ParseUint(s string, defaultValue uint) (uint, error) {
 	return uint(val), nil
 }
 
+// RoundTo2 rounds a float64 to 2 decimal places, the precision money amounts are stored with
+func RoundTo2(val float64) float64 {
+	return math.Round(val*1e2) / 1e2
+}
+
 // RoundTo4 rounds a float64 to 4 decimal places
 func RoundTo4(val float64) float64 {
 	return math.Round(val*1e4) / 1e4
@@ -62,7 +62,7 @@ func ResetTestDB(db *gorm.DB, dbName string, t *testing.T) {
 	require.NoError(t, err)
 	_, _ = sqlDB.Exec("SET FOREIGN_KEY_CHECKS = 0;")
 	// Drop tables if they exist (including migration tracking table)
-	tables := []string{"jwt_tokens", "transactions", "users", "schema_migrations"} // Order matters for foreign keys
+	tables := []string{"tax_lot_disposals", "tax_lots", "jwt_tokens", "portfolio_snapshots", "target_allocations", "accounts", "transactions", "users", "schema_migrations"} // Order matters for foreign keys
 	for _, table := range tables {
 		_, _ = sqlDB.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s;", table))
 	}
@@ -0,0 +1,6 @@
+-- Cost basis method support
+-- Adds the per-user default lot relief method and per-sell specific lot selections
+
+ALTER TABLE users ADD COLUMN cost_basis_method VARCHAR(20) NOT NULL DEFAULT 'average' AFTER is_active;
+
+ALTER TABLE transactions ADD COLUMN lot_selections JSON NULL AFTER user_notes;
@@ -0,0 +1,40 @@
+-- Tax lot ledger
+-- Records every acquisition lot and the disposals that relieved it, rebuilt whenever a user's transactions change
+
+CREATE TABLE IF NOT EXISTS tax_lots (
+    lot_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    transaction_id VARCHAR(36) NOT NULL,
+    symbol VARCHAR(20) NOT NULL,
+    cost_basis_method VARCHAR(20) NOT NULL,
+    acquired_at TIMESTAMP NOT NULL,
+    original_quantity DECIMAL(15,4) NOT NULL,
+    remaining_quantity DECIMAL(15,4) NOT NULL,
+    unit_cost DECIMAL(15,4) NOT NULL,
+    cost_basis DECIMAL(15,2) NOT NULL,
+    closed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_tax_lots_user_symbol (user_id, symbol),
+    INDEX idx_tax_lots_transaction_id (transaction_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON UPDATE CASCADE ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS tax_lot_disposals (
+    disposal_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    lot_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    sell_transaction_id VARCHAR(36) NOT NULL,
+    symbol VARCHAR(20) NOT NULL,
+    disposed_at TIMESTAMP NOT NULL,
+    quantity DECIMAL(15,4) NOT NULL,
+    cost_basis DECIMAL(15,2) NOT NULL,
+    proceeds DECIMAL(15,2) NOT NULL,
+    gain_loss DECIMAL(15,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_tax_lot_disposals_lot_id (lot_id),
+    INDEX idx_tax_lot_disposals_user_symbol (user_id, symbol),
+    INDEX idx_tax_lot_disposals_sell_transaction_id (sell_transaction_id),
+    FOREIGN KEY (lot_id) REFERENCES tax_lots(lot_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON UPDATE CASCADE ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
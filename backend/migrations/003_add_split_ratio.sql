@@ -0,0 +1,4 @@
+-- Stock split corporate actions
+-- Split transactions carry the number of new shares received per old share (4 for 4:1, 0.1 for 1:10)
+
+ALTER TABLE transactions ADD COLUMN split_ratio DECIMAL(15,6) NULL AFTER lot_selections;
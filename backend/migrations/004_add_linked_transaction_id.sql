@@ -0,0 +1,6 @@
+-- Dividend reinvestment support
+-- A reinvested dividend is stored as a Dividends row and a Buy row that points back to it
+
+ALTER TABLE transactions ADD COLUMN linked_transaction_id VARCHAR(36) NULL AFTER split_ratio;
+
+CREATE INDEX idx_transactions_linked_transaction_id ON transactions (linked_transaction_id);
@@ -0,0 +1,4 @@
+-- Multi-currency support
+-- Adds the per-user base currency that portfolio values are reported in
+
+ALTER TABLE users ADD COLUMN base_currency VARCHAR(3) NOT NULL DEFAULT 'USD' AFTER cost_basis_method;
@@ -0,0 +1,21 @@
+-- Brokerage accounts
+-- Accounts are owned by a user; transactions may reference the account they were made in
+
+CREATE TABLE IF NOT EXISTS accounts (
+    account_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    broker VARCHAR(100) NOT NULL,
+    account_type VARCHAR(20) NOT NULL DEFAULT 'taxable',
+    base_currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    INDEX idx_accounts_user_id (user_id),
+    INDEX idx_accounts_deleted_at (deleted_at),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON UPDATE CASCADE ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE transactions ADD COLUMN account_id VARCHAR(36) NULL AFTER broker;
+
+CREATE INDEX idx_transactions_account_id ON transactions (account_id);
@@ -0,0 +1,14 @@
+-- Target allocations
+-- Each row is the target weight, as a percentage of the portfolio, of one symbol or one asset class
+
+CREATE TABLE IF NOT EXISTS target_allocations (
+    target_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_key VARCHAR(50) NOT NULL,
+    weight DECIMAL(7,4) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_target_allocations_user_type_key (user_id, target_type, target_key),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON UPDATE CASCADE ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
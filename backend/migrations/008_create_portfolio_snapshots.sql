@@ -0,0 +1,17 @@
+-- Portfolio snapshots
+-- Each row is the end of day valuation of a user's whole portfolio in their base currency, filled in
+-- by the snapshot job and deleted from the earliest affected date whenever a transaction changes
+
+CREATE TABLE IF NOT EXISTS portfolio_snapshots (
+    user_id VARCHAR(36) NOT NULL,
+    snapshot_date DATE NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    market_value DECIMAL(20,4) NOT NULL DEFAULT 0,
+    cost_basis DECIMAL(20,4) NOT NULL DEFAULT 0,
+    cash DECIMAL(20,4) NOT NULL DEFAULT 0,
+    net_flows DECIMAL(20,4) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, snapshot_date),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON UPDATE CASCADE ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
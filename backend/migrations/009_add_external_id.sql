@@ -0,0 +1,7 @@
+-- External IDs of imported transactions
+-- File imports store the ID the institution gave each transaction (the OFX FITID) so that
+-- importing the same statement again does not duplicate it
+
+ALTER TABLE transactions ADD COLUMN external_id VARCHAR(255) NULL AFTER linked_transaction_id;
+
+CREATE INDEX idx_transactions_user_id_external_id ON transactions (user_id, external_id);
@@ -23,6 +23,105 @@ func GetAllMigrations() []Migration {
 				return db.Exec("DROP TABLE IF EXISTS jwt_tokens; DROP TABLE IF EXISTS transactions; DROP TABLE IF EXISTS users;").Error
 			},
 		},
+		{
+			ID:          "001_add_cost_basis_method",
+			Description: "Add users.cost_basis_method and transactions.lot_selections for selectable cost basis methods",
+			Up: func(db *gorm.DB) error {
+				return executeSQLFile(db, "001_add_cost_basis_method.sql")
+			},
+			Down: func(db *gorm.DB) error {
+				if err := db.Exec("ALTER TABLE transactions DROP COLUMN lot_selections").Error; err != nil {
+					return err
+				}
+				return db.Exec("ALTER TABLE users DROP COLUMN cost_basis_method").Error
+			},
+		},
+		{
+			ID:          "002_create_tax_lots",
+			Description: "Tax lot ledger: tax_lots and tax_lot_disposals",
+			Up: func(db *gorm.DB) error {
+				return executeSQLFile(db, "002_create_tax_lots.sql")
+			},
+			Down: func(db *gorm.DB) error {
+				return db.Exec("DROP TABLE IF EXISTS tax_lot_disposals; DROP TABLE IF EXISTS tax_lots;").Error
+			},
+		},
+		{
+			ID:          "003_add_split_ratio",
+			Description: "Add transactions.split_ratio for stock split and reverse split corporate actions",
+			Up: func(db *gorm.DB) error {
+				return executeSQLFile(db, "003_add_split_ratio.sql")
+			},
+			Down: func(db *gorm.DB) error {
+				return db.Exec("ALTER TABLE transactions DROP COLUMN split_ratio").Error
+			},
+		},
+		{
+			ID:          "004_add_linked_transaction_id",
+			Description: "Add transactions.linked_transaction_id pairing reinvested dividends with their share purchase",
+			Up: func(db *gorm.DB) error {
+				return executeSQLFile(db, "004_add_linked_transaction_id.sql")
+			},
+			Down: func(db *gorm.DB) error {
+				return db.Exec("ALTER TABLE transactions DROP COLUMN linked_transaction_id").Error
+			},
+		},
+		{
+			ID:          "005_add_base_currency",
+			Description: "Add users.base_currency for reporting portfolio values in one currency",
+			Up: func(db *gorm.DB) error {
+				return executeSQLFile(db, "005_add_base_currency.sql")
+			},
+			Down: func(db *gorm.DB) error {
+				return db.Exec("ALTER TABLE users DROP COLUMN base_currency").Error
+			},
+		},
+		{
+			ID:          "006_create_accounts",
+			Description: "Create accounts table and transactions.account_id referencing it",
+			Up: func(db *gorm.DB) error {
+				return executeSQLFile(db, "006_create_accounts.sql")
+			},
+			Down: func(db *gorm.DB) error {
+				if err := db.Exec("ALTER TABLE transactions DROP COLUMN account_id").Error; err != nil {
+					return err
+				}
+				return db.Exec("DROP TABLE IF EXISTS accounts").Error
+			},
+		},
+		{
+			ID:          "007_create_target_allocations",
+			Description: "Create target_allocations table holding each user's target weights for rebalancing",
+			Up: func(db *gorm.DB) error {
+				return executeSQLFile(db, "007_create_target_allocations.sql")
+			},
+			Down: func(db *gorm.DB) error {
+				return db.Exec("DROP TABLE IF EXISTS target_allocations").Error
+			},
+		},
+		{
+			ID:          "008_create_portfolio_snapshots",
+			Description: "Create portfolio_snapshots table caching each user's end of day portfolio valuation",
+			Up: func(db *gorm.DB) error {
+				return executeSQLFile(db, "008_create_portfolio_snapshots.sql")
+			},
+			Down: func(db *gorm.DB) error {
+				return db.Exec("DROP TABLE IF EXISTS portfolio_snapshots").Error
+			},
+		},
+		{
+			ID:          "009_add_external_id",
+			Description: "Add transactions.external_id holding the institution's ID of imported transactions",
+			Up: func(db *gorm.DB) error {
+				return executeSQLFile(db, "009_add_external_id.sql")
+			},
+			Down: func(db *gorm.DB) error {
+				if err := db.Exec("DROP INDEX idx_transactions_user_id_external_id ON transactions").Error; err != nil {
+					return err
+				}
+				return db.Exec("ALTER TABLE transactions DROP COLUMN external_id").Error
+			},
+		},
 	}
 }
 
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/api/handlers"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/repositories"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+func TestAccountCRUD(t *testing.T) {
+	db := utils.SetupTestDB(t)
+	accountsHandler := handlers.NewAccountsHandler(services.NewAccountService(
+		repositories.NewAccountRepository(db),
+		repositories.NewTransactionRepository(db),
+	))
+
+	user, err := createTestUser(db, "accounts@example.com")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", user.UserID)
+	})
+	router.GET("/accounts", accountsHandler.GetAccounts)
+	router.POST("/accounts", accountsHandler.CreateAccount)
+	router.PUT("/accounts/:id", accountsHandler.UpdateAccount)
+	router.DELETE("/accounts/:id", accountsHandler.DeleteAccount)
+
+	send := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var payload bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&payload).Encode(body))
+		}
+		req, err := http.NewRequest(method, path, &payload)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Create with defaults for type and currency
+	w := send("POST", "/accounts", gin.H{"name": "Brokerage", "broker": "Schwab"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data struct {
+			Account models.Account `json:"account"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	account := created.Data.Account
+	assert.Equal(t, models.AccountTypeTaxable, account.AccountType)
+	assert.Equal(t, "USD", account.BaseCurrency)
+
+	// Invalid account type
+	w = send("POST", "/accounts", gin.H{"name": "Retirement", "broker": "Schwab", "account_type": "pension"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Update
+	w = send("PUT", fmt.Sprintf("/accounts/%s", account.AccountID), gin.H{"name": "Roth", "broker": "Schwab", "account_type": "roth_ira"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = send("GET", "/accounts", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed struct {
+		Data struct {
+			Accounts []models.Account `json:"accounts"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Data.Accounts, 1)
+	assert.Equal(t, "Roth", listed.Data.Accounts[0].Name)
+	assert.Equal(t, models.AccountTypeRothIRA, listed.Data.Accounts[0].AccountType)
+
+	// Accounts referenced by transactions cannot be deleted
+	transaction := &models.Transaction{
+		UserID:          user.UserID,
+		AccountID:       &account.AccountID,
+		Symbol:          "AAPL",
+		TradeType:       types.TradeTypeBuy,
+		Quantity:        1,
+		Price:           100,
+		Amount:          100,
+		Currency:        "USD",
+		Broker:          "Schwab",
+		TransactionDate: time.Now(),
+	}
+	require.NoError(t, db.Create(transaction).Error)
+
+	w = send("DELETE", fmt.Sprintf("/accounts/%s", account.AccountID), nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	require.NoError(t, db.Unscoped().Delete(transaction).Error)
+	w = send("DELETE", fmt.Sprintf("/accounts/%s", account.AccountID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("DELETE", fmt.Sprintf("/accounts/%s", account.AccountID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetTaxLots_UnknownAccount(t *testing.T) {
+	db := utils.SetupTestDB(t)
+	transactionRepo := repositories.NewTransactionRepository(db)
+	accountRepo := repositories.NewAccountRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+	taxLotService := services.NewTaxLotService(transactionRepo, repositories.NewTaxLotRepository(db), accountRepo, userRepo)
+	portfolioHandler := handlers.NewPortfolioHandler(nil, taxLotService, nil)
+
+	owner, err := createTestUser(db, "owner@example.com")
+	require.NoError(t, err)
+	other := &models.User{Username: "otheruser", Email: "other@example.com"}
+	require.NoError(t, other.SetPassword("test123"))
+	require.NoError(t, db.Create(other).Error)
+	account := &models.Account{UserID: owner.UserID, Name: "Brokerage", Broker: "Schwab"}
+	require.NoError(t, accountRepo.Create(account))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", other.UserID)
+	})
+	router.GET("/portfolio/holdings/:symbol/lots", portfolioHandler.GetTaxLots)
+
+	// Another user's account is reported like one that does not exist
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", fmt.Sprintf("/portfolio/holdings/AAPL/lots?account_id=%s", account.AccountID), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Account does not exist")
+}
+
+func TestGetTaxLots_AccountLookupFailure(t *testing.T) {
+	db := utils.SetupTestDB(t)
+	accountRepo := repositories.NewAccountRepository(db)
+	taxLotService := services.NewTaxLotService(repositories.NewTransactionRepository(db), repositories.NewTaxLotRepository(db),
+		accountRepo, repositories.NewUserRepository(db))
+
+	owner, err := createTestUser(db, "owner@example.com")
+	require.NoError(t, err)
+	account := &models.Account{UserID: owner.UserID, Name: "Brokerage", Broker: "Schwab"}
+	require.NoError(t, accountRepo.Create(account))
+
+	// A failing lookup is returned as it is rather than reported as a missing account
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+	_, err = taxLotService.GetAccountTaxLots(owner.UserID, account.AccountID, "AAPL", "")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, services.ErrAccountNotFound)
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func TestBuildAllocationReport(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	exposures := []services.AllocationExposure{
+		{Key: "Technology", Symbol: "AAPL", MarketValue: 300},
+		{Key: "Technology", Symbol: "MSFT", MarketValue: 500},
+		{Key: "Energy", Symbol: "XOM", MarketValue: 100},
+		{Key: "", Symbol: "ZZZZ", MarketValue: 100},
+		{Key: "Energy", Symbol: "GONE", MarketValue: 0},
+	}
+
+	report := services.BuildAllocationReport(models.AllocationGroupingSector, "USD", exposures, now)
+	assert.Equal(t, models.AllocationGroupingSector, report.GroupBy)
+	assert.Equal(t, 1000.0, report.MarketValue)
+	require.Len(t, report.Groups, 3)
+
+	tech := report.Groups[0]
+	assert.Equal(t, "Technology", tech.Key)
+	assert.Equal(t, 80.0, tech.Weight)
+	require.Len(t, tech.Holdings, 2)
+	assert.Equal(t, "MSFT", tech.Holdings[0].Symbol)
+	assert.Equal(t, 50.0, tech.Holdings[0].Weight)
+
+	// Ties keep their first-seen order; exposures without a key are grouped as unknown
+	assert.Equal(t, "Energy", report.Groups[1].Key)
+	assert.Len(t, report.Groups[1].Holdings, 1)
+	assert.Equal(t, models.AllocationUnknown, report.Groups[2].Key)
+	assert.Equal(t, 10.0, report.Groups[2].Weight)
+
+	empty := services.BuildAllocationReport(models.AllocationGroupingCountry, "USD", nil, now)
+	assert.NotNil(t, empty.Groups)
+	assert.Empty(t, empty.Groups)
+}
+
+func TestBrokerPositions(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	atBroker := func(tx models.Transaction, broker string) models.Transaction {
+		tx.Broker = broker
+		return tx
+	}
+
+	positions := services.BrokerPositions([]models.Transaction{
+		atBroker(newTestTransaction(types.TradeTypeSell, 4, 120, day(3)), "IB"),
+		atBroker(newTestTransaction(types.TradeTypeBuy, 10, 100, day(1)), "IB"),
+		atBroker(newTestTransaction(types.TradeTypeBuy, 5, 100, day(2)), "Schwab"),
+		atBroker(newTestSplit(2, day(4)), "Schwab"),
+		newTestCashMovement(types.TradeTypeDeposit, "IB", 1000, day(1)),
+	})
+
+	require.Len(t, positions, 1)
+	assert.InDelta(t, 6, positions["AAPL"]["IB"], 1e-9)
+	assert.InDelta(t, 10, positions["AAPL"]["Schwab"], 1e-9)
+}
+
+func TestAllocationGrouping_IsValid(t *testing.T) {
+	for _, groupBy := range []string{"sector", "asset_class", "country", "broker", "currency"} {
+		assert.True(t, models.AllocationGrouping(groupBy).IsValid(), groupBy)
+	}
+	assert.False(t, models.AllocationGrouping("industry").IsValid())
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/services"
+)
+
+func TestPriceSeries_PriceOn(t *testing.T) {
+	series := services.NewPriceSeries([]provider.ClosePrice{
+		{Date: "2024-01-05", Price: 105},
+		{Date: "2024-01-02", Price: 100},
+		{Date: "2024-01-03", Price: 0},
+	})
+	require.Equal(t, 2, series.Len())
+
+	_, ok := series.PriceOn(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+
+	price, ok := series.PriceOn(time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, 100.0, price)
+
+	price, _ = series.PriceOn(time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, 105.0, price)
+}
+
+func TestPriceSeries_Intraday(t *testing.T) {
+	series := services.NewIntradayPriceSeries([]provider.ClosePrice{
+		{Date: "2024-01-05T15:00:00Z", Price: 101},
+		{Date: "2024-01-05T14:00:00Z", Price: 100},
+		{Date: "2024-01-05T16:00:00Z", Price: 102},
+	})
+
+	_, ok := series.PriceOn(time.Date(2024, 1, 5, 13, 30, 0, 0, time.UTC))
+	assert.False(t, ok)
+
+	// Between bars the latest close applies, whatever the location of the time
+	eastern := time.FixedZone("EST", -5*60*60)
+	price, ok := series.PriceOn(time.Date(2024, 1, 5, 10, 30, 0, 0, eastern))
+	assert.True(t, ok)
+	assert.Equal(t, 101.0, price)
+
+	price, _ = series.PriceOn(time.Date(2024, 1, 5, 16, 0, 0, 0, time.UTC))
+	assert.Equal(t, 102.0, price)
+	price, _ = series.PriceOn(time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC))
+	assert.Equal(t, 102.0, price)
+}
+
+func TestSimulateBenchmark(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	prices := services.NewPriceSeries([]provider.ClosePrice{
+		{Date: "2024-01-01", Price: 100},
+		{Date: "2024-01-02", Price: 110},
+		{Date: "2024-01-03", Price: 120},
+	})
+	flows := []services.ExternalCashFlow{
+		{Date: day(1), Currency: "USD", Amount: 1000}, // already in the starting value
+		{Date: day(2), Currency: "USD", Amount: 550},
+		{Date: day(3), Currency: "USD", Amount: -600},
+	}
+
+	values := services.SimulateBenchmark(prices, []time.Time{day(1), day(2), day(3)}, 1000, flows)
+	require.Len(t, values, 3)
+	assert.InDelta(t, 1000, values[0], 1e-9)
+	// 10 units at 110 plus 5 more bought with the deposit
+	assert.InDelta(t, 15*110, values[1], 1e-9)
+	// 5 units sold for the withdrawal leave 10 at 120
+	assert.InDelta(t, 10*120, values[2], 1e-9)
+}
+
+func TestCompareToBenchmark(t *testing.T) {
+	benchmark := []float64{0.01, -0.02, 0.03, 0.00}
+
+	// A portfolio moving twice as much as the benchmark plus a constant 0.1% per period
+	portfolio := make([]float64, len(benchmark))
+	for i, r := range benchmark {
+		portfolio[i] = 2*r + 0.001
+	}
+
+	alpha, beta, trackingError := services.CompareToBenchmark(portfolio, benchmark, 12)
+	assert.InDelta(t, 2, beta, 1e-9)
+	assert.InDelta(t, 0.012, alpha, 1e-9)
+	assert.Greater(t, trackingError, 0.0)
+
+	alpha, beta, trackingError = services.CompareToBenchmark(benchmark, benchmark, 12)
+	assert.InDelta(t, 1, beta, 1e-9)
+	assert.InDelta(t, 0, alpha, 1e-9)
+	assert.InDelta(t, 0, trackingError, 1e-9)
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func newTestCashMovement(tradeType types.TradeType, broker string, amount float64, date time.Time) models.Transaction {
+	tx := newTestTransaction(tradeType, 0, 0, date)
+	tx.Symbol = types.CashSymbol
+	tx.Broker = broker
+	tx.Amount = amount
+	return tx
+}
+
+func TestCashFlowAmount(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := map[types.TradeType]float64{
+		types.TradeTypeDeposit:    100,
+		types.TradeTypeInterest:   100,
+		types.TradeTypeSell:       100,
+		types.TradeTypeDividend:   100,
+		types.TradeTypeWithdrawal: -100,
+		types.TradeTypeFee:        -100,
+		types.TradeTypeTax:        -100,
+		types.TradeTypeBuy:        -100,
+		types.TradeTypeSplit:      0,
+	}
+	for tradeType, expected := range cases {
+		assert.Equal(t, expected, services.CashFlowAmount(newTestCashMovement(tradeType, "IB", 100, day)), tradeType)
+	}
+}
+
+func TestCalculateCashBalances(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	buy := newTestTransaction(types.TradeTypeBuy, 10, 100, day(2))
+	buy.Broker = "IB"
+	sell := newTestTransaction(types.TradeTypeSell, 5, 120, day(5))
+	sell.Broker = "IB"
+	// Without deposits the other broker's cash is not tracked
+	untracked := newTestTransaction(types.TradeTypeBuy, 1, 100, day(2))
+	untracked.Broker = "Schwab"
+	eurDeposit := newTestCashMovement(types.TradeTypeDeposit, "IB", 500, day(3))
+	eurDeposit.Currency = "EUR"
+
+	transactions := []models.Transaction{
+		newTestCashMovement(types.TradeTypeDeposit, "IB", 5000, day(1)),
+		buy,
+		untracked,
+		eurDeposit,
+		newTestCashMovement(types.TradeTypeFee, "IB", 10, day(4)),
+		sell,
+		newTestCashMovement(types.TradeTypeInterest, "IB", 3, day(6)),
+		newTestCashMovement(types.TradeTypeTax, "IB", 1, day(6)),
+		newTestCashMovement(types.TradeTypeWithdrawal, "IB", 1000, day(7)),
+	}
+
+	balances := services.CalculateCashBalances(transactions, day(31))
+	require.Len(t, balances, 2)
+	assert.Equal(t, "IB", balances[0].Broker)
+	assert.Equal(t, "EUR", balances[0].Currency)
+	assert.InDelta(t, 500, balances[0].Balance, 1e-9)
+	assert.Equal(t, "USD", balances[1].Currency)
+	assert.InDelta(t, 5000-1000-10+600+3-1-1000, balances[1].Balance, 1e-9)
+
+	// Balances as of an earlier date exclude later transactions
+	balances = services.CalculateCashBalances(transactions, day(2))
+	require.Len(t, balances, 1)
+	assert.InDelta(t, 4000, balances[0].Balance, 1e-9)
+
+	assert.Empty(t, services.CalculateCashBalances([]models.Transaction{untracked}, day(31)))
+}
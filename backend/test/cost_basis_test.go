@@ -0,0 +1,144 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func newTestTransaction(tradeType types.TradeType, quantity, price float64, date time.Time) models.Transaction {
+	return models.Transaction{
+		TransactionID:   uuid.New(),
+		Symbol:          "AAPL",
+		TradeType:       tradeType,
+		Quantity:        quantity,
+		Price:           price,
+		Amount:          quantity * price,
+		Currency:        "USD",
+		TransactionDate: date,
+	}
+}
+
+func costBasisTestTransactions() []models.Transaction {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	return []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 10, 100, day(1)),
+		newTestTransaction(types.TradeTypeBuy, 10, 150, day(2)),
+		newTestTransaction(types.TradeTypeBuy, 10, 120, day(3)),
+		newTestTransaction(types.TradeTypeSell, 15, 200, day(4)),
+	}
+}
+
+func TestCalculateCostBasis_Methods(t *testing.T) {
+	cases := []struct {
+		method            models.CostBasisMethod
+		expectedCost      float64
+		expectedRealized  float64
+		expectedOpenLots  int
+		expectedDisposals int
+	}{
+		// Average: pool cost 3700 / 30 shares, 15 sold from the oldest lots
+		{models.CostBasisAverage, 1850, 3000 - 1850, 2, 2},
+		// FIFO: sells 10@100 + 5@150
+		{models.CostBasisFIFO, 5*150 + 10*120, 3000 - (1000 + 750), 2, 2},
+		// LIFO: sells 10@120 + 5@150
+		{models.CostBasisLIFO, 10*100 + 5*150, 3000 - (1200 + 750), 2, 2},
+		// HIFO: sells 10@150 + 5@120
+		{models.CostBasisHIFO, 10*100 + 5*120, 3000 - (1500 + 600), 2, 2},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.method), func(t *testing.T) {
+			result := services.CalculateCostBasis(costBasisTestTransactions(), c.method)
+			assert.Equal(t, c.method, result.Method)
+			assert.InDelta(t, 15, result.TotalQuantity, 1e-9)
+			assert.InDelta(t, c.expectedCost, result.TotalCost, 1e-6)
+			assert.InDelta(t, c.expectedRealized, result.RealizedGainLoss, 1e-6)
+			assert.Len(t, result.OpenLots, c.expectedOpenLots)
+			assert.Len(t, result.Disposals, c.expectedDisposals)
+		})
+	}
+}
+
+func TestCalculateCostBasis_SpecificLot(t *testing.T) {
+	transactions := costBasisTestTransactions()
+	secondLot := transactions[1]
+
+	// Relieve 5 shares from the second lot, remaining 10 fall back to FIFO
+	transactions[3].LotSelections = models.LotSelections{
+		{TransactionID: secondLot.TransactionID.String(), Quantity: 5},
+	}
+
+	result := services.CalculateCostBasis(transactions, models.CostBasisSpecificLot)
+	require.Len(t, result.Disposals, 2)
+	assert.Equal(t, secondLot.TransactionID, result.Disposals[0].LotTransactionID)
+	assert.InDelta(t, 5, result.Disposals[0].Quantity, 1e-9)
+	assert.Equal(t, transactions[0].TransactionID, result.Disposals[1].LotTransactionID)
+	assert.InDelta(t, 10, result.Disposals[1].Quantity, 1e-9)
+	assert.InDelta(t, 3000-(750+1000), result.RealizedGainLoss, 1e-6)
+	assert.InDelta(t, 5*150+10*120, result.TotalCost, 1e-6)
+}
+
+func TestCalculateCostBasis_OversellAndInvalidMethod(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		newTestTransaction(types.TradeTypeSell, 5, 100, day),
+		newTestTransaction(types.TradeTypeBuy, 10, 100, day.AddDate(0, 0, 1)),
+		newTestTransaction(types.TradeTypeSell, 20, 110, day.AddDate(0, 0, 2)),
+	}
+
+	result := services.CalculateCostBasis(transactions, models.CostBasisMethod("unknown"))
+	assert.Equal(t, models.DefaultCostBasisMethod, result.Method)
+	assert.InDelta(t, 0, result.TotalQuantity, 1e-9)
+	assert.Empty(t, result.OpenLots)
+	assert.InDelta(t, 100, result.RealizedGainLoss, 1e-6)
+	assert.Equal(t, 0.0, result.UnitCost())
+}
+
+func newTestSplit(ratio float64, date time.Time) models.Transaction {
+	split := newTestTransaction(types.TradeTypeSplit, 0, 0, date)
+	split.SplitRatio = ratio
+	return split
+}
+
+func TestCalculateCostBasis_Split(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	transactions := []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 10, 400, day(1)),
+		newTestSplit(4, day(2)),
+		newTestTransaction(types.TradeTypeSell, 20, 120, day(3)),
+	}
+
+	result := services.CalculateCostBasis(transactions, models.CostBasisFIFO)
+	assert.InDelta(t, 20, result.TotalQuantity, 1e-9)
+	assert.InDelta(t, 2000, result.TotalCost, 1e-6)
+	assert.InDelta(t, 100, result.UnitCost(), 1e-6)
+	assert.InDelta(t, 20*(120-100), result.RealizedGainLoss, 1e-6)
+	require.Len(t, result.OpenLots, 1)
+	assert.InDelta(t, 40, result.OpenLots[0].OriginalQuantity, 1e-9)
+}
+
+func TestCalculateCostBasis_ReverseSplit(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	transactions := []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 100, 2, day(1)),
+		newTestSplit(0.1, day(2)),
+		newTestTransaction(types.TradeTypeBuy, 10, 25, day(3)),
+	}
+
+	result := services.CalculateCostBasis(transactions, models.CostBasisAverage)
+	assert.InDelta(t, 20, result.TotalQuantity, 1e-9)
+	assert.InDelta(t, 200+250, result.TotalCost, 1e-6)
+
+	var position float64
+	for _, tx := range transactions {
+		position = services.ApplyToPosition(position, tx)
+	}
+	assert.InDelta(t, 20, position, 1e-9)
+}
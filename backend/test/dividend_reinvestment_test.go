@@ -0,0 +1,70 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/repositories"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+func TestExpandReinvestedDividends(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	buy := newTestTransaction(types.TradeTypeBuy, 10, 100, date.AddDate(0, -2, 0))
+	drip := newTestTransaction(types.TradeTypeDividendReinvestment, 0.25, 120, date)
+
+	expanded := services.ExpandReinvestedDividends([]models.Transaction{buy, drip})
+	require.Len(t, expanded, 3)
+	assert.Equal(t, buy.TransactionID, expanded[0].TransactionID)
+
+	dividend, purchase := expanded[1], expanded[2]
+	assert.Equal(t, types.TradeTypeDividend, dividend.TradeType)
+	assert.InDelta(t, 30, dividend.Amount, 1e-9)
+	assert.Equal(t, 0.0, dividend.Quantity)
+	assert.Equal(t, types.TradeTypeBuy, purchase.TradeType)
+	assert.InDelta(t, 0.25, purchase.Quantity, 1e-9)
+	require.NotNil(t, purchase.LinkedTransactionID)
+	assert.Equal(t, dividend.TransactionID, *purchase.LinkedTransactionID)
+	assert.NotEqual(t, dividend.TransactionID, purchase.TransactionID)
+
+	// The reinvested shares join the lots and the cash is counted once as income
+	result := services.CalculateCostBasis(expanded, models.CostBasisFIFO)
+	assert.InDelta(t, 10.25, result.TotalQuantity, 1e-9)
+	assert.InDelta(t, 1030, result.TotalCost, 1e-6)
+	total, _ := services.CalculateDividendIncome(expanded, date)
+	assert.InDelta(t, 30, total, 1e-9)
+}
+
+func TestUpdateTransaction_RejectsReinvestedDividendHalf(t *testing.T) {
+	db := utils.SetupTestDB(t)
+	user, err := createTestUser(db, "drip-update@example.com")
+	require.NoError(t, err)
+
+	transactionRepo := repositories.NewTransactionRepository(db)
+	accountRepo := repositories.NewAccountRepository(db)
+	taxLotService := services.NewTaxLotService(transactionRepo, repositories.NewTaxLotRepository(db), accountRepo, repositories.NewUserRepository(db))
+	transactionService := services.NewTransactionService(transactionRepo, taxLotService, accountRepo, nil)
+
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	drip := newTestTransaction(types.TradeTypeDividendReinvestment, 0.25, 120, date)
+	result, err := transactionService.CreateTransactions(user.UserID, []models.Transaction{drip}, false)
+	require.NoError(t, err)
+	require.Len(t, result.Created, 2)
+
+	// Neither the dividend nor its linked Buy can drift away from the other
+	for _, half := range result.Created {
+		_, err := transactionService.UpdateTransaction(user.UserID, half.TransactionID, half.Symbol, half.Exchange, half.Broker, half.Currency,
+			"2024-03-20", string(half.TradeType), half.Quantity, half.Price, 45, half.UserNotes, nil, 0, nil)
+		assert.ErrorIs(t, err, services.ErrLinkedTransaction, half.TradeType)
+
+		stored, err := transactionRepo.GetByID(half.TransactionID)
+		require.NoError(t, err)
+		assert.InDelta(t, 30, stored.Amount, 1e-6)
+		assert.True(t, stored.TransactionDate.Equal(date))
+	}
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/config"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/repositories"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+func newTestDividend(symbol string, amount float64, date time.Time) models.Transaction {
+	dividend := newTestTransaction(types.TradeTypeDividend, 0, 0, date)
+	dividend.Symbol = symbol
+	dividend.Amount = amount
+	return dividend
+}
+
+func dividendTestTransactions() []models.Transaction {
+	return []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 10, 100, time.Date(2023, 1, 5, 0, 0, 0, 0, time.UTC)),
+		newTestDividend("AAPL", 10, time.Date(2023, 5, 15, 0, 0, 0, 0, time.UTC)),
+		newTestDividend("AAPL", 12, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)),
+		newTestDividend("MSFT", 8, time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)),
+		newTestDividend("AAPL", 12, time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)),
+	}
+}
+
+func TestCalculateDividendIncome(t *testing.T) {
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	total, ttm := services.CalculateDividendIncome(dividendTestTransactions(), asOf)
+	assert.InDelta(t, 42, total, 1e-9)
+	assert.InDelta(t, 32, ttm, 1e-9)
+	assert.InDelta(t, 3.2, services.CalculateYieldOnCost(ttm, 1000), 1e-9)
+	assert.Equal(t, 0.0, services.CalculateYieldOnCost(ttm, 0))
+}
+
+func TestBuildDividendReport(t *testing.T) {
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	monthly := services.BuildDividendReport(dividendTestTransactions(), models.DividendGroupingMonth, asOf)
+	require.Len(t, monthly.Periods, 3)
+	assert.Equal(t, "2023-05", monthly.Periods[0].Period)
+	assert.Equal(t, "2024-02", monthly.Periods[1].Period)
+	assert.InDelta(t, 20, monthly.Periods[1].Total, 1e-9)
+	assert.Len(t, monthly.Periods[1].Symbols, 2)
+	require.Len(t, monthly.Symbols, 2)
+	assert.Equal(t, "AAPL", monthly.Symbols[0].Symbol)
+	assert.InDelta(t, 34, monthly.Symbols[0].Amount, 1e-9)
+
+	yearly := services.BuildDividendReport(dividendTestTransactions(), models.DividendGroupingYear, asOf)
+	require.Len(t, yearly.Periods, 2)
+	assert.Equal(t, "2024", yearly.Periods[1].Period)
+	assert.InDelta(t, 32, yearly.Periods[1].Total, 1e-9)
+	assert.InDelta(t, 42, yearly.TotalIncome, 1e-9)
+}
+
+func TestApplyToPosition_DividendKeepsShares(t *testing.T) {
+	position := services.ApplyToPosition(10, newTestDividend("AAPL", 12, time.Now()))
+	assert.InDelta(t, 10, position, 1e-9)
+}
+
+func TestGetDividendIncome_ConvertsToBaseCurrency(t *testing.T) {
+	db := utils.SetupTestDB(t)
+	user, err := createTestUser(db, "dividend-fx@example.com")
+	require.NoError(t, err)
+
+	// One euro is worth 1.10 dollars on the first payment date and 1.20 from the second on
+	today := services.SnapshotDay(time.Now())
+	first, second := today.AddDate(0, -3, 0), today.AddDate(0, -1, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/fx/historical":
+			require.NoError(t, json.NewEncoder(w).Encode(provider.HistoricalFXRatesResponse{Success: true, Data: provider.FXHistoricalRates{
+				Base: "EUR", Quote: "USD",
+				Rates: []provider.FXRatePoint{
+					{Date: second.Format("2006-01-02"), Rate: 1.2},
+					{Date: first.Format("2006-01-02"), Rate: 1.1},
+				},
+			}}))
+		case "/api/v1/fx/current":
+			require.NoError(t, json.NewEncoder(w).Encode(provider.CurrentFXRatesResponse{Success: true, Data: []provider.FXRate{
+				{Base: "USD", Quote: "EUR", Rate: 1 / 1.2},
+			}}))
+		default:
+			http.Error(w, "not served", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dividends := []models.Transaction{
+		newTestDividend("SAP", 10, first),
+		newTestDividend("AAPL", 5, second),
+		newTestDividend("SAP", 10, second),
+	}
+	for i := range dividends {
+		dividends[i].UserID = user.UserID
+	}
+	dividends[0].Currency, dividends[2].Currency = "EUR", "EUR"
+	require.NoError(t, db.Create(&dividends).Error)
+
+	priceManager := provider.NewPriceServiceManager(&config.Config{PriceService: config.PriceServiceConfig{
+		BaseURL:    server.URL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+	}})
+	portfolioService := services.NewPortfolioService(repositories.NewTransactionRepository(db), repositories.NewUserRepository(db),
+		repositories.NewAccountRepository(db), repositories.NewPortfolioSnapshotRepository(db), priceManager)
+
+	report, err := portfolioService.GetDividendIncome(context.Background(), user.UserID, models.DividendGroupingYear, services.PortfolioOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "USD", report.Currency)
+	assert.InDelta(t, 28, report.TotalIncome, 1e-9)
+	assert.InDelta(t, 28, report.TTMDividendIncome, 1e-9)
+	require.Len(t, report.Symbols, 2)
+	assert.Equal(t, "SAP", report.Symbols[0].Symbol)
+	assert.InDelta(t, 23, report.Symbols[0].Amount, 1e-9)
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func TestTransactionFingerprint(t *testing.T) {
+	userID := uuid.New()
+	day := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	buy := newTestTransaction(types.TradeTypeBuy, 10, 150, day)
+	buy.UserID = userID
+	buy.Broker = "Schwab"
+
+	// Notes, exchange and a different broker spelling do not make a transaction different
+	same := buy
+	same.TransactionID = uuid.New()
+	same.Broker = " schwab "
+	same.UserNotes = "extracted again"
+	same.Exchange = "NASDAQ"
+	assert.Equal(t, services.TransactionFingerprint(buy), services.TransactionFingerprint(same))
+
+	for name, change := range map[string]func(tx *models.Transaction){
+		"user":     func(tx *models.Transaction) { tx.UserID = uuid.New() },
+		"symbol":   func(tx *models.Transaction) { tx.Symbol = "MSFT" },
+		"date":     func(tx *models.Transaction) { tx.TransactionDate = tx.TransactionDate.AddDate(0, 0, 1) },
+		"type":     func(tx *models.Transaction) { tx.TradeType = types.TradeTypeSell },
+		"quantity": func(tx *models.Transaction) { tx.Quantity = 10.5 },
+		"price":    func(tx *models.Transaction) { tx.Price = 150.01 },
+		"broker":   func(tx *models.Transaction) { tx.Broker = "Fidelity" },
+	} {
+		other := buy
+		change(&other)
+		assert.NotEqual(t, services.TransactionFingerprint(buy), services.TransactionFingerprint(other), name)
+	}
+
+	// Without a quantity or price, the amount tells cash movements apart
+	deposit := newTestCashMovement(types.TradeTypeDeposit, "Schwab", 500, day)
+	otherDeposit := newTestCashMovement(types.TradeTypeDeposit, "Schwab", 750, day)
+	assert.NotEqual(t, services.TransactionFingerprint(deposit), services.TransactionFingerprint(otherDeposit))
+
+	// A reinvested dividend matches the Buy it is stored as
+	reinvest := buy
+	reinvest.TradeType = types.TradeTypeDividendReinvestment
+	assert.Equal(t, services.TransactionFingerprint(buy), services.TransactionFingerprint(reinvest))
+}
+
+func TestMatchDuplicates(t *testing.T) {
+	day := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	fill := newTestTransaction(types.TradeTypeBuy, 5, 100, day)
+	existingFill := fill
+	existingFill.TransactionID = uuid.New()
+	sell := newTestTransaction(types.TradeTypeSell, 5, 120, day.AddDate(0, 0, 1))
+
+	// Two identical fills were recorded once before: only one of them is a duplicate
+	matches := services.MatchDuplicates([]models.Transaction{existingFill}, []models.Transaction{fill, fill, sell})
+	require.Len(t, matches, 3)
+	require.NotNil(t, matches[0])
+	assert.Equal(t, existingFill.TransactionID, *matches[0])
+	assert.Nil(t, matches[1])
+	assert.Nil(t, matches[2])
+
+	assert.Equal(t, []*uuid.UUID{nil}, services.MatchDuplicates(nil, []models.Transaction{sell}))
+
+	// The stored copy was rounded to the precision of its columns
+	drip := newTestTransaction(types.TradeTypeBuy, 0.123456, 187.654321, day)
+	drip.Amount = 23.166666
+	stored := drip
+	stored.TransactionID = uuid.New()
+	stored.Quantity, stored.Price, stored.Amount = 0.1235, 187.6543, 23.17
+	matches = services.MatchDuplicates([]models.Transaction{stored}, []models.Transaction{drip})
+	require.NotNil(t, matches[0])
+	assert.Equal(t, stored.TransactionID, *matches[0])
+
+	fee := newTestCashMovement(types.TradeTypeFee, "IB", 1.23456, day)
+	storedFee := fee
+	storedFee.TransactionID = uuid.New()
+	storedFee.Amount = 1.23
+	assert.NotNil(t, services.MatchDuplicates([]models.Transaction{storedFee}, []models.Transaction{fee})[0])
+}
@@ -0,0 +1,175 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/api/handlers"
+	"github.com/transaction-tracker/backend/internal/exporter"
+	"github.com/transaction-tracker/backend/internal/importer"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+var exportedTransactions = []types.TransactionData{
+	{ID: uuid.NewString(), TransactionDate: "2024-01-02", TradeType: types.TradeTypeBuy, Symbol: "AAPL", Quantity: 10, Price: 185.25, Amount: 1852.5, Currency: "USD", Broker: "Schwab", UserNotes: `Bought "the dip", <finally> & more`},
+	{ID: uuid.NewString(), TransactionDate: "2024-01-03", TradeType: types.TradeTypeWithdrawal, Symbol: "$CASH", Amount: 250, Currency: "USD", Broker: "Schwab"},
+	{ID: uuid.NewString(), TransactionDate: "2024-06-10", TradeType: types.TradeTypeSplit, Symbol: "NVDA", Currency: "USD", Broker: "Schwab", SplitRatio: 10},
+}
+
+func exportTransactions(t *testing.T, format exporter.Format) []byte {
+	var buf bytes.Buffer
+	writer, err := exporter.NewWriter(format, &buf)
+	require.NoError(t, err)
+	for _, transaction := range exportedTransactions {
+		require.NoError(t, writer.Write(transaction))
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestExportCSV_RoundTripsThroughImport(t *testing.T) {
+	content := exportTransactions(t, exporter.FormatCSV)
+
+	profile, ok := importer.LookupProfile("generic")
+	require.True(t, ok)
+	result, err := importer.ParseCSV(bytes.NewReader(content), profile)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, len(exportedTransactions))
+
+	for i, row := range result.Rows {
+		expected := exportedTransactions[i]
+		assert.Empty(t, row.Error)
+		assert.Equal(t, expected.TransactionDate, row.Transaction.TransactionDate)
+		assert.Equal(t, expected.TradeType, row.Transaction.TradeType)
+		assert.Equal(t, expected.Quantity, row.Transaction.Quantity)
+		assert.Equal(t, expected.Price, row.Transaction.Price)
+		assert.Equal(t, expected.Amount, row.Transaction.Amount)
+		assert.Equal(t, expected.SplitRatio, row.Transaction.SplitRatio)
+		assert.Equal(t, expected.UserNotes, row.Transaction.UserNotes)
+	}
+	assert.Equal(t, "AAPL", result.Rows[0].Transaction.Symbol)
+}
+
+func TestExportJSON(t *testing.T) {
+	var decoded []types.TransactionData
+	require.NoError(t, json.Unmarshal(exportTransactions(t, exporter.FormatJSON), &decoded))
+	assert.Equal(t, exportedTransactions, decoded)
+
+	// An empty export is still a valid array
+	var buf bytes.Buffer
+	writer, err := exporter.NewWriter(exporter.FormatJSON, &buf)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	assert.Equal(t, "[]", buf.String())
+}
+
+func TestExportXLSX(t *testing.T) {
+	content := exportTransactions(t, exporter.FormatXLSX)
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+
+	parts := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		parts[file.Name] = file
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		assert.Contains(t, parts, name)
+	}
+	require.Contains(t, parts, "xl/worksheets/sheet1.xml")
+
+	sheetFile, err := parts["xl/worksheets/sheet1.xml"].Open()
+	require.NoError(t, err)
+	defer sheetFile.Close()
+	sheetXML, err := io.ReadAll(sheetFile)
+	require.NoError(t, err)
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	require.NoError(t, xml.Unmarshal(sheetXML, &sheet))
+	require.Len(t, sheet.Rows, len(exportedTransactions)+1)
+
+	header := sheet.Rows[0].Cells
+	require.Len(t, header, len(exporter.Columns))
+	assert.Equal(t, "transaction_id", header[0].Inline)
+
+	buy := sheet.Rows[1].Cells
+	assert.Equal(t, "inlineStr", buy[3].Type)
+	assert.Equal(t, "AAPL", buy[3].Inline)
+	assert.Equal(t, "", buy[4].Type)
+	assert.Equal(t, "10", buy[4].Value)
+	assert.Equal(t, "1852.5", buy[6].Value)
+	assert.Equal(t, exportedTransactions[0].UserNotes, buy[12].Inline)
+}
+
+func TestExportTransactions_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewTransactionsHandler(nil, nil)
+	router := gin.New()
+	router.GET("/transaction-history/export", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.ExportTransactions(c)
+	})
+
+	for _, query := range []string{"format=pdf", "format=csv&trade_type=Bogus", "format=xlsx&timeframe=2024-13-01"} {
+		req := httptest.NewRequest(http.MethodGet, "/transaction-history/export?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestTransactionFilter_TradeTypeMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewTransactionsHandler(nil, nil)
+	router := gin.New()
+	router.GET("/transaction-history/export", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.ExportTransactions(c)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/transaction-history/export?trade_type=Deposit,DividendReinvestment", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The message lists every stored trade type; a reinvested dividend is stored as its two halves
+	var response handlers.GetTransactionsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"Must be one of: Buy, Sell, Dividends, Split, Deposit, Withdrawal, Fee, Interest, Tax (comma-separated for multiple)"}, response.Errors["trade_type"])
+}
+
+func TestExportCSV_EscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := exporter.NewWriter(exporter.FormatCSV, &buf)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(types.TransactionData{TransactionDate: "2024-01-02", TradeType: types.TradeTypeWithdrawal, Symbol: "$CASH", Amount: -250, Currency: "USD", Broker: "@broker", UserNotes: `=HYPERLINK("http://example.com")`}))
+	require.NoError(t, writer.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	// Text cells starting with a formula character are quoted; numbers are written as they are
+	row := records[1]
+	assert.Equal(t, "-250", row[6])
+	assert.Equal(t, "'@broker", row[8])
+	assert.Equal(t, `'=HYPERLINK("http://example.com")`, row[12])
+}
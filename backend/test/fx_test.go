@@ -0,0 +1,121 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func TestSymbolCurrency(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	first := newTestTransaction(types.TradeTypeBuy, 10, 100, day(1))
+	first.Currency = "usd"
+	second := newTestTransaction(types.TradeTypeBuy, 10, 100, day(2))
+	second.Currency = "twd"
+
+	assert.Equal(t, "TWD", services.SymbolCurrency([]models.Transaction{second, first}))
+	assert.Equal(t, models.DefaultBaseCurrency, services.SymbolCurrency(nil))
+}
+
+func TestConvertHolding(t *testing.T) {
+	holding := models.SingleHolding{
+		Symbol:             "2330.TW",
+		NativeCurrency:     "TWD",
+		TotalQuantity:      100,
+		TotalCost:          50000,
+		UnitCost:           500,
+		CurrentPrice:       600,
+		MarketValue:        60000,
+		UnrealizedGainLoss: 10000,
+		DividendIncome:     1000,
+		TotalReturnRate:    22,
+	}
+
+	converted := services.ConvertHolding(holding, "USD", 0.032)
+	assert.Equal(t, "USD", converted.Currency)
+	assert.Equal(t, "TWD", converted.NativeCurrency)
+	assert.Equal(t, 0.032, converted.FXRate)
+	assert.InDelta(t, 1600, converted.TotalCost, 1e-9)
+	assert.InDelta(t, 19.2, converted.CurrentPrice, 1e-9)
+	assert.InDelta(t, 1920, converted.MarketValue, 1e-9)
+	assert.InDelta(t, 320, converted.UnrealizedGainLoss, 1e-9)
+	assert.InDelta(t, 32, converted.DividendIncome, 1e-9)
+	// Quantities and return rates are currency independent
+	assert.Equal(t, 100.0, converted.TotalQuantity)
+	assert.Equal(t, 22.0, converted.TotalReturnRate)
+
+	same := services.ConvertHolding(holding, "TWD", 1)
+	assert.Equal(t, "TWD", same.Currency)
+	assert.Equal(t, holding.MarketValue, same.MarketValue)
+}
+
+func TestConvertTransactionAmounts(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	usd := newTestTransaction(types.TradeTypeBuy, 10, 100, day)
+	eur := newTestTransaction(types.TradeTypeBuy, 10, 100, day)
+	eur.Currency = "EUR"
+
+	transactions := []models.Transaction{usd, eur}
+	assert.ElementsMatch(t, []string{"USD", "EUR"}, services.TransactionCurrencies(transactions))
+
+	rates := map[string]float64{"USD": 1, "EUR": 1.1}
+	converted := services.ConvertTransactionAmounts(transactions, func(currency string, _ time.Time) (float64, bool) {
+		rate, ok := rates[currency]
+		return rate, ok
+	})
+	assert.InDelta(t, 1000, converted[0].Amount, 1e-9)
+	assert.InDelta(t, 1100, converted[1].Amount, 1e-9)
+	assert.InDelta(t, 110, converted[1].Price, 1e-9)
+	// The originals are left untouched
+	assert.Equal(t, 1000.0, transactions[1].Amount)
+}
+
+func TestConvertTransactionAmounts_RateOfTransactionDate(t *testing.T) {
+	bought := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	sold := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	buy := newTestTransaction(types.TradeTypeBuy, 10, 100, bought)
+	sell := newTestTransaction(types.TradeTypeSell, 10, 100, sold)
+	buy.Currency, sell.Currency = "EUR", "EUR"
+
+	// The euro strengthens between the buy and today
+	series := services.NewFXRateSeries([]provider.FXRatePoint{
+		{Date: "2024-01-02", Rate: 1.1},
+		{Date: "2026-10-16", Rate: 1.2},
+	})
+	converted := services.ConvertTransactionAmounts([]models.Transaction{buy, sell}, func(currency string, date time.Time) (float64, bool) {
+		return series.RateOn(date)
+	})
+
+	// Each cash flow is converted as of its own date, not at today's rate
+	assert.InDelta(t, 1100, converted[0].Amount, 1e-9)
+	assert.InDelta(t, 1200, converted[1].Amount, 1e-9)
+}
+
+func TestFXRateSeries_RateOn(t *testing.T) {
+	series := services.NewFXRateSeries([]provider.FXRatePoint{
+		{Date: "2025-01-13", Rate: 1.03},
+		{Date: "2025-01-06", Rate: 1.04},
+		{Date: "2025-01-20", Rate: 1.05},
+	})
+
+	rate, ok := series.RateOn(time.Date(2025, 1, 13, 15, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, 1.03, rate)
+
+	// Between points the earlier rate carries forward
+	rate, ok = series.RateOn(time.Date(2025, 1, 18, 0, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, 1.03, rate)
+
+	rate, ok = series.RateOn(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, 1.05, rate)
+
+	_, ok = series.RateOn(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+}
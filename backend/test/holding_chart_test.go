@@ -0,0 +1,63 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func TestBuildHoldingChart(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 16, 0, 0, 0, time.UTC) }
+
+	before := newTestTransaction(types.TradeTypeBuy, 10, 100, day(1))
+	buy := newTestTransaction(types.TradeTypeBuy, 10, 120, time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC))
+	sell := newTestTransaction(types.TradeTypeSell, 5, 130, day(5))
+	transactions := []models.Transaction{sell, buy, before}
+
+	prices := map[string]*services.PriceSeries{
+		"AAPL": services.NewPriceSeries([]provider.ClosePrice{
+			{Date: "2024-01-02", Price: 110},
+			{Date: "2024-01-04", Price: 125},
+		}),
+	}
+	valuer := services.NewPortfolioValuer(transactions, prices, nil)
+	timePoints := []time.Time{day(2), day(3), day(4), day(5)}
+
+	dataPoints, events := services.BuildHoldingChart("AAPL", transactions, valuer, models.CostBasisFIFO, timePoints)
+	require.Len(t, dataPoints, 4)
+
+	assert.Equal(t, 10.0, dataPoints[0].Quantity)
+	assert.Equal(t, 1100.0, dataPoints[0].MarketValue)
+	assert.Equal(t, 1000.0, dataPoints[0].CostBasis)
+	assert.Equal(t, 100.0, dataPoints[0].UnrealizedGainLoss)
+
+	// The Tuesday close carries over to Wednesday, after the second buy
+	assert.Equal(t, 20.0, dataPoints[1].Quantity)
+	assert.Equal(t, 110.0, dataPoints[1].Price)
+	assert.Equal(t, 2200.0, dataPoints[1].MarketValue)
+	assert.Equal(t, 2200.0, dataPoints[1].CostBasis)
+
+	assert.Equal(t, 2500.0, dataPoints[2].MarketValue)
+	assert.Equal(t, 300.0, dataPoints[2].UnrealizedGainLoss)
+
+	// FIFO relieves the first lot, leaving five shares at 100 and ten at 120
+	assert.Equal(t, 15.0, dataPoints[3].Quantity)
+	assert.Equal(t, 1700.0, dataPoints[3].CostBasis)
+	assert.Equal(t, 1875.0, dataPoints[3].MarketValue)
+
+	// Only transactions within the chart are marked, at the first point reflecting them
+	require.Len(t, events, 2)
+	assert.Equal(t, buy.TransactionID, events[0].TransactionID)
+	assert.Equal(t, types.TradeTypeBuy, events[0].TradeType)
+	assert.Equal(t, 1, events[0].DataPointIndex)
+	assert.Equal(t, types.TradeTypeSell, events[1].TradeType)
+	assert.Equal(t, 5.0, events[1].Quantity)
+	assert.Equal(t, 650.0, events[1].Amount)
+	assert.Equal(t, 3, events[1].DataPointIndex)
+}
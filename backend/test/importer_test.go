@@ -0,0 +1,161 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/api/handlers"
+	"github.com/transaction-tracker/backend/internal/importer"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func parseWithProfile(t *testing.T, name, content string) *importer.Result {
+	profile, ok := importer.LookupProfile(name)
+	require.True(t, ok, name)
+	result, err := importer.ParseCSV(strings.NewReader(content), profile)
+	require.NoError(t, err)
+	return result
+}
+
+func TestParseCSV_Schwab(t *testing.T) {
+	content := `"Date","Action","Symbol","Description","Quantity","Price","Fees & Comm","Amount"
+"03/04/2024","Buy","AAPL","APPLE INC","10","$170.50","$1.00","-$1,706.00"
+"03/05/2024 as of 03/04/2024","Sell","MSFT","MICROSOFT CORP","5","$400.00","","$2,000.00"
+"03/06/2024","Qualified Dividend","AAPL","APPLE INC","","","","$2.40"
+"03/07/2024","MoneyLink Transfer","","Tfr BANK","","","","-$500.00"
+"03/08/2024","Journal","","JOURNAL","","","","$10.00"
+"03/09/2024","Stock Split","NVDA","NVIDIA CORP","90","","",""
+"Transactions Total","","","","","","","-$194.00"
+`
+	result := parseWithProfile(t, "schwab", content)
+	assert.Equal(t, 1, result.Skipped)
+	require.Len(t, result.Rows, 6)
+
+	buy := result.Rows[0].Transaction
+	assert.Equal(t, 2, result.Rows[0].Line)
+	assert.Equal(t, types.TradeTypeBuy, buy.TradeType)
+	assert.Equal(t, "2024-03-04", buy.TransactionDate)
+	assert.Equal(t, "Schwab", buy.Broker)
+	assert.Equal(t, 10.0, buy.Quantity)
+	assert.Equal(t, 170.5, buy.Price)
+	assert.Equal(t, 1705.0, buy.Amount)
+
+	// The commission follows as a Fee from the same line
+	fee := result.Rows[1]
+	assert.Equal(t, 2, fee.Line)
+	assert.Equal(t, types.TradeTypeFee, fee.Transaction.TradeType)
+	assert.Equal(t, "AAPL", fee.Transaction.Symbol)
+	assert.Equal(t, 1.0, fee.Transaction.Amount)
+
+	assert.Equal(t, "2024-03-05", result.Rows[2].Transaction.TransactionDate)
+	assert.Equal(t, types.TradeTypeDividend, result.Rows[3].Transaction.TradeType)
+	assert.Equal(t, 2.4, result.Rows[3].Transaction.Amount)
+
+	// The sign of a transfer gives its direction
+	assert.Equal(t, types.TradeTypeWithdrawal, result.Rows[4].Transaction.TradeType)
+	assert.Equal(t, 500.0, result.Rows[4].Transaction.Amount)
+
+	assert.Equal(t, `unsupported action "Stock Split"`, result.Rows[5].Error)
+}
+
+func TestParseCSV_Fidelity(t *testing.T) {
+	content := `
+Brokerage
+
+Run Date,Action,Symbol,Description,Type,Quantity,Price ($),Commission ($),Fees ($),Accrued Interest ($),Amount ($),Settlement Date
+01/16/2024,"YOU BOUGHT VANGUARD INDEX FDS S&P 500 ETF (VOO) (Cash)", VOO,VANGUARD INDEX FDS S&P 500 ETF,Cash,2,430.25,,,,-860.5,01/18/2024
+01/17/2024,"DIVIDEND RECEIVED FIDELITY GOVERNMENT MONEY MARKET (SPAXX) (Cash)", SPAXX**,FIDELITY GOVERNMENT MONEY MARKET,Cash,,,,,,1.23,
+01/18/2024,"YOU SOLD VANGUARD INDEX FDS S&P 500 ETF (VOO) (Cash)", VOO,VANGUARD INDEX FDS S&P 500 ETF,Cash,-1,440,,,,440,01/20/2024
+
+"The data and information in this spreadsheet is provided to you solely for your use"
+`
+	result := parseWithProfile(t, "fidelity", content)
+	require.Len(t, result.Rows, 3)
+
+	assert.Equal(t, types.TradeTypeBuy, result.Rows[0].Transaction.TradeType)
+	assert.Equal(t, "VOO", result.Rows[0].Transaction.Symbol)
+	assert.Equal(t, 860.5, result.Rows[0].Transaction.Amount)
+	assert.Equal(t, "SPAXX", result.Rows[1].Transaction.Symbol)
+	assert.Equal(t, 1.23, result.Rows[1].Transaction.Amount)
+	assert.Equal(t, types.TradeTypeSell, result.Rows[2].Transaction.TradeType)
+	assert.Equal(t, 1.0, result.Rows[2].Transaction.Quantity)
+}
+
+func TestParseCSV_IBKRAndRobinhood(t *testing.T) {
+	ibkr := parseWithProfile(t, "ibkr", `"Symbol","TradeDate","Buy/Sell","Quantity","TradePrice","Proceeds","IBCommission","CurrencyPrimary","Exchange"
+"SAP","20240212","SELL","-3","180.2","540.6","-1.25","EUR","IBIS"
+`)
+	require.Len(t, ibkr.Rows, 2)
+	sell := ibkr.Rows[0].Transaction
+	assert.Equal(t, types.TradeTypeSell, sell.TradeType)
+	assert.Equal(t, "EUR", sell.Currency)
+	assert.Equal(t, "IBIS", sell.Exchange)
+	assert.Equal(t, 3.0, sell.Quantity)
+	assert.Equal(t, 1.25, ibkr.Rows[1].Transaction.Amount)
+
+	robinhood := parseWithProfile(t, "robinhood", `"Activity Date","Process Date","Settle Date","Instrument","Description","Trans Code","Quantity","Price","Amount"
+"2/1/2024","2/1/2024","2/5/2024","TSLA","Tesla","Buy","2","$187.50","($375.00)"
+"2/2/2024","2/2/2024","2/2/2024","","ACH Deposit","ACH","","","$1,000.00"
+"2/3/2024","2/3/2024","2/3/2024","","Gold Subscription Fee","GOLD","","","($5.00)"
+`)
+	require.Len(t, robinhood.Rows, 3)
+	assert.Equal(t, "2024-02-01", robinhood.Rows[0].Transaction.TransactionDate)
+	assert.Equal(t, 375.0, robinhood.Rows[0].Transaction.Amount)
+	assert.Equal(t, types.TradeTypeDeposit, robinhood.Rows[1].Transaction.TradeType)
+	assert.Equal(t, types.TradeTypeFee, robinhood.Rows[2].Transaction.TradeType)
+	assert.Equal(t, 5.0, robinhood.Rows[2].Transaction.Amount)
+}
+
+func TestParseCSV_MissingHeader(t *testing.T) {
+	profile, _ := importer.LookupProfile("schwab")
+	_, err := importer.ParseCSV(strings.NewReader("a,b,c\n1,2,3\n"), profile)
+	assert.Error(t, err)
+}
+
+func TestImportTransactions_DryRun(t *testing.T) {
+	content := `transaction_date,trade_type,symbol,quantity,price,amount,currency,broker
+2024-01-02,Buy,AAPL,10,100,1000,USD,IB
+2024-01-03,Sell,AAPL,5,110,999,USD,IB
+2024-01-04,Withdrawal,,,,250,USD,IB
+`
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "history.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteField("profile", "generic"))
+	require.NoError(t, writer.Close())
+
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewTransactionsHandler(nil, nil)
+	router := gin.New()
+	router.POST("/transaction-history/import", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.ImportTransactions(c)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/transaction-history/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response handlers.ImportTransactionsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.Data)
+	assert.True(t, response.Data.DryRun)
+	assert.Equal(t, 3, response.Data.ValidCount)
+	assert.Equal(t, 0, response.Data.InvalidCount)
+	assert.Equal(t, types.TradeTypeWithdrawal, response.Data.Rows[2].Transaction.TradeType)
+	assert.Empty(t, response.Data.Transactions)
+}
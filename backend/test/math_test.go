@@ -59,3 +59,64 @@ func TestXIRR(t *testing.T) {
 		t.Error("XIRR with empty cash flows should be 0")
 	}
 }
+
+func TestTimeWeightedReturn(t *testing.T) {
+	// 100 grows to 110, then a 100 deposit is followed by a fall to 189: 1.1 * 0.9 - 1
+	values := []float64{0, 100, 110, 210, 189}
+	flows := []float64{0, 100, 0, 100, 0}
+	r := utils.TimeWeightedReturn(values, flows)
+	if math.Abs(r-(-0.01)) > 1e-9 {
+		t.Errorf("TimeWeightedReturn = %v, want -0.01", r)
+	}
+
+	if utils.TimeWeightedReturn([]float64{100}, []float64{0}) != 0 {
+		t.Error("TimeWeightedReturn with a single valuation should be 0")
+	}
+}
+
+func TestAnnualizeReturn(t *testing.T) {
+	if r := utils.AnnualizeReturn(0.21, 2); math.Abs(r-0.1) > 1e-9 {
+		t.Errorf("AnnualizeReturn(0.21, 2) = %v, want 0.1", r)
+	}
+	if r := utils.AnnualizeReturn(0.05, 0.5); r != 0.05 {
+		t.Errorf("AnnualizeReturn(0.05, 0.5) = %v, want 0.05", r)
+	}
+}
+
+func TestMeanAndCovariance(t *testing.T) {
+	x := []float64{1, 2, 3, 4}
+	y := []float64{2, 4, 6, 8}
+	if m := utils.Mean(x); m != 2.5 {
+		t.Errorf("Mean(%v) = %v, want 2.5", x, m)
+	}
+	if c := utils.Covariance(x, y); math.Abs(c-2.5) > 1e-9 {
+		t.Errorf("Covariance = %v, want 2.5", c)
+	}
+	if utils.Covariance(x, y[:2]) != 0 {
+		t.Error("Covariance of mismatched slices should be 0")
+	}
+}
+
+func TestDownsideDeviation(t *testing.T) {
+	// Only the -0.02 and -0.04 shortfalls count, averaged over all four returns
+	returns := []float64{0.01, -0.02, 0.03, -0.04}
+	expected := math.Sqrt((0.02*0.02 + 0.04*0.04) / 4)
+	if d := utils.DownsideDeviation(returns, 0); math.Abs(d-expected) > 1e-12 {
+		t.Errorf("DownsideDeviation = %v, want %v", d, expected)
+	}
+	if utils.DownsideDeviation(nil, 0) != 0 {
+		t.Error("DownsideDeviation of no returns should be 0")
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	values := []float64{100, 120, 90, 110, 130, 104, 125}
+	drawdown, peak, trough := utils.MaxDrawdown(values)
+	if math.Abs(drawdown-0.25) > 1e-12 || peak != 1 || trough != 2 {
+		t.Errorf("MaxDrawdown = (%v, %d, %d), want (0.25, 1, 2)", drawdown, peak, trough)
+	}
+
+	if drawdown, _, _ := utils.MaxDrawdown([]float64{1, 2, 3}); drawdown != 0 {
+		t.Errorf("MaxDrawdown of a rising series = %v, want 0", drawdown)
+	}
+}
@@ -0,0 +1,134 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/importer"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func parseOFXFixture(t *testing.T, name string) *importer.Result {
+	file, err := os.Open(filepath.Join("dummy-data", "ofx", name))
+	require.NoError(t, err)
+	defer file.Close()
+
+	result, err := importer.ParseOFX(file)
+	require.NoError(t, err)
+	return result
+}
+
+func TestParseOFX_SGML(t *testing.T) {
+	result := parseOFXFixture(t, "brokerage_v1.ofx")
+	// The repeated FID-0003 and the position transfer are skipped
+	assert.Equal(t, 2, result.Skipped)
+	require.Len(t, result.Rows, 8)
+
+	buy := result.Rows[0]
+	assert.Equal(t, 1, buy.Line)
+	assert.Empty(t, buy.Error)
+	assert.Equal(t, types.TradeTypeBuy, buy.Transaction.TradeType)
+	assert.Equal(t, "AAPL", buy.Transaction.Symbol)
+	assert.Equal(t, "2024-01-05", buy.Transaction.TransactionDate)
+	assert.Equal(t, 10.0, buy.Transaction.Quantity)
+	assert.Equal(t, 185.25, buy.Transaction.Price)
+	assert.Equal(t, 1852.5, buy.Transaction.Amount)
+	assert.Equal(t, "USD", buy.Transaction.Currency)
+	assert.Equal(t, "Fidelity", buy.Transaction.Broker)
+	assert.Equal(t, "fidelity.com/X12345678/FID-0001", buy.Transaction.ExternalID)
+	assert.Equal(t, "YOU BOUGHT APPLE INC", buy.Transaction.UserNotes)
+
+	// Commission and fees follow as one Fee with its own external ID
+	fee := result.Rows[1]
+	assert.Equal(t, 1, fee.Line)
+	assert.Equal(t, types.TradeTypeFee, fee.Transaction.TradeType)
+	assert.Equal(t, 5.0, fee.Transaction.Amount)
+	assert.Equal(t, "fidelity.com/X12345678/FID-0001:fee", fee.Transaction.ExternalID)
+
+	sell := result.Rows[2].Transaction
+	assert.Equal(t, types.TradeTypeSell, sell.TradeType)
+	assert.Equal(t, "MSFT", sell.Symbol)
+	assert.Equal(t, 4.0, sell.Quantity)
+	assert.Equal(t, 1553.88, sell.Amount)
+
+	dividend := result.Rows[3].Transaction
+	assert.Equal(t, types.TradeTypeDividend, dividend.TradeType)
+	assert.Equal(t, 2.4, dividend.Amount)
+
+	reinvest := result.Rows[4].Transaction
+	assert.Equal(t, types.TradeTypeDividendReinvestment, reinvest.TradeType)
+	assert.Equal(t, "VOO", reinvest.Symbol)
+	assert.Equal(t, 12.6, reinvest.Amount)
+
+	deposit := result.Rows[5]
+	assert.Equal(t, 6, deposit.Line)
+	assert.Equal(t, types.TradeTypeDeposit, deposit.Transaction.TradeType)
+	assert.Empty(t, deposit.Transaction.Symbol)
+	assert.Equal(t, 5000.0, deposit.Transaction.Amount)
+	assert.Equal(t, "Electronic Funds Transfer Received", deposit.Transaction.UserNotes)
+
+	withdrawal := result.Rows[6].Transaction
+	assert.Equal(t, types.TradeTypeWithdrawal, withdrawal.TradeType)
+	assert.Equal(t, 250.0, withdrawal.Amount)
+
+	// A security missing from the security list cannot be mapped to a ticker
+	unknown := result.Rows[7]
+	assert.Equal(t, 9, unknown.Line)
+	assert.Contains(t, unknown.Error, "999999999")
+}
+
+func TestParseOFX_XML(t *testing.T) {
+	result := parseOFXFixture(t, "brokerage_v2.qfx")
+	assert.Equal(t, 0, result.Skipped)
+	require.Len(t, result.Rows, 5)
+
+	buy := result.Rows[0].Transaction
+	assert.Equal(t, "SAP", buy.Symbol)
+	assert.Equal(t, "EUR", buy.Currency)
+	assert.Equal(t, "broker.example.com", buy.Broker)
+	assert.Equal(t, 180.2, buy.Price)
+	assert.Equal(t, 540.6, buy.Amount)
+	assert.Empty(t, buy.UserNotes)
+	assert.Equal(t, 1.5, result.Rows[1].Transaction.Amount)
+
+	interest := result.Rows[2].Transaction
+	assert.Equal(t, types.TradeTypeInterest, interest.TradeType)
+	assert.Equal(t, "USD", interest.Currency)
+	assert.Equal(t, 0.87, interest.Amount)
+
+	split := result.Rows[3].Transaction
+	assert.Equal(t, types.TradeTypeSplit, split.TradeType)
+	assert.Equal(t, 2.0, split.SplitRatio)
+
+	fee := result.Rows[4].Transaction
+	assert.Equal(t, types.TradeTypeFee, fee.TradeType)
+	assert.Equal(t, 4.99, fee.Amount)
+	assert.Equal(t, "broker.example.com/DE-998877/QFX-4", fee.ExternalID)
+}
+
+func TestParseOFX_AccountsShareFITID(t *testing.T) {
+	result := parseOFXFixture(t, "two_accounts.ofx")
+
+	// The FITID repeated within the first account is skipped; the second account's is its own
+	assert.Equal(t, 1, result.Skipped)
+	require.Len(t, result.Rows, 2)
+	assert.Equal(t, "brokerage.example.com/INDIVIDUAL-1/1001", result.Rows[0].Transaction.ExternalID)
+	assert.Equal(t, "AAPL", result.Rows[0].Transaction.Symbol)
+	assert.Equal(t, "brokerage.example.com/IRA-2/1001", result.Rows[1].Transaction.ExternalID)
+	assert.Equal(t, "MSFT", result.Rows[1].Transaction.Symbol)
+	assert.Equal(t, 3, result.Rows[1].Line)
+	assert.Equal(t, "Example Brokerage", result.Rows[1].Transaction.Broker)
+}
+
+func TestParseOFX_NotOFX(t *testing.T) {
+	_, err := importer.ParseOFX(strings.NewReader("transaction_date,trade_type\n2024-01-02,Buy\n"))
+	assert.Error(t, err)
+
+	// A bank statement has no investment transactions to import
+	_, err = importer.ParseOFX(strings.NewReader("<OFX><BANKMSGSRSV1><STMTTRNRS></STMTTRNRS></BANKMSGSRSV1></OFX>"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/api/handlers"
+	"github.com/transaction-tracker/backend/internal/pdf"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func readPDFFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("dummy-data", "pdf", name))
+	require.NoError(t, err)
+	return data
+}
+
+func TestSplitPages(t *testing.T) {
+	pages, err := pdf.SplitPages(readPDFFixture(t, "statement_3_pages.pdf"))
+	require.NoError(t, err)
+	require.Len(t, pages, 3)
+
+	texts := []string{"Page 1: BUY", "Page 2: SELL", "Page 3: BUY"}
+	for i, page := range pages {
+		assert.True(t, bytes.HasPrefix(page, []byte("%PDF-")), "page %d", i+1)
+		for j, text := range texts {
+			assert.Equal(t, i == j, bytes.Contains(page, []byte(text)), "page %d contains %q", i+1, text)
+		}
+
+		// Every page is a valid document of one page with the font it inherits
+		again, err := pdf.SplitPages(page)
+		require.NoError(t, err, "page %d", i+1)
+		assert.Len(t, again, 1)
+		assert.Contains(t, string(page), "/BaseFont /Helvetica")
+	}
+
+	// Inherited attributes are copied onto the page, and its own attributes win
+	assert.Contains(t, string(pages[0]), "/MediaBox [0 0 612 792]")
+	assert.NotContains(t, string(pages[0]), "/Rotate")
+	assert.Contains(t, string(pages[1]), "/Rotate 90")
+	assert.Contains(t, string(pages[2]), "/MediaBox [0 0 595 842]")
+
+	// The link annotation pointing at another page is left out
+	assert.NotContains(t, string(pages[0]), "/Annot")
+
+	// A stream length given by reference is written directly
+	assert.Regexp(t, regexp.MustCompile(`/Length \d+ >>\nstream\nBT /F1 12 Tf 72 720 Td \(Page 2`), string(pages[1]))
+}
+
+func TestSplitPagesUpTo(t *testing.T) {
+	data := readPDFFixture(t, "statement_3_pages.pdf")
+	pages, err := pdf.SplitPagesUpTo(data, 3)
+	require.NoError(t, err)
+	assert.Len(t, pages, 3)
+
+	// A document over the limit is refused before any page is written
+	pages, err = pdf.SplitPagesUpTo(data, 2)
+	assert.Nil(t, pages)
+	var limitErr *pdf.PageLimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, 3, limitErr.Pages)
+	assert.Equal(t, 2, limitErr.Limit)
+}
+
+func TestSplitPages_ObjectStreams(t *testing.T) {
+	pages, err := pdf.SplitPages(readPDFFixture(t, "confirmations_objstm.pdf"))
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+
+	for i, text := range []string{"Confirm A", "Confirm B"} {
+		assert.Contains(t, string(pages[i]), "/BaseFont /Courier")
+
+		// The compressed content stream is copied as is
+		start := bytes.Index(pages[i], []byte("stream\n")) + len("stream\n")
+		end := bytes.Index(pages[i], []byte("\nendstream"))
+		require.True(t, start > 0 && end > start)
+		reader, err := zlib.NewReader(bytes.NewReader(pages[i][start:end]))
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), text)
+	}
+}
+
+func TestSplitPages_Errors(t *testing.T) {
+	_, err := pdf.SplitPages(readPDFFixture(t, "encrypted.pdf"))
+	assert.ErrorIs(t, err, pdf.ErrEncrypted)
+
+	_, err = pdf.SplitPages([]byte("not a pdf"))
+	assert.Error(t, err)
+}
+
+// objectStreamPDF returns a PDF holding only an object stream with the given dictionary entries
+// and data
+func objectStreamPDF(entries string, data []byte) []byte {
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write(data)
+	writer.Close()
+	return []byte(fmt.Sprintf("%%PDF-1.7\n1 0 obj\n<</Type /ObjStm /Filter /FlateDecode %s /Length %d>>\nstream\n%s\nendstream\nendobj\n",
+		entries, compressed.Len(), compressed.Bytes()))
+}
+
+func TestSplitPages_MalformedInput(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"negative first":      objectStreamPDF("/N 1 /First -5", []byte("2 0 <<>>")),
+		"name in header":      objectStreamPDF("/N 1 /First 8", []byte("/A /B   <<>>")),
+		"negative offset":     objectStreamPDF("/N 1 /First 6", []byte("2 -100<<>>")),
+		"offset past the end": objectStreamPDF("/N 1 /First 8", []byte("2 99999 <<>>")),
+	} {
+		assert.NotPanics(t, func() {
+			_, err := pdf.SplitPages(data)
+			assert.ErrorContains(t, err, "malformed object stream", name)
+		}, name)
+	}
+
+	_, err := pdf.SplitPages([]byte("%PDF-1.7\n1 0 obj\n<</Type /Catalog /Pages " + strings.Repeat("[", 100000) + ">>\nendobj\n"))
+	assert.Error(t, err)
+
+	// A stream that expands far beyond any statement is not decoded
+	_, err = pdf.SplitPages(objectStreamPDF("/N 1 /First 8", make([]byte, 100<<20)))
+	assert.ErrorContains(t, err, "exceeds")
+}
+
+// pageAIClient extracts a fixed transaction for each page by the text on the page
+type pageAIClient struct {
+	inputs []types.FileInput
+}
+
+func (f *pageAIClient) ExtractTransactions(_ context.Context, file types.FileInput) (*types.ExtractResponse, error) {
+	data, err := io.ReadAll(file.Data)
+	if err != nil {
+		return nil, err
+	}
+	f.inputs = append(f.inputs, file)
+
+	buy := types.TransactionData{Symbol: "AAPL", TradeType: types.TradeTypeBuy, Quantity: 10, Price: 150, Amount: 1500, Currency: "USD", TransactionDate: "2024-03-01"}
+	var transactions []types.TransactionData
+	switch {
+	case bytes.Contains(data, []byte("Page 1")):
+		transactions = []types.TransactionData{buy}
+	case bytes.Contains(data, []byte("Page 2")):
+		// Two identical fills on one confirmation are both trades
+		sell := types.TransactionData{Symbol: "MSFT", TradeType: types.TradeTypeSell, Quantity: 5, Price: 400, Amount: 2000, Currency: "USD", TransactionDate: "2024-03-04"}
+		transactions = []types.TransactionData{sell, sell}
+	case bytes.Contains(data, []byte("Page 3")):
+		// The summary page repeats the first trade, with the ID the model made up for it, and has
+		// a second one of it
+		buy.ID = "repeated"
+		transactions = []types.TransactionData{buy, buy}
+	}
+	return &types.ExtractResponse{
+		Success: true,
+		Data:    &types.ExtractResponseData{Transactions: transactions, TransactionCount: len(transactions)},
+	}, nil
+}
+
+func postExtract(t *testing.T, client handlers.AIClient, filename string, content []byte) *httptest.ResponseRecorder {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/extract", handlers.NewExtractTransactionsHandler(nil, client).ExtractTransactions)
+
+	req := httptest.NewRequest(http.MethodPost, "/extract", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestExtractTransactions_PDF(t *testing.T) {
+	client := &pageAIClient{}
+	// Uploaded as application/octet-stream, so the type is detected from the content
+	w := postExtract(t, client, "statement.pdf", readPDFFixture(t, "statement_3_pages.pdf"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.Len(t, client.inputs, 3)
+	for i, input := range client.inputs {
+		assert.Equal(t, "application/pdf", input.MimeType)
+		assert.Equal(t, []string{"statement-page-1.pdf", "statement-page-2.pdf", "statement-page-3.pdf"}[i], input.Filename)
+	}
+
+	var response types.ExtractResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.True(t, response.Success)
+	require.NotNil(t, response.Data)
+	assert.Equal(t, 3, response.Data.PageCount)
+	assert.Equal(t, "statement.pdf", response.Data.FileName)
+	require.Equal(t, 4, response.Data.TransactionCount)
+	require.Len(t, response.Data.Transactions, 4)
+	for i, expected := range []struct {
+		symbol string
+		page   int
+	}{{"AAPL", 1}, {"MSFT", 2}, {"MSFT", 2}, {"AAPL", 3}} {
+		assert.Equal(t, expected.symbol, response.Data.Transactions[i].Symbol, "transaction %d", i)
+		assert.Equal(t, expected.page, response.Data.Transactions[i].Page, "transaction %d", i)
+	}
+}
+
+func TestExtractTransactions_Unsupported(t *testing.T) {
+	client := &pageAIClient{}
+	w := postExtract(t, client, "notes.txt", []byte("BUY 10 AAPL @ 150"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, client.inputs)
+
+	w = postExtract(t, client, "encrypted.pdf", readPDFFixture(t, "encrypted.pdf"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "encrypted")
+	assert.Empty(t, client.inputs)
+}
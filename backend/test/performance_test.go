@@ -0,0 +1,52 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func TestExternalCashFlows(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	// IB tracks cash, so only its deposit crosses the portfolio boundary
+	deposit := newTestCashMovement(types.TradeTypeDeposit, "IB", 1000, day(1))
+	ibBuy := newTestTransaction(types.TradeTypeBuy, 5, 100, day(2))
+	ibBuy.Broker = "IB"
+	ibFee := newTestCashMovement(types.TradeTypeFee, "IB", 5, day(2))
+
+	// Schwab does not, so its trades move money in and out
+	schwabSell := newTestTransaction(types.TradeTypeSell, 2, 120, day(4))
+	schwabSell.Broker = "Schwab"
+	schwabBuy := newTestTransaction(types.TradeTypeBuy, 10, 100, day(3))
+	schwabBuy.Broker = "Schwab"
+	schwabSplit := newTestSplit(2, day(5))
+	schwabSplit.Broker = "Schwab"
+
+	flows := services.ExternalCashFlows([]models.Transaction{schwabSell, deposit, ibBuy, ibFee, schwabBuy, schwabSplit})
+	require.Len(t, flows, 3)
+	assert.Equal(t, day(1), flows[0].Date)
+	assert.Equal(t, 1000.0, flows[0].Amount)
+	assert.Equal(t, "USD", flows[0].Currency)
+	assert.Equal(t, 1000.0, flows[1].Amount)
+	assert.Equal(t, -240.0, flows[2].Amount)
+}
+
+func TestExternalCashFlows_DividendReinvestmentNetsOut(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	flows := services.ExternalCashFlows([]models.Transaction{
+		newTestDividend("AAPL", 50, day),
+		newTestTransaction(types.TradeTypeBuy, 0.5, 100, day),
+	})
+
+	var net float64
+	for _, flow := range flows {
+		net += flow.Amount
+	}
+	assert.InDelta(t, 0, net, 1e-9)
+}
@@ -0,0 +1,136 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/config"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/repositories"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+// countingPriceServer serves current prices and counts the requests made to each path
+func countingPriceServer(t *testing.T, prices map[string]float64) (*httptest.Server, func(path string) int) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		if r.URL.Path != "/api/v1/price/current" {
+			http.Error(w, "not served", http.StatusNotFound)
+			return
+		}
+		var quotes []provider.SymbolCurrentPrice
+		for _, symbol := range strings.Split(r.URL.Query().Get("symbols"), ",") {
+			quotes = append(quotes, provider.SymbolCurrentPrice{Symbol: symbol, CurrentPrice: prices[symbol], Currency: "USD", Timestamp: time.Now()})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(provider.CurrentPricesResponse{Success: true, Data: quotes, Timestamp: time.Now()}))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[path]
+	}
+}
+
+func TestGetPortfolioSummary_ValuesHistoryFromSnapshots(t *testing.T) {
+	db := utils.SetupTestDB(t)
+	user, err := createTestUser(db, "summary@example.com")
+	require.NoError(t, err)
+
+	today := services.SnapshotDay(time.Now())
+	var transactions []models.Transaction
+	for i, symbol := range []string{"AAPL", "MSFT", "NVDA"} {
+		transactions = append(transactions, models.Transaction{
+			TransactionID:   uuid.New(),
+			UserID:          user.UserID,
+			Symbol:          symbol,
+			TradeType:       types.TradeTypeBuy,
+			Quantity:        10,
+			Price:           100,
+			Amount:          1000,
+			Currency:        "USD",
+			Broker:          "IB",
+			TransactionDate: today.AddDate(0, 0, -10+i),
+		})
+	}
+	require.NoError(t, db.Create(&transactions).Error)
+
+	// Snapshots cover every day up to yesterday, valued at the trade prices
+	snapshotRepo := repositories.NewPortfolioSnapshotRepository(db)
+	from, yesterday := transactions[0].TransactionDate, today.AddDate(0, 0, -1)
+	snapshots := services.BuildPortfolioSnapshots("USD", transactions, services.NewPortfolioValuer(transactions, nil, nil), models.CostBasisFIFO, from, yesterday)
+	require.NoError(t, snapshotRepo.ReplaceFrom(user.UserID, from, snapshots))
+
+	server, requests := countingPriceServer(t, map[string]float64{"AAPL": 110, "MSFT": 110, "NVDA": 110})
+	priceManager := provider.NewPriceServiceManager(&config.Config{PriceService: config.PriceServiceConfig{
+		BaseURL:    server.URL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+	}})
+	transactionRepo := repositories.NewTransactionRepository(db)
+	portfolioService := services.NewPortfolioService(transactionRepo, repositories.NewUserRepository(db), repositories.NewAccountRepository(db), snapshotRepo, priceManager)
+
+	summary, err := portfolioService.GetPortfolioSummary(context.Background(), user.UserID, services.PortfolioOptions{})
+	require.NoError(t, err)
+	assert.InDelta(t, 3300, summary.MarketValue, 0.01)
+	assert.InDelta(t, 10, summary.TWR, 0.01)
+
+	// Current prices come in one batch, and the history comes from the snapshots alone
+	assert.Equal(t, 1, requests("/api/v1/price/current"))
+	assert.Equal(t, 0, requests("/api/v1/price/historical"))
+}
+
+func TestGetPortfolioSummary_CountsDividendsOfSoldSymbols(t *testing.T) {
+	db := utils.SetupTestDB(t)
+	user, err := createTestUser(db, "dividends@example.com")
+	require.NoError(t, err)
+
+	// AAPL paid a dividend and was then sold in full; MSFT is still held
+	today := services.SnapshotDay(time.Now())
+	transactions := []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 10, 100, today.AddDate(0, -6, 0)),
+		newTestDividend("AAPL", 5, today.AddDate(0, -4, 0)),
+		newTestTransaction(types.TradeTypeSell, 10, 100, today.AddDate(0, -2, 0)),
+		newTestTransaction(types.TradeTypeBuy, 10, 100, today.AddDate(0, -1, 0)),
+	}
+	transactions[3].Symbol = "MSFT"
+	for i := range transactions {
+		transactions[i].UserID = user.UserID
+	}
+	require.NoError(t, db.Create(&transactions).Error)
+
+	server, _ := countingPriceServer(t, map[string]float64{"MSFT": 100})
+	priceManager := provider.NewPriceServiceManager(&config.Config{PriceService: config.PriceServiceConfig{
+		BaseURL:    server.URL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+	}})
+	portfolioService := services.NewPortfolioService(repositories.NewTransactionRepository(db), repositories.NewUserRepository(db),
+		repositories.NewAccountRepository(db), repositories.NewPortfolioSnapshotRepository(db), priceManager)
+
+	summary, err := portfolioService.GetPortfolioSummary(context.Background(), user.UserID, services.PortfolioOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.HoldingsCount)
+	assert.InDelta(t, 5, summary.DividendIncome, 1e-9)
+	assert.InDelta(t, 5, summary.TTMDividendIncome, 1e-9)
+	assert.InDelta(t, 5, summary.TotalReturn, 1e-9)
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func TestClassifyHoldingTerm(t *testing.T) {
+	acquired := time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	// Exactly one year is still short term
+	assert.Equal(t, models.HoldingTermShort, services.ClassifyHoldingTerm(acquired, time.Date(2024, 3, 15, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, models.HoldingTermLong, services.ClassifyHoldingTerm(acquired, time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, models.HoldingTermShort, services.ClassifyHoldingTerm(acquired, time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestBuildRealizedGainsReport(t *testing.T) {
+	transactions := []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 10, 100, time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)),
+		newTestTransaction(types.TradeTypeBuy, 10, 150, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		newTestTransaction(types.TradeTypeSell, 15, 200, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+		newTestTransaction(types.TradeTypeSell, 5, 90, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)),
+	}
+
+	disposals := services.CalculateCostBasis(transactions, models.CostBasisFIFO).Disposals
+
+	report := services.BuildRealizedGainsReport(2024, models.CostBasisFIFO, disposals)
+	assert.Equal(t, 2024, report.Year)
+	require.Len(t, report.Disposals, 3)
+	assert.Equal(t, models.HoldingTermLong, report.Disposals[0].Term)
+	assert.Equal(t, models.HoldingTermShort, report.Disposals[1].Term)
+	assert.Equal(t, models.HoldingTermShort, report.Disposals[2].Term)
+	assert.InDelta(t, 10*(200-100), report.Totals.LongTermGainLoss, 1e-6)
+	assert.InDelta(t, 5*(200-150)+5*(90-150), report.Totals.ShortTermGainLoss, 1e-6)
+	assert.InDelta(t, 1000+250-300, report.Totals.TotalGainLoss, 1e-6)
+	assert.InDelta(t, 3000+450, report.Totals.Proceeds, 1e-6)
+	require.Len(t, report.Symbols, 1)
+	assert.Equal(t, "AAPL", report.Symbols[0].Symbol)
+	assert.Equal(t, report.Totals, report.Symbols[0].RealizedGainTotals)
+
+	empty := services.BuildRealizedGainsReport(2020, models.CostBasisFIFO, disposals)
+	assert.Empty(t, empty.Symbols)
+	assert.Empty(t, empty.Disposals)
+}
@@ -0,0 +1,174 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func findRebalanceTrade(trades []models.RebalanceTrade, symbol string) *models.RebalanceTrade {
+	for i := range trades {
+		if trades[i].Symbol == symbol {
+			return &trades[i]
+		}
+	}
+	return nil
+}
+
+func TestBuildRebalancePlan_SellsAndBuys(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	holdings := []services.RebalanceHolding{
+		{Symbol: "AAPL", Quantity: 70, Price: 10},
+		{Symbol: "BND", Quantity: 30, Price: 10},
+		{Symbol: "OLD", Quantity: 5, Price: 20},
+	}
+	targets := []models.TargetAllocation{
+		{Type: models.TargetTypeSymbol, Key: "AAPL", Weight: 50},
+		{Type: models.TargetTypeSymbol, Key: "BND", Weight: 50},
+	}
+
+	plan := services.BuildRebalancePlan("USD", holdings, targets, 0, services.RebalanceOptions{}, now)
+	assert.Equal(t, 1100.0, plan.MarketValue)
+	assert.Equal(t, 1100.0, plan.TotalValue)
+
+	// Untargeted holdings are sold and their proceeds fund the underweight position
+	aapl := findRebalanceTrade(plan.Trades, "AAPL")
+	require.NotNil(t, aapl)
+	assert.Equal(t, types.TradeTypeSell, aapl.Action)
+	assert.Equal(t, 15.0, aapl.Quantity)
+
+	old := findRebalanceTrade(plan.Trades, "OLD")
+	require.NotNil(t, old)
+	assert.Equal(t, types.TradeTypeSell, old.Action)
+	assert.Equal(t, 5.0, old.Quantity)
+
+	bnd := findRebalanceTrade(plan.Trades, "BND")
+	require.NotNil(t, bnd)
+	assert.Equal(t, types.TradeTypeBuy, bnd.Action)
+	assert.Equal(t, 25.0, bnd.Quantity)
+
+	assert.Equal(t, 250.0, plan.SellAmount)
+	assert.Equal(t, 250.0, plan.BuyAmount)
+	assert.Equal(t, 0.0, plan.CashAfter)
+	for _, position := range plan.Positions {
+		assert.Equal(t, position.TargetWeight, position.ProjectedWeight, position.Symbol)
+	}
+}
+
+func TestBuildRebalancePlan_NoSells(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	holdings := []services.RebalanceHolding{
+		{Symbol: "AAPL", Quantity: 70, Price: 10},
+		{Symbol: "BND", Quantity: 10, Price: 10},
+		{Symbol: "GLD", Quantity: 10, Price: 10},
+	}
+	targets := []models.TargetAllocation{
+		{Type: models.TargetTypeSymbol, Key: "AAPL", Weight: 40},
+		{Type: models.TargetTypeSymbol, Key: "BND", Weight: 40},
+		{Type: models.TargetTypeSymbol, Key: "GLD", Weight: 20},
+	}
+
+	// Shortfalls of 300 and 100 share the 100 of cash in proportion
+	plan := services.BuildRebalancePlan("USD", holdings, targets, 100, services.RebalanceOptions{NoSells: true}, now)
+	require.Len(t, plan.Trades, 2)
+	for _, trade := range plan.Trades {
+		assert.Equal(t, types.TradeTypeBuy, trade.Action)
+	}
+	assert.InDelta(t, 75.0, findRebalanceTrade(plan.Trades, "BND").Amount, 1e-4)
+	assert.InDelta(t, 25.0, findRebalanceTrade(plan.Trades, "GLD").Amount, 1e-4)
+	assert.InDelta(t, 0.0, plan.CashAfter, 1e-4)
+	assert.Equal(t, 0.0, plan.SellAmount)
+}
+
+func TestBuildRebalancePlan_WholeSharesAndMinTradeSize(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	holdings := []services.RebalanceHolding{
+		{Symbol: "AAPL", Quantity: 10, Price: 30},
+		{Symbol: "MSFT", Price: 70},
+		{Symbol: "TINY", Quantity: 1, Price: 5},
+	}
+	targets := []models.TargetAllocation{
+		{Type: models.TargetTypeSymbol, Key: "AAPL", Weight: 50},
+		{Type: models.TargetTypeSymbol, Key: "MSFT", Weight: 50},
+	}
+	opts := services.RebalanceOptions{WholeShares: true, MinTradeSize: 10}
+
+	plan := services.BuildRebalancePlan("USD", holdings, targets, 200, opts, now)
+
+	// 47.5 over target rounds up to 2 shares; the 5 of TINY is below the minimum trade size
+	aapl := findRebalanceTrade(plan.Trades, "AAPL")
+	require.NotNil(t, aapl)
+	assert.Equal(t, 2.0, aapl.Quantity)
+	assert.Nil(t, findRebalanceTrade(plan.Trades, "TINY"))
+
+	// A 252.5 shortfall buys 3 whole shares, never more than the available cash
+	msft := findRebalanceTrade(plan.Trades, "MSFT")
+	require.NotNil(t, msft)
+	assert.Equal(t, 3.0, msft.Quantity)
+	assert.Equal(t, 50.0, plan.CashAfter)
+}
+
+func TestBuildRebalancePlan_AssetClassTargets(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	holdings := []services.RebalanceHolding{
+		{Symbol: "BND", AssetClass: "fixed_income", Quantity: 30, Price: 10},
+		{Symbol: "TLT", AssetClass: "fixed_income", Quantity: 10, Price: 10},
+		{Symbol: "SPY", AssetClass: "etf", Quantity: 50, Price: 10},
+		{Symbol: "AAPL", AssetClass: "equity", Quantity: 10, Price: 10},
+	}
+	targets := []models.TargetAllocation{
+		{Type: models.TargetTypeAssetClass, Key: "fixed_income", Weight: 60},
+		{Type: models.TargetTypeSymbol, Key: "SPY", Weight: 30},
+		{Type: models.TargetTypeAssetClass, Key: "etf", Weight: 10},
+		{Type: models.TargetTypeAssetClass, Key: "crypto", Weight: 5},
+	}
+
+	plan := services.BuildRebalancePlan("USD", holdings, targets, 0, services.RebalanceOptions{}, now)
+
+	weights := make(map[string]float64)
+	for _, position := range plan.Positions {
+		weights[position.Symbol] = position.TargetWeight
+	}
+	// The class weight is split by market value, 3:1 between BND and TLT
+	assert.Equal(t, 45.0, weights["BND"])
+	assert.Equal(t, 15.0, weights["TLT"])
+	assert.Equal(t, 30.0, weights["SPY"])
+	assert.Equal(t, 0.0, weights["AAPL"])
+
+	// SPY's own target takes it out of the etf class, leaving that class without holdings
+	require.Len(t, plan.UnmatchedTargets, 2)
+	assert.Equal(t, "etf", plan.UnmatchedTargets[0].Key)
+	assert.Equal(t, "crypto", plan.UnmatchedTargets[1].Key)
+	assert.Equal(t, "BND", plan.Positions[0].Symbol)
+}
+
+func TestBuildRebalancePlan_OpensTargetedPosition(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	// A targeted symbol that is not held comes in as an empty position at its current price
+	holdings := []services.RebalanceHolding{
+		{Symbol: "AAPL", Quantity: 100, Price: 10},
+		{Symbol: "VTI", Price: 25},
+	}
+	targets := []models.TargetAllocation{
+		{Type: models.TargetTypeSymbol, Key: "AAPL", Weight: 50},
+		{Type: models.TargetTypeSymbol, Key: "VTI", Weight: 50},
+	}
+
+	plan := services.BuildRebalancePlan("USD", holdings, targets, 0, services.RebalanceOptions{}, now)
+	assert.Empty(t, plan.UnmatchedTargets)
+
+	vti := findRebalanceTrade(plan.Trades, "VTI")
+	require.NotNil(t, vti)
+	assert.Equal(t, types.TradeTypeBuy, vti.Action)
+	assert.Equal(t, 20.0, vti.Quantity)
+
+	aapl := findRebalanceTrade(plan.Trades, "AAPL")
+	require.NotNil(t, aapl)
+	assert.Equal(t, types.TradeTypeSell, aapl.Action)
+	assert.Equal(t, 50.0, aapl.Quantity)
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/repositories"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+func TestCalculateReturnStatistics(t *testing.T) {
+	returns := []float64{0.01, -0.005, 0.002, -0.01, 0.008}
+
+	stats := services.CalculateReturnStatistics(returns, 0)
+	growth := 1.0
+	for _, r := range returns {
+		growth *= 1 + r
+	}
+	assert.InDelta(t, math.Pow(growth, 252.0/5)-1, stats.AnnualizedReturn, 1e-9)
+	assert.Greater(t, stats.AnnualizedVolatility, 0.0)
+	assert.Greater(t, stats.DownsideDeviation, 0.0)
+	assert.InDelta(t, stats.AnnualizedReturn/stats.AnnualizedVolatility, stats.SharpeRatio, 1e-9)
+	assert.InDelta(t, stats.AnnualizedReturn/stats.DownsideDeviation, stats.SortinoRatio, 1e-9)
+
+	// A higher risk-free rate lowers both ratios
+	withRiskFree := services.CalculateReturnStatistics(returns, 0.05)
+	assert.Less(t, withRiskFree.SharpeRatio, stats.SharpeRatio)
+	assert.Less(t, withRiskFree.SortinoRatio, stats.SortinoRatio)
+
+	assert.Equal(t, services.ReturnStatistics{}, services.CalculateReturnStatistics([]float64{0.01}, 0))
+}
+
+func TestBeta(t *testing.T) {
+	benchmark := []float64{0.01, -0.02, 0.015, 0.005}
+	halved := make([]float64, len(benchmark))
+	for i, r := range benchmark {
+		halved[i] = r / 2
+	}
+	assert.InDelta(t, 0.5, services.Beta(halved, benchmark), 1e-9)
+	assert.Equal(t, 0.0, services.Beta(halved, []float64{0, 0, 0, 0}))
+	assert.Equal(t, 0.0, services.Beta(halved, benchmark[:2]))
+}
+
+func TestPriceReturns(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 16, 0, 0, 0, time.UTC) }
+	prices := services.NewPriceSeries([]provider.ClosePrice{
+		{Date: "2024-01-03", Price: 100},
+		{Date: "2024-01-04", Price: 110},
+		{Date: "2024-01-08", Price: 99},
+	})
+
+	// No close is known on the 2nd, so the first period is skipped
+	returns, indices := services.PriceReturns(prices, []time.Time{day(2), day(3), day(4), day(5), day(8)})
+	require.Len(t, returns, 3)
+	assert.Equal(t, []int{2, 3, 4}, indices)
+	assert.InDelta(t, 0.1, returns[0], 1e-12)
+	assert.InDelta(t, 0, returns[1], 1e-12)
+	assert.InDelta(t, -0.1, returns[2], 1e-12)
+}
+
+func TestGetRiskMetrics_AllWithoutTransactions(t *testing.T) {
+	db := utils.SetupTestDB(t)
+	user, err := createTestUser(db, "risk-empty@example.com")
+	require.NoError(t, err)
+
+	portfolioService := services.NewPortfolioService(repositories.NewTransactionRepository(db), repositories.NewUserRepository(db), repositories.NewAccountRepository(db), nil, nil)
+	metrics, err := portfolioService.GetRiskMetrics(context.Background(), user.UserID, models.TimeFrameALL, 0.04, services.PortfolioOptions{Benchmark: "SPY"})
+	require.NoError(t, err)
+
+	// An empty history has no trading days rather than every weekday since year 1
+	assert.Equal(t, 0, metrics.TradingDays)
+	assert.False(t, metrics.StartDate.IsZero())
+	assert.Empty(t, metrics.Holdings)
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/repositories"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+	"github.com/transaction-tracker/backend/internal/utils"
+)
+
+func newSnapshotTestPortfolio() ([]models.Transaction, map[string]*services.PriceSeries) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 16, 0, 0, 0, time.UTC) }
+
+	deposit := newTestCashMovement(types.TradeTypeDeposit, "IB", 2000, day(2))
+	buy := newTestTransaction(types.TradeTypeBuy, 10, 100, day(3))
+	buy.Broker = "IB"
+	withdrawal := newTestCashMovement(types.TradeTypeWithdrawal, "IB", 500, day(4))
+	transactions := []models.Transaction{withdrawal, buy, deposit}
+
+	prices := map[string]*services.PriceSeries{
+		"AAPL": services.NewPriceSeries([]provider.ClosePrice{
+			{Date: "2024-01-03", Price: 105},
+			{Date: "2024-01-04", Price: 110},
+		}),
+	}
+	return transactions, prices
+}
+
+func TestBuildPortfolioSnapshots(t *testing.T) {
+	transactions, prices := newSnapshotTestPortfolio()
+	valuer := services.NewPortfolioValuer(transactions, prices, nil)
+
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	snapshots := services.BuildPortfolioSnapshots("USD", transactions, valuer, models.CostBasisFIFO, from, to)
+	require.Len(t, snapshots, 3)
+
+	assert.Equal(t, from, snapshots[0].SnapshotDate)
+	assert.Equal(t, "USD", snapshots[0].Currency)
+	assert.Equal(t, 0.0, snapshots[0].MarketValue)
+	assert.Equal(t, 2000.0, snapshots[0].Cash)
+	assert.Equal(t, 2000.0, snapshots[0].NetFlows)
+
+	assert.Equal(t, 1050.0, snapshots[1].MarketValue)
+	assert.Equal(t, 1000.0, snapshots[1].CostBasis)
+	assert.Equal(t, 1000.0, snapshots[1].Cash)
+	assert.Equal(t, 0.0, snapshots[1].NetFlows)
+
+	assert.Equal(t, 1100.0, snapshots[2].MarketValue)
+	assert.Equal(t, 1000.0, snapshots[2].CostBasis)
+	assert.Equal(t, 500.0, snapshots[2].Cash)
+	assert.Equal(t, -500.0, snapshots[2].NetFlows)
+}
+
+func TestSnapshotsCover(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	snapshots := []models.PortfolioSnapshot{
+		{SnapshotDate: day(2), Currency: "USD"},
+		{SnapshotDate: day(3), Currency: "USD"},
+		{SnapshotDate: day(5), Currency: "USD"},
+	}
+
+	assert.True(t, services.SnapshotsCover(snapshots, "USD", day(2), day(3)))
+	assert.False(t, services.SnapshotsCover(snapshots, "USD", day(2), day(5)))
+	// Snapshots taken in another base currency do not count
+	assert.False(t, services.SnapshotsCover(snapshots, "EUR", day(2), day(3)))
+}
+
+func TestSnapshotTimeline(t *testing.T) {
+	transactions, prices := newSnapshotTestPortfolio()
+	valuer := services.NewPortfolioValuer(transactions, prices, nil)
+
+	snapshots := services.BuildPortfolioSnapshots("USD", transactions, valuer,
+		models.CostBasisFIFO, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC))
+	// Snapshots are taken as stored, so a doctored one shows where each value comes from
+	snapshots[1].MarketValue = 1234
+	timeline := services.NewSnapshotTimeline(snapshots, services.NewPortfolioValuer(transactions, prices, nil))
+
+	assert.Equal(t, 0.0, timeline.ValueAt(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 2000.0, timeline.ValueAt(time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 1234.0+1000, timeline.ValueAt(time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)))
+
+	// After the last snapshot the valuer takes over
+	assert.InDelta(t, 1100.0+500, timeline.ValueAt(time.Date(2024, 1, 4, 20, 0, 0, 0, time.UTC)), 1e-9)
+
+	// Snapshotted flows are dated at the start of their day, later ones as they happened
+	flows := timeline.FlowsIn(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(2024, 1, 4, 20, 0, 0, 0, time.UTC))
+	require.Len(t, flows, 2)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), flows[0].Date)
+	assert.Equal(t, 2000.0, flows[0].Amount)
+	assert.Equal(t, time.Date(2024, 1, 4, 16, 0, 0, 0, time.UTC), flows[1].Date)
+	assert.Equal(t, -500.0, flows[1].Amount)
+
+	assert.Empty(t, timeline.FlowsIn(time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)))
+}
+
+func TestPortfolioSnapshotRepository_ReplaceFromIf(t *testing.T) {
+	db := utils.SetupTestDB(t)
+	user, err := createTestUser(db, "snapshots@example.com")
+	require.NoError(t, err)
+	snapshotRepo := repositories.NewPortfolioSnapshotRepository(db)
+
+	transactions, prices := newSnapshotTestPortfolio()
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	build := func() []models.PortfolioSnapshot {
+		return services.BuildPortfolioSnapshots("USD", transactions, services.NewPortfolioValuer(transactions, prices, nil), models.CostBasisFIFO, from, to)
+	}
+	require.NoError(t, snapshotRepo.ReplaceFrom(user.UserID, from, build()[:1]))
+
+	// Snapshots that are no longer current are rolled back, leaving the stored ones in place
+	written, err := snapshotRepo.ReplaceFromIf(user.UserID, from, build(), func() bool { return false })
+	require.NoError(t, err)
+	assert.False(t, written)
+	stored, err := snapshotRepo.GetByUserIDInRange(user.UserID, from, to)
+	require.NoError(t, err)
+	assert.Len(t, stored, 1)
+
+	written, err = snapshotRepo.ReplaceFromIf(user.UserID, from, build(), func() bool { return true })
+	require.NoError(t, err)
+	assert.True(t, written)
+	stored, err = snapshotRepo.GetByUserIDInRange(user.UserID, from, to)
+	require.NoError(t, err)
+	assert.Len(t, stored, 3)
+}
@@ -0,0 +1,212 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/repositories"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+	"github.com/transaction-tracker/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// setupTaxLotLedger creates a user preferring a cost basis method, with services over the test database
+func setupTaxLotLedger(t *testing.T, method models.CostBasisMethod) (*gorm.DB, *models.User, *services.TaxLotService, *services.TransactionService, *repositories.TaxLotRepository) {
+	db := utils.SetupTestDB(t)
+	user, err := createTestUser(db, "ledger@example.com")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("cost_basis_method", method).Error)
+
+	transactionRepo := repositories.NewTransactionRepository(db)
+	taxLotRepo := repositories.NewTaxLotRepository(db)
+	accountRepo := repositories.NewAccountRepository(db)
+	taxLotService := services.NewTaxLotService(transactionRepo, taxLotRepo, accountRepo, repositories.NewUserRepository(db))
+	transactionService := services.NewTransactionService(transactionRepo, taxLotService, accountRepo, nil)
+
+	return db, user, taxLotService, transactionService, taxLotRepo
+}
+
+// ledgerTransactions buys 10 AAPL at 100, then 5 at 120, and sells 12 at 150
+func ledgerTransactions() []models.Transaction {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	return []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 10, 100, day(1, 2)),
+		newTestTransaction(types.TradeTypeBuy, 5, 120, day(2, 1)),
+		newTestTransaction(types.TradeTypeSell, 12, 150, day(3, 1)),
+	}
+}
+
+func TestGetTaxLots_ClosedLotsAndDisposals(t *testing.T) {
+	db, user, taxLotService, _, taxLotRepo := setupTaxLotLedger(t, models.CostBasisFIFO)
+	transactions := ledgerTransactions()
+	for i := range transactions {
+		transactions[i].UserID = user.UserID
+	}
+	require.NoError(t, db.Create(&transactions).Error)
+
+	// The ledger is built on the first read
+	response, err := taxLotService.GetTaxLots(user.UserID, "AAPL", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.CostBasisFIFO, response.CostBasisMethod)
+
+	require.Len(t, response.ClosedLots, 1)
+	closed := response.ClosedLots[0]
+	assert.Equal(t, transactions[0].TransactionID, closed.TransactionID)
+	require.NotNil(t, closed.ClosedAt)
+	assert.InDelta(t, 0, closed.RemainingQuantity, 1e-9)
+	require.Len(t, closed.Disposals, 1)
+	assert.Equal(t, transactions[2].TransactionID, closed.Disposals[0].SellTransactionID)
+	assert.InDelta(t, 10, closed.Disposals[0].Quantity, 1e-9)
+	assert.InDelta(t, 1000, closed.Disposals[0].CostBasis, 1e-6)
+	assert.InDelta(t, 1500, closed.Disposals[0].Proceeds, 1e-6)
+	assert.InDelta(t, 500, closed.Disposals[0].GainLoss, 1e-6)
+
+	require.Len(t, response.OpenLots, 1)
+	open := response.OpenLots[0]
+	assert.Equal(t, transactions[1].TransactionID, open.TransactionID)
+	assert.InDelta(t, 3, open.RemainingQuantity, 1e-9)
+	assert.InDelta(t, 360, open.CostBasis, 1e-6)
+	require.Len(t, open.Disposals, 1)
+	assert.InDelta(t, 2, open.Disposals[0].Quantity, 1e-9)
+	assert.InDelta(t, 60, open.Disposals[0].GainLoss, 1e-6)
+
+	// and persisted with its disposals
+	stored, err := taxLotRepo.GetByUserIDAndSymbol(user.UserID, "AAPL")
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Len(t, stored[0].Disposals, 1)
+	assert.Len(t, stored[1].Disposals, 1)
+}
+
+func TestGetTaxLots_RebuildsWhenCostBasisMethodChanges(t *testing.T) {
+	db, user, taxLotService, _, taxLotRepo := setupTaxLotLedger(t, models.CostBasisFIFO)
+	transactions := ledgerTransactions()
+	for i := range transactions {
+		transactions[i].UserID = user.UserID
+	}
+	require.NoError(t, db.Create(&transactions).Error)
+
+	_, err := taxLotService.GetTaxLots(user.UserID, "AAPL", "")
+	require.NoError(t, err)
+
+	// Under LIFO the sale relieves the later lot first, leaving 3 shares of the first
+	require.NoError(t, db.Model(user).Update("cost_basis_method", models.CostBasisLIFO).Error)
+	response, err := taxLotService.GetTaxLots(user.UserID, "AAPL", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.CostBasisLIFO, response.CostBasisMethod)
+	require.Len(t, response.OpenLots, 1)
+	assert.Equal(t, transactions[0].TransactionID, response.OpenLots[0].TransactionID)
+	assert.InDelta(t, 3, response.OpenLots[0].RemainingQuantity, 1e-9)
+	require.Len(t, response.ClosedLots, 1)
+	assert.Equal(t, transactions[1].TransactionID, response.ClosedLots[0].TransactionID)
+
+	stored, err := taxLotRepo.GetByUserIDAndSymbol(user.UserID, "AAPL")
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	for _, lot := range stored {
+		assert.Equal(t, models.CostBasisLIFO, lot.CostBasisMethod)
+	}
+}
+
+func TestGetTaxLots_RequestedCostBasisMethod(t *testing.T) {
+	db, user, taxLotService, _, taxLotRepo := setupTaxLotLedger(t, models.CostBasisFIFO)
+	transactions := ledgerTransactions()
+	for i := range transactions {
+		transactions[i].UserID = user.UserID
+	}
+	require.NoError(t, db.Create(&transactions).Error)
+
+	_, err := taxLotService.GetTaxLots(user.UserID, "AAPL", "")
+	require.NoError(t, err)
+
+	// Another method is computed on the fly and leaves the ledger of the preferred method alone
+	response, err := taxLotService.GetTaxLots(user.UserID, "AAPL", models.CostBasisLIFO)
+	require.NoError(t, err)
+	assert.Equal(t, models.CostBasisLIFO, response.CostBasisMethod)
+	require.Len(t, response.OpenLots, 1)
+	assert.Equal(t, transactions[0].TransactionID, response.OpenLots[0].TransactionID)
+
+	stored, err := taxLotRepo.GetByUserIDAndSymbol(user.UserID, "AAPL")
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	for _, lot := range stored {
+		assert.Equal(t, models.CostBasisFIFO, lot.CostBasisMethod)
+	}
+}
+
+func TestTaxLotLedger_RebuiltOnTransactionWrites(t *testing.T) {
+	_, user, _, transactionService, taxLotRepo := setupTaxLotLedger(t, models.CostBasisFIFO)
+	date := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	storedLots := func(symbol string) []models.TaxLot {
+		lots, err := taxLotRepo.GetByUserIDAndSymbol(user.UserID, symbol)
+		require.NoError(t, err)
+		return lots
+	}
+
+	// Creating transactions rebuilds the ledger of their symbol
+	result, err := transactionService.CreateTransactions(user.UserID, []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 10, 100, date),
+		newTestTransaction(types.TradeTypeSell, 4, 150, date.AddDate(0, 1, 0)),
+	}, false)
+	require.NoError(t, err)
+	require.Len(t, result.Created, 2)
+	buy, sell := result.Created[0], result.Created[1]
+
+	lots := storedLots("AAPL")
+	require.Len(t, lots, 1)
+	assert.InDelta(t, 6, lots[0].RemainingQuantity, 1e-9)
+	require.Len(t, lots[0].Disposals, 1)
+	assert.Equal(t, sell.TransactionID, lots[0].Disposals[0].SellTransactionID)
+
+	// Updating a sale relieves the lot by the new quantity
+	_, err = transactionService.UpdateTransaction(user.UserID, sell.TransactionID, "AAPL", sell.Exchange, sell.Broker, "USD",
+		"2024-02-02", string(types.TradeTypeSell), 10, 150, 1500, "", nil, 0, nil)
+	require.NoError(t, err)
+	lots = storedLots("AAPL")
+	require.Len(t, lots, 1)
+	assert.False(t, lots[0].IsOpen())
+	assert.InDelta(t, 10, lots[0].Disposals[0].Quantity, 1e-9)
+
+	// Moving the purchase to another symbol rebuilds the ledgers of both symbols
+	_, err = transactionService.UpdateTransaction(user.UserID, buy.TransactionID, "MSFT", buy.Exchange, buy.Broker, "USD",
+		"2024-01-02", string(types.TradeTypeBuy), 10, 100, 1000, "", nil, 0, nil)
+	require.NoError(t, err)
+	assert.Empty(t, storedLots("AAPL"))
+	lots = storedLots("MSFT")
+	require.Len(t, lots, 1)
+	assert.InDelta(t, 10, lots[0].RemainingQuantity, 1e-9)
+
+	// Deleting the purchase empties its ledger
+	require.NoError(t, transactionService.DeleteTransaction(user.UserID, buy.TransactionID))
+	assert.Empty(t, storedLots("MSFT"))
+}
+
+func TestGetTaxLots_RebuildsLedgerAfterFailedSync(t *testing.T) {
+	db, user, taxLotService, transactionService, _ := setupTaxLotLedger(t, models.CostBasisFIFO)
+	date := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	_, err := transactionService.CreateTransactions(user.UserID, []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 10, 100, date),
+	}, false)
+	require.NoError(t, err)
+
+	// The sale is stored, but its ledger rebuild fails while the disposals table is unavailable
+	require.NoError(t, db.Migrator().RenameTable("tax_lot_disposals", "tax_lot_disposals_unavailable"))
+	_, err = transactionService.CreateTransactions(user.UserID, []models.Transaction{
+		newTestTransaction(types.TradeTypeSell, 4, 150, date.AddDate(0, 1, 0)),
+	}, false)
+	require.NoError(t, db.Migrator().RenameTable("tax_lot_disposals_unavailable", "tax_lot_disposals"))
+	require.NoError(t, err)
+
+	// The next read rebuilds the stale ledger
+	response, err := taxLotService.GetTaxLots(user.UserID, "AAPL", "")
+	require.NoError(t, err)
+	require.Len(t, response.OpenLots, 1)
+	assert.InDelta(t, 6, response.OpenLots[0].RemainingQuantity, 1e-9)
+	assert.Len(t, response.OpenLots[0].Disposals, 1)
+}
@@ -27,10 +27,14 @@ func setupTestTransactionsHandler(t *testing.T) (*handlers.TransactionsHandler,
 
 	// Create repositories and services
 	transactionRepo := repositories.NewTransactionRepository(db)
-	transactionService := services.NewTransactionService(transactionRepo)
+	userRepo := repositories.NewUserRepository(db)
+	accountRepo := repositories.NewAccountRepository(db)
+	taxLotService := services.NewTaxLotService(transactionRepo, repositories.NewTaxLotRepository(db), accountRepo, userRepo)
+	transactionService := services.NewTransactionService(transactionRepo, taxLotService, accountRepo, nil)
+	portfolioService := services.NewPortfolioService(transactionRepo, userRepo, accountRepo, nil, nil)
 
 	// Create transactions handler without AI client (extraction moved to separate handler)
-	transactionsHandler := handlers.NewTransactionsHandler(transactionService)
+	transactionsHandler := handlers.NewTransactionsHandler(transactionService, portfolioService)
 
 	return transactionsHandler, db, nil
 }
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/provider"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func TestPortfolioValuer_ValueAt(t *testing.T) {
+	// 2024-01-05 is a Friday
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 16, 0, 0, 0, time.UTC) }
+
+	deposit := newTestCashMovement(types.TradeTypeDeposit, "IB", 2000, day(2))
+	buy := newTestTransaction(types.TradeTypeBuy, 10, 100, day(3))
+	buy.Broker = "IB"
+	split := newTestSplit(2, day(9))
+	split.Broker = "IB"
+	transactions := []models.Transaction{split, buy, deposit}
+
+	prices := map[string]*services.PriceSeries{
+		"AAPL": services.NewPriceSeries([]provider.ClosePrice{
+			{Date: "2024-01-04", Price: 110},
+			{Date: "2024-01-05", Price: 120},
+			{Date: "2024-01-09", Price: 65},
+		}),
+	}
+	valuer := services.NewPortfolioValuer(transactions, prices, nil)
+
+	// Before the first close the position is valued at its trade price
+	assert.InDelta(t, 2000, valuer.ValueAt(day(3)), 1e-9)
+	assert.InDelta(t, 1000+1100, valuer.ValueAt(day(4)), 1e-9)
+
+	// The Friday close carries over the weekend
+	assert.InDelta(t, 1000+1200, valuer.ValueAt(day(6)), 1e-9)
+	assert.InDelta(t, 1000+1200, valuer.ValueAt(day(8)), 1e-9)
+
+	// After the split there are twice the shares at the new close
+	assert.InDelta(t, 1000+1300, valuer.ValueAt(day(9)), 1e-9)
+
+	// Going back in time replays from the start
+	assert.InDelta(t, 2000, valuer.ValueAt(day(2)), 1e-9)
+	assert.InDelta(t, 0, valuer.ValueAt(day(1)), 1e-9)
+	assert.InDelta(t, 1000+1200, valuer.ValueAt(day(5)), 1e-9)
+}
+
+func TestHeldSymbols(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	withSymbol := func(tx models.Transaction, symbol string) models.Transaction {
+		tx.Symbol = symbol
+		return tx
+	}
+
+	transactions := []models.Transaction{
+		// Closed before the window
+		withSymbol(newTestTransaction(types.TradeTypeBuy, 5, 10, day(1)), "GONE"),
+		withSymbol(newTestTransaction(types.TradeTypeSell, 5, 10, day(2)), "GONE"),
+		// Open at the start of the window and closed inside it
+		withSymbol(newTestTransaction(types.TradeTypeBuy, 5, 10, day(3)), "SOLD"),
+		withSymbol(newTestTransaction(types.TradeTypeSell, 5, 10, day(12)), "SOLD"),
+		// Open throughout
+		newTestTransaction(types.TradeTypeBuy, 5, 10, day(4)),
+		// Bought after the window
+		withSymbol(newTestTransaction(types.TradeTypeBuy, 5, 10, day(25)), "LATER"),
+		newTestCashMovement(types.TradeTypeDeposit, "IB", 1000, day(1)),
+	}
+
+	assert.Equal(t, []string{"AAPL", "SOLD"}, services.HeldSymbols(transactions, day(10), day(20)))
+}
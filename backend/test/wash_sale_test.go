@@ -0,0 +1,104 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/backend/internal/models"
+	"github.com/transaction-tracker/backend/internal/services"
+	"github.com/transaction-tracker/backend/internal/types"
+)
+
+func TestWashSale_ReplacementPurchasedAfterLoss(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	transactions := []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 10, 100, day(1, 2)),
+		newTestTransaction(types.TradeTypeSell, 10, 80, day(2, 15)),
+		newTestTransaction(types.TradeTypeBuy, 10, 85, day(3, 1)),
+	}
+
+	result := services.CalculateCostBasis(transactions, models.CostBasisFIFO)
+	require.Len(t, result.Disposals, 1)
+	disposal := result.Disposals[0]
+	assert.True(t, disposal.WashSale)
+	assert.InDelta(t, 200, disposal.DisallowedLoss, 1e-6)
+	assert.InDelta(t, 0, disposal.GainLoss, 1e-6)
+	assert.Equal(t, transactions[2].TransactionID, disposal.ReplacementTransactionIDs[0])
+
+	// The disallowed loss moves into the replacement lot's basis
+	require.Len(t, result.OpenLots, 1)
+	assert.InDelta(t, 105, result.OpenLots[0].UnitCost, 1e-6)
+	assert.InDelta(t, 1050, result.TotalCost, 1e-6)
+
+	flags := services.BuildWashSaleFlags(result.WashSales)
+	require.Len(t, flags, 2)
+	assert.Equal(t, types.WashSaleRoleLossSale, flags[transactions[1].TransactionID].Role)
+	assert.Equal(t, types.WashSaleRoleReplacement, flags[transactions[2].TransactionID].Role)
+	assert.InDelta(t, 200, flags[transactions[2].TransactionID].Amount, 1e-6)
+}
+
+func TestWashSale_PartialReplacementHeldBeforeLoss(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	transactions := []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 10, 100, day(1, 2)),
+		newTestTransaction(types.TradeTypeBuy, 5, 90, day(2, 20)),
+		newTestTransaction(types.TradeTypeSell, 10, 80, day(3, 1)),
+	}
+
+	result := services.CalculateCostBasis(transactions, models.CostBasisFIFO)
+	require.Len(t, result.Disposals, 1)
+	assert.True(t, result.Disposals[0].WashSale)
+	// Only the 5 replacement shares disallow their part of the 200 loss
+	assert.InDelta(t, 100, result.Disposals[0].DisallowedLoss, 1e-6)
+	assert.InDelta(t, -100, result.RealizedGainLoss, 1e-6)
+	require.Len(t, result.OpenLots, 1)
+	assert.InDelta(t, 110, result.OpenLots[0].UnitCost, 1e-6)
+}
+
+func TestWashSale_OutsideWindowOrGain(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	transactions := []models.Transaction{
+		newTestTransaction(types.TradeTypeBuy, 10, 100, day(1, 2)),
+		newTestTransaction(types.TradeTypeSell, 5, 120, day(2, 15)),
+		newTestTransaction(types.TradeTypeSell, 5, 80, day(3, 1)),
+		newTestTransaction(types.TradeTypeBuy, 5, 85, day(4, 15)),
+	}
+
+	result := services.CalculateCostBasis(transactions, models.CostBasisFIFO)
+	require.Len(t, result.Disposals, 2)
+	for _, d := range result.Disposals {
+		assert.False(t, d.WashSale)
+	}
+	assert.Empty(t, result.WashSales)
+	assert.InDelta(t, 100-100, result.RealizedGainLoss, 1e-6)
+}
+
+func TestWashSale_WindowCountsCalendarDays(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	soldAt := time.Date(2024, 3, 1, 10, 0, 0, 0, newYork)
+
+	// The window spans the start of daylight saving time on March 10
+	for _, tc := range []struct {
+		name     string
+		boughtAt time.Time
+		washSale bool
+	}{
+		{"day 30 later in the day", time.Date(2024, 3, 31, 16, 0, 0, 0, newYork), true},
+		{"day 31 earlier in the day", time.Date(2024, 4, 1, 9, 0, 0, 0, newYork), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			transactions := []models.Transaction{
+				newTestTransaction(types.TradeTypeBuy, 10, 100, time.Date(2024, 1, 2, 10, 0, 0, 0, newYork)),
+				newTestTransaction(types.TradeTypeSell, 10, 80, soldAt),
+				newTestTransaction(types.TradeTypeBuy, 10, 85, tc.boughtAt),
+			}
+
+			result := services.CalculateCostBasis(transactions, models.CostBasisFIFO)
+			require.Len(t, result.Disposals, 1)
+			assert.Equal(t, tc.washSale, result.Disposals[0].WashSale)
+		})
+	}
+}
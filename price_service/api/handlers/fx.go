@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/transaction-tracker/price_service/internal/config"
+	"github.com/transaction-tracker/price_service/internal/models"
+	"github.com/transaction-tracker/price_service/internal/provider"
+)
+
+var currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type FXHandler struct {
+	provider provider.FXRateProvider
+	config   *config.Config
+}
+
+func NewFXHandler(provider provider.FXRateProvider, config *config.Config) *FXHandler {
+	return &FXHandler{
+		provider: provider,
+		config:   config,
+	}
+}
+
+// GetCurrentRates handles GET /api/v1/fx/current?base=USD&quotes=EUR,TWD
+func (h *FXHandler) GetCurrentRates(c *gin.Context) {
+	base := strings.TrimSpace(strings.ToUpper(c.Query("base")))
+	if !currencyCodeRegex.MatchString(base) {
+		h.invalidInput(c, "base must be a 3-letter ISO currency code")
+		return
+	}
+
+	quotesParam := c.Query("quotes")
+	if quotesParam == "" {
+		h.invalidInput(c, "quotes parameter is required")
+		return
+	}
+
+	var quotes []string
+	for _, quote := range strings.Split(quotesParam, ",") {
+		quote = strings.TrimSpace(strings.ToUpper(quote))
+		if quote == "" {
+			continue
+		}
+		if !currencyCodeRegex.MatchString(quote) {
+			h.invalidInput(c, fmt.Sprintf("invalid currency code: %s", quote))
+			return
+		}
+		quotes = append(quotes, quote)
+	}
+	if len(quotes) == 0 {
+		h.invalidInput(c, "no valid quote currencies provided")
+		return
+	}
+	if len(quotes) > h.config.Cache.MaxSymbolsPerReq {
+		h.invalidInput(c, "too many currencies requested")
+		return
+	}
+
+	now := time.Now()
+	result := make([]models.FXRate, 0, len(quotes))
+	for _, quote := range quotes {
+		if quote == base {
+			result = append(result, models.FXRate{Base: base, Quote: quote, Rate: 1, Date: now.Format(DateFormat), Timestamp: now})
+			continue
+		}
+
+		rate, err := h.provider.GetCurrentRate(c.Request.Context(), base, quote)
+		if err != nil {
+			h.rateUnavailable(c, base, quote, err)
+			return
+		}
+		result = append(result, *rate)
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success:   true,
+		Data:      result,
+		Timestamp: now,
+	})
+}
+
+// GetHistoricalRates handles GET /api/v1/fx/historical?base=USD&quote=TWD with optional date or from/to
+func (h *FXHandler) GetHistoricalRates(c *gin.Context) {
+	base := strings.TrimSpace(strings.ToUpper(c.Query("base")))
+	quote := strings.TrimSpace(strings.ToUpper(c.Query("quote")))
+	if !currencyCodeRegex.MatchString(base) || !currencyCodeRegex.MatchString(quote) {
+		h.invalidInput(c, "base and quote must be 3-letter ISO currency codes")
+		return
+	}
+
+	dateParam := strings.TrimSpace(c.Query("date"))
+	fromParam := strings.TrimSpace(c.Query("from"))
+	toParam := strings.TrimSpace(c.Query("to"))
+	if err := validateFXDateParameters(dateParam, fromParam, toParam); err != nil {
+		h.invalidInput(c, err.Error())
+		return
+	}
+
+	var history *models.FXHistoricalRates
+	if base == quote {
+		history = sameCurrencyHistory(base, dateParam, fromParam, toParam)
+	} else {
+		var err error
+		history, err = h.provider.GetHistoricalRates(c.Request.Context(), base, quote)
+		if err != nil {
+			h.rateUnavailable(c, base, quote, err)
+			return
+		}
+	}
+
+	result := FilterFXRates(history, dateParam, fromParam, toParam)
+	if dateParam != "" && len(result.Rates) == 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Error: models.ErrorDetail{
+				Code:    models.ErrCurrencyNotFound,
+				Message: fmt.Sprintf("no %s/%s rate available on or before %s", base, quote, dateParam),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success:   true,
+		Data:      result,
+		Timestamp: time.Now(),
+	})
+}
+
+// FilterFXRates narrows newest-first rates to a date range, or to the latest rate on or before a date.
+// FX markets close on weekends, so a single date carries the previous rate forward.
+func FilterFXRates(history *models.FXHistoricalRates, dateParam, fromParam, toParam string) *models.FXHistoricalRates {
+	result := &models.FXHistoricalRates{
+		Base:  history.Base,
+		Quote: history.Quote,
+		Rates: []models.FXRatePoint{},
+	}
+
+	switch {
+	case dateParam != "":
+		for _, point := range history.Rates {
+			if point.Date <= dateParam {
+				result.Rates = append(result.Rates, models.FXRatePoint{Date: point.Date, Rate: point.Rate})
+				break
+			}
+		}
+	case fromParam != "" && toParam != "":
+		for _, point := range history.Rates {
+			if point.Date >= fromParam && point.Date <= toParam {
+				result.Rates = append(result.Rates, point)
+			}
+		}
+	default:
+		result.Rates = append(result.Rates, history.Rates...)
+	}
+
+	return result
+}
+
+// sameCurrencyHistory returns a constant rate of 1 for the requested dates
+func sameCurrencyHistory(currency, dateParam, fromParam, toParam string) *models.FXHistoricalRates {
+	date := dateParam
+	if date == "" {
+		date = toParam
+	}
+	if date == "" {
+		date = time.Now().Format(DateFormat)
+	}
+	return &models.FXHistoricalRates{
+		Base:  currency,
+		Quote: currency,
+		Rates: []models.FXRatePoint{{Date: date, Rate: 1}},
+	}
+}
+
+// validateFXDateParameters validates the combination of date parameters
+func validateFXDateParameters(date, from, to string) error {
+	if date != "" && (from != "" || to != "") {
+		return fmt.Errorf("cannot use 'date' parameter with 'from'/'to' parameters")
+	}
+	if (from != "") != (to != "") {
+		return fmt.Errorf("both 'from' and 'to' parameters are required for date range queries")
+	}
+	for _, value := range []string{date, from, to} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(DateFormat, value); err != nil {
+			return fmt.Errorf("invalid date format, use YYYY-MM-DD")
+		}
+	}
+	if from != "" && from > to {
+		return fmt.Errorf("'from' date must be earlier than or equal to 'to' date")
+	}
+	return nil
+}
+
+func (h *FXHandler) invalidInput(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Success: false,
+		Error: models.ErrorDetail{
+			Code:    models.ErrInvalidInput,
+			Message: message,
+		},
+	})
+}
+
+func (h *FXHandler) rateUnavailable(c *gin.Context, base, quote string, err error) {
+	if strings.Contains(err.Error(), "unsupported currency") {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Error: models.ErrorDetail{
+				Code:    models.ErrCurrencyNotFound,
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+		Success: false,
+		Error: models.ErrorDetail{
+			Code:    models.ErrServiceUnavailable,
+			Message: fmt.Sprintf("failed to fetch %s/%s exchange rate", base, quote),
+		},
+	})
+}
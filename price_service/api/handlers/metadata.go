@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/transaction-tracker/price_service/internal/config"
+	"github.com/transaction-tracker/price_service/internal/logger"
+	"github.com/transaction-tracker/price_service/internal/models"
+	"github.com/transaction-tracker/price_service/internal/provider"
+)
+
+type MetadataHandler struct {
+	provider provider.SecurityMetadataProvider
+	config   *config.Config
+}
+
+func NewMetadataHandler(provider provider.SecurityMetadataProvider, config *config.Config) *MetadataHandler {
+	return &MetadataHandler{
+		provider: provider,
+		config:   config,
+	}
+}
+
+// GetSecurityMetadata handles GET /api/v1/metadata?symbols=AAPL,SPY.
+// Symbols without metadata are left out of the result.
+func (h *MetadataHandler) GetSecurityMetadata(c *gin.Context) {
+	symbolsParam := c.Query("symbols")
+	if symbolsParam == "" {
+		h.invalidInput(c, "symbols parameter is required")
+		return
+	}
+
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, symbol := range strings.Split(symbolsParam, ",") {
+		symbol = strings.TrimSpace(strings.ToUpper(symbol))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		symbols = append(symbols, symbol)
+	}
+	if len(symbols) == 0 {
+		h.invalidInput(c, "no valid symbols provided")
+		return
+	}
+	if len(symbols) > h.config.Cache.MaxSymbolsPerReq {
+		h.invalidInput(c, "too many symbols requested")
+		return
+	}
+
+	result := make([]models.SecurityMetadata, 0, len(symbols))
+	for _, symbol := range symbols {
+		metadata, err := h.provider.GetSecurityMetadata(c.Request.Context(), symbol)
+		if err != nil {
+			if strings.Contains(err.Error(), "unknown symbol") {
+				continue
+			}
+			logger.Warn("Error fetching security metadata", logger.H{"symbol": symbol, "error": err})
+			c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+				Success: false,
+				Error: models.ErrorDetail{
+					Code:    models.ErrServiceUnavailable,
+					Message: "failed to fetch security metadata",
+				},
+			})
+			return
+		}
+		result = append(result, *metadata)
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success:   true,
+		Data:      result,
+		Timestamp: time.Now(),
+	})
+}
+
+func (h *MetadataHandler) invalidInput(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Success: false,
+		Error: models.ErrorDetail{
+			Code:    models.ErrInvalidInput,
+			Message: message,
+		},
+	})
+}
@@ -417,12 +417,13 @@ func (h *PriceHandler) handleResolutionQuery(c *gin.Context, symbol string) {
 	resolution := models.Resolution(resolutionStr)
 	if resolution != models.ResolutionDaily &&
 		resolution != models.ResolutionWeekly &&
-		resolution != models.ResolutionMonthly {
+		resolution != models.ResolutionMonthly &&
+		resolution != models.ResolutionIntraday {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Success: false,
 			Error: models.ErrorDetail{
 				Code:    models.ErrInvalidInput,
-				Message: "invalid resolution (daily, weekly, monthly allowed)",
+				Message: "invalid resolution (daily, weekly, monthly, intraday allowed)",
 			},
 		})
 		return
@@ -29,7 +29,19 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		panic("Failed to initialize stock price provider: " + err.Error())
 	}
 
+	fxProvider, err := provider.NewFXRateProvider(cfg)
+	if err != nil {
+		panic("Failed to initialize FX rate provider: " + err.Error())
+	}
+
+	metadataProvider, err := provider.NewSecurityMetadataProvider(cfg)
+	if err != nil {
+		panic("Failed to initialize security metadata provider: " + err.Error())
+	}
+
 	priceHandler := handlers.NewPriceHandler(cacheService, thirdPartyProviderMap, cfg)
+	fxHandler := handlers.NewFXHandler(fxProvider, cfg)
+	metadataHandler := handlers.NewMetadataHandler(metadataProvider, cfg)
 	cacheHandler := handlers.NewCacheHandler(cacheService)
 
 	rateLimiter := middlewares.NewRateLimiter(cfg.RateLimit.RequestsPerWindow, cfg.RateLimit.WindowDuration)
@@ -57,6 +69,16 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		priceGroup.GET("/historical", priceHandler.GetHistoricalPrices)
 	}
 
+	// FX rate endpoints
+	fxGroup := api.Group("/fx")
+	{
+		fxGroup.GET("/current", fxHandler.GetCurrentRates)
+		fxGroup.GET("/historical", fxHandler.GetHistoricalRates)
+	}
+
+	// Security metadata endpoints
+	api.GET("/metadata", metadataHandler.GetSecurityMetadata)
+
 	// Cache management endpoints
 	api.POST("/invalid-cache", cacheHandler.InvalidateCache)
 
@@ -12,6 +12,8 @@ type Config struct {
 	Server    ServerConfig
 	Redis     RedisConfig
 	StockAPI  StockAPIConfig
+	FX        FXConfig
+	Metadata  MetadataConfig
 	Cache     CacheConfig
 	RateLimit RateLimitConfig
 }
@@ -38,6 +40,16 @@ type ProviderConfig struct {
 	BaseURL string
 }
 
+type FXConfig struct {
+	Provider    string // "alpha_vantage" or "fixture" (offline)
+	FixturePath string // rates file for the fixture provider
+}
+
+type MetadataConfig struct {
+	Provider    string // "fixture" (offline)
+	FixturePath string // optional metadata file for the fixture provider, embedded metadata when empty
+}
+
 type CacheConfig struct {
 	DefaultTTL       time.Duration
 	MaxSymbolsPerReq int
@@ -73,6 +85,14 @@ func Load() (*Config, error) {
 				BaseURL: getEnv("FINNHUB_BASE_URL", "https://finnhub.io/api/v1"),
 			},
 		},
+		FX: FXConfig{
+			Provider:    getEnv("FX_PROVIDER", "alpha_vantage"),
+			FixturePath: getEnv("FX_FIXTURE_PATH", ""),
+		},
+		Metadata: MetadataConfig{
+			Provider:    getEnv("METADATA_PROVIDER", "fixture"),
+			FixturePath: getEnv("METADATA_FIXTURE_PATH", ""),
+		},
 		Cache: CacheConfig{
 			DefaultTTL:       time.Duration(getEnvAsInt("DEFAULT_TTL_MINUTES", 60)) * time.Minute,
 			MaxSymbolsPerReq: getEnvAsInt("MAX_SYMBOLS_PER_REQUEST", 50),
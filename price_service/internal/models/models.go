@@ -12,6 +12,9 @@ const (
 	ResolutionIntraday Resolution = "intraday"
 )
 
+// IntradayTimeFormat is the format of intraday price timestamps, always in UTC
+const IntradayTimeFormat = "2006-01-02T15:04:05Z"
+
 // SymbolCurrentPrice represents current price data for a symbol
 type SymbolCurrentPrice struct {
 	Symbol        string    `json:"symbol"`
@@ -25,7 +28,7 @@ type SymbolCurrentPrice struct {
 
 // ClosePrice represents a date-price pair
 type ClosePrice struct {
-	Date  string  `json:"date"` // YYYY-MM-DD format
+	Date  string  `json:"date"` // YYYY-MM-DD format, or IntradayTimeFormat for intraday prices
 	Price float64 `json:"price"`
 }
 
@@ -36,6 +39,51 @@ type SymbolHistoricalPrice struct {
 	HistoricalPrices []ClosePrice `json:"historical_prices"`
 }
 
+// FXRate represents the rate to convert one unit of Base into Quote
+type FXRate struct {
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Rate      float64   `json:"rate"`
+	Date      string    `json:"date"` // YYYY-MM-DD the rate applies to
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FXRatePoint represents a date-rate pair
+type FXRatePoint struct {
+	Date string  `json:"date"` // YYYY-MM-DD format
+	Rate float64 `json:"rate"`
+}
+
+// FXHistoricalRates represents historical rates for a currency pair, sorted newest to oldest
+type FXHistoricalRates struct {
+	Base  string        `json:"base"`
+	Quote string        `json:"quote"`
+	Rates []FXRatePoint `json:"rates"`
+}
+
+// AssetClass classifies a security by the kind of asset it represents
+type AssetClass string
+
+const (
+	AssetClassEquity      AssetClass = "equity"
+	AssetClassETF         AssetClass = "etf"
+	AssetClassFixedIncome AssetClass = "fixed_income"
+	AssetClassCommodity   AssetClass = "commodity"
+	AssetClassCrypto      AssetClass = "crypto"
+	AssetClassOther       AssetClass = "other"
+)
+
+// SecurityMetadata represents descriptive data about a security
+type SecurityMetadata struct {
+	Symbol     string     `json:"symbol"`
+	Name       string     `json:"name"`
+	Sector     string     `json:"sector"`
+	Industry   string     `json:"industry"`
+	Country    string     `json:"country"` // ISO 3166-1 alpha-2 code of the issuer's domicile
+	AssetClass AssetClass `json:"asset_class"`
+	Exchange   string     `json:"exchange"`
+}
+
 // Error response structure
 type ErrorCode string
 
@@ -46,6 +94,7 @@ const (
 	ErrServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
 	ErrInvalidInput       ErrorCode = "INVALID_INPUT"
 	ErrUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrCurrencyNotFound   ErrorCode = "CURRENCY_NOT_FOUND"
 )
 
 // ErrorResponse represents the standard error response format
@@ -38,6 +38,9 @@ func NewAlphaVantageProvider(apiKey string) *AlphaVantageProvider {
 }
 
 func (a *AlphaVantageProvider) GetHistoricalPrices(ctx context.Context, symbol string, resolution models.Resolution) (*models.SymbolHistoricalPrice, error) {
+	if resolution == models.ResolutionIntraday {
+		return a.getIntradayPrices(ctx, symbol)
+	}
 
 	params := url.Values{}
 	// Map our resolution to Alpha Vantage function
@@ -159,6 +162,69 @@ func (a *AlphaVantageProvider) GetHistoricalPrices(ctx context.Context, symbol s
 	}, nil
 }
 
+// getIntradayPrices returns the hourly closes of the last trading days, newest first. Alpha Vantage
+// stamps bars in the exchange's time zone, so each is converted into a UTC timestamp.
+func (a *AlphaVantageProvider) getIntradayPrices(ctx context.Context, symbol string) (*models.SymbolHistoricalPrice, error) {
+	params := url.Values{}
+	params.Set("function", "TIME_SERIES_INTRADAY")
+	params.Set("symbol", symbol)
+	params.Set("interval", "60min")
+	params.Set("outputsize", "compact") // the latest 100 bars, about two weeks of trading hours
+	params.Set("apikey", a.APIKey)
+
+	resp, err := a.makeRequest(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		MetaData   map[string]string            `json:"Meta Data"`
+		TimeSeries map[string]map[string]string `json:"Time Series (60min)"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Alpha Vantage response: %w", err)
+	}
+	if len(result.TimeSeries) == 0 {
+		return nil, fmt.Errorf("no intraday data returned for symbol %s", symbol)
+	}
+
+	location := time.UTC
+	if zone := result.MetaData["6. Time Zone"]; zone != "" {
+		if loaded, err := time.LoadLocation(zone); err == nil {
+			location = loaded
+		} else {
+			logger.Warn("Unknown Alpha Vantage time zone, assuming UTC", logger.H{"symbol": symbol, "time_zone": zone})
+		}
+	}
+
+	prices := make([]models.ClosePrice, 0, len(result.TimeSeries))
+	for stamp, data := range result.TimeSeries {
+		barTime, err := time.ParseInLocation("2006-01-02 15:04:05", stamp, location)
+		if err != nil {
+			continue
+		}
+		price, err := strconv.ParseFloat(data["4. close"], 64)
+		if err != nil {
+			continue
+		}
+		prices = append(prices, models.ClosePrice{
+			Date:  barTime.UTC().Format(models.IntradayTimeFormat),
+			Price: price,
+		})
+	}
+
+	// UTC timestamps sort as strings, newest first like the other resolutions
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Date > prices[j].Date
+	})
+
+	return &models.SymbolHistoricalPrice{
+		Symbol:           symbol,
+		Resolution:       models.ResolutionIntraday,
+		HistoricalPrices: prices,
+	}, nil
+}
+
 func (a *AlphaVantageProvider) makeRequest(ctx context.Context, params url.Values) ([]byte, error) {
 	reqURL := fmt.Sprintf("%s?%s", a.BaseURL, params.Encode())
 
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/transaction-tracker/price_service/internal/models"
+)
+
+// GetCurrentRate uses the Alpha Vantage CURRENCY_EXCHANGE_RATE function
+func (a *AlphaVantageProvider) GetCurrentRate(ctx context.Context, base, quote string) (*models.FXRate, error) {
+	params := url.Values{}
+	params.Set("function", "CURRENCY_EXCHANGE_RATE")
+	params.Set("from_currency", strings.ToUpper(base))
+	params.Set("to_currency", strings.ToUpper(quote))
+	params.Set("apikey", a.APIKey)
+
+	resp, err := a.makeRequest(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Rate map[string]string `json:"Realtime Currency Exchange Rate"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Alpha Vantage response: %w", err)
+	}
+
+	rate, err := strconv.ParseFloat(result.Rate["5. Exchange Rate"], 64)
+	if err != nil {
+		return nil, fmt.Errorf("no exchange rate returned for %s/%s", base, quote)
+	}
+
+	date := time.Now().Format("2006-01-02")
+	if refreshed := result.Rate["6. Last Refreshed"]; len(refreshed) >= 10 {
+		date = refreshed[:10]
+	}
+
+	return &models.FXRate{
+		Base:      strings.ToUpper(base),
+		Quote:     strings.ToUpper(quote),
+		Rate:      rate,
+		Date:      date,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// GetHistoricalRates uses the Alpha Vantage FX_DAILY function
+func (a *AlphaVantageProvider) GetHistoricalRates(ctx context.Context, base, quote string) (*models.FXHistoricalRates, error) {
+	params := url.Values{}
+	params.Set("function", "FX_DAILY")
+	params.Set("from_symbol", strings.ToUpper(base))
+	params.Set("to_symbol", strings.ToUpper(quote))
+	params.Set("outputsize", "full")
+	params.Set("apikey", a.APIKey)
+
+	resp, err := a.makeRequest(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		TimeSeries map[string]map[string]string `json:"Time Series FX (Daily)"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Alpha Vantage response: %w", err)
+	}
+	if len(result.TimeSeries) == 0 {
+		return nil, fmt.Errorf("no exchange rates returned for %s/%s", base, quote)
+	}
+
+	rates := make([]models.FXRatePoint, 0, len(result.TimeSeries))
+	for date, data := range result.TimeSeries {
+		if rate, err := strconv.ParseFloat(data["4. close"], 64); err == nil {
+			rates = append(rates, models.FXRatePoint{Date: date, Rate: rate})
+		}
+	}
+
+	// Sort rates by date (newest to oldest)
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Date > rates[j].Date
+	})
+
+	return &models.FXHistoricalRates{
+		Base:  strings.ToUpper(base),
+		Quote: strings.ToUpper(quote),
+		Rates: rates,
+	}, nil
+}
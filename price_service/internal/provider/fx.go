@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/transaction-tracker/price_service/internal/config"
+	"github.com/transaction-tracker/price_service/internal/models"
+)
+
+const (
+	FXProviderFixture      = "fixture"
+	FXProviderAlphaVantage = "alpha_vantage"
+)
+
+// FXRateProvider defines the interface for foreign exchange rate providers
+type FXRateProvider interface {
+	// GetCurrentRate retrieves the latest rate to convert base into quote
+	GetCurrentRate(ctx context.Context, base, quote string) (*models.FXRate, error)
+
+	// GetHistoricalRates retrieves daily rates for a currency pair, newest first
+	GetHistoricalRates(ctx context.Context, base, quote string) (*models.FXHistoricalRates, error)
+}
+
+// NewFXRateProvider creates the FX rate provider selected in the configuration
+func NewFXRateProvider(cfg *config.Config) (FXRateProvider, error) {
+	switch strings.ToLower(cfg.FX.Provider) {
+	case FXProviderFixture:
+		return NewFixtureFXProvider(cfg.FX.FixturePath)
+	case "", FXProviderAlphaVantage:
+		alphaVantage := NewAlphaVantageProvider(cfg.StockAPI.AlphaVantage.APIKey)
+		if cfg.StockAPI.AlphaVantage.BaseURL != "" {
+			alphaVantage.BaseURL = cfg.StockAPI.AlphaVantage.BaseURL
+		}
+		return alphaVantage, nil
+	default:
+		return nil, fmt.Errorf("unsupported FX provider: %s", cfg.FX.Provider)
+	}
+}
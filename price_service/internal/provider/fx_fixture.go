@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/transaction-tracker/price_service/internal/models"
+)
+
+// fxFixture is the on-disk format of fixture rates: per date, units of each currency per one base unit
+type fxFixture struct {
+	Base  string                        `json:"base"`
+	Rates map[string]map[string]float64 `json:"rates"`
+}
+
+// FixtureFXProvider serves FX rates from a static file for offline use.
+// Cross rates are derived through the fixture's base currency.
+type FixtureFXProvider struct {
+	base  string
+	dates []string // sorted newest to oldest
+	rates map[string]map[string]float64
+}
+
+// NewFixtureFXProvider loads rates from the given file
+func NewFixtureFXProvider(path string) (*FixtureFXProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the fixture FX provider needs a rates file (FX_FIXTURE_PATH)")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FX fixture %s: %w", path, err)
+	}
+
+	var fixture fxFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse FX fixture: %w", err)
+	}
+	if fixture.Base == "" || len(fixture.Rates) == 0 {
+		return nil, fmt.Errorf("FX fixture must define a base currency and rates")
+	}
+
+	p := &FixtureFXProvider{
+		base:  strings.ToUpper(fixture.Base),
+		rates: fixture.Rates,
+	}
+	for date := range fixture.Rates {
+		p.dates = append(p.dates, date)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(p.dates)))
+
+	return p, nil
+}
+
+// GetCurrentRate returns the rate of the most recent fixture date
+func (p *FixtureFXProvider) GetCurrentRate(ctx context.Context, base, quote string) (*models.FXRate, error) {
+	date := p.dates[0]
+	rate, err := p.rateOn(date, base, quote)
+	if err != nil {
+		return nil, err
+	}
+	return &models.FXRate{
+		Base:      strings.ToUpper(base),
+		Quote:     strings.ToUpper(quote),
+		Rate:      rate,
+		Date:      date,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// GetHistoricalRates returns the rate on every fixture date, newest first
+func (p *FixtureFXProvider) GetHistoricalRates(ctx context.Context, base, quote string) (*models.FXHistoricalRates, error) {
+	result := &models.FXHistoricalRates{
+		Base:  strings.ToUpper(base),
+		Quote: strings.ToUpper(quote),
+	}
+	for _, date := range p.dates {
+		rate, err := p.rateOn(date, base, quote)
+		if err != nil {
+			return nil, err
+		}
+		result.Rates = append(result.Rates, models.FXRatePoint{Date: date, Rate: rate})
+	}
+	return result, nil
+}
+
+// rateOn derives the base to quote rate on a fixture date
+func (p *FixtureFXProvider) rateOn(date, base, quote string) (float64, error) {
+	baseRate, err := p.unitsPerFixtureBase(date, base)
+	if err != nil {
+		return 0, err
+	}
+	quoteRate, err := p.unitsPerFixtureBase(date, quote)
+	if err != nil {
+		return 0, err
+	}
+	return quoteRate / baseRate, nil
+}
+
+// unitsPerFixtureBase returns how many units of currency one fixture base unit buys on a date
+func (p *FixtureFXProvider) unitsPerFixtureBase(date, currency string) (float64, error) {
+	currency = strings.ToUpper(currency)
+	if currency == p.base {
+		return 1, nil
+	}
+	rate, ok := p.rates[date][currency]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("unsupported currency: %s", currency)
+	}
+	return rate, nil
+}
@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/transaction-tracker/price_service/internal/config"
+	"github.com/transaction-tracker/price_service/internal/models"
+)
+
+const (
+	MetadataProviderFixture = "fixture"
+)
+
+// SecurityMetadataProvider defines the interface for security metadata providers
+type SecurityMetadataProvider interface {
+	// GetSecurityMetadata retrieves the sector, industry, country, asset class and exchange of a symbol
+	GetSecurityMetadata(ctx context.Context, symbol string) (*models.SecurityMetadata, error)
+}
+
+// NewSecurityMetadataProvider creates the security metadata provider selected in the configuration
+func NewSecurityMetadataProvider(cfg *config.Config) (SecurityMetadataProvider, error) {
+	switch strings.ToLower(cfg.Metadata.Provider) {
+	case "", MetadataProviderFixture:
+		return NewFixtureMetadataProvider(cfg.Metadata.FixturePath)
+	default:
+		return nil, fmt.Errorf("unsupported metadata provider: %s", cfg.Metadata.Provider)
+	}
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/transaction-tracker/price_service/internal/models"
+)
+
+//go:embed testdata/security-metadata.json
+var securityMetadataTestData []byte
+
+// FixtureMetadataProvider serves security metadata from a static file for offline use
+type FixtureMetadataProvider struct {
+	securities map[string]models.SecurityMetadata
+}
+
+// NewFixtureMetadataProvider loads metadata from the given file, or the embedded fixture when path is empty.
+// The file maps each symbol to its metadata.
+func NewFixtureMetadataProvider(path string) (*FixtureMetadataProvider, error) {
+	data := securityMetadataTestData
+	if path != "" {
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read metadata fixture %s: %w", path, err)
+		}
+		data = fileData
+	}
+
+	var fixture map[string]models.SecurityMetadata
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata fixture: %w", err)
+	}
+	if len(fixture) == 0 {
+		return nil, fmt.Errorf("metadata fixture must define at least one security")
+	}
+
+	p := &FixtureMetadataProvider{securities: make(map[string]models.SecurityMetadata, len(fixture))}
+	for symbol, metadata := range fixture {
+		symbol = strings.ToUpper(symbol)
+		metadata.Symbol = symbol
+		if metadata.AssetClass == "" {
+			metadata.AssetClass = models.AssetClassOther
+		}
+		p.securities[symbol] = metadata
+	}
+
+	return p, nil
+}
+
+// GetSecurityMetadata returns the fixture entry of a symbol
+func (p *FixtureMetadataProvider) GetSecurityMetadata(ctx context.Context, symbol string) (*models.SecurityMetadata, error) {
+	metadata, ok := p.securities[strings.ToUpper(symbol)]
+	if !ok {
+		return nil, fmt.Errorf("unknown symbol: %s", symbol)
+	}
+	return &metadata, nil
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/price_service/api/handlers"
+	"github.com/transaction-tracker/price_service/internal/config"
+	"github.com/transaction-tracker/price_service/internal/models"
+	"github.com/transaction-tracker/price_service/internal/provider"
+)
+
+// fxFixturePath holds weekly reference rates for the tests
+const fxFixturePath = "testdata/fx-rates-usd-weekly.json"
+
+func TestNewFXRateProvider(t *testing.T) {
+	// The live provider is the default; the fixture is opt-in and needs a rates file
+	p, err := provider.NewFXRateProvider(&config.Config{})
+	require.NoError(t, err)
+	assert.IsType(t, &provider.AlphaVantageProvider{}, p)
+
+	_, err = provider.NewFXRateProvider(&config.Config{FX: config.FXConfig{Provider: provider.FXProviderFixture}})
+	assert.Error(t, err)
+
+	p, err = provider.NewFXRateProvider(&config.Config{FX: config.FXConfig{Provider: provider.FXProviderFixture, FixturePath: fxFixturePath}})
+	require.NoError(t, err)
+	assert.IsType(t, &provider.FixtureFXProvider{}, p)
+}
+
+func TestFixtureFXProvider_CurrentAndCrossRates(t *testing.T) {
+	p, err := provider.NewFixtureFXProvider(fxFixturePath)
+	require.NoError(t, err)
+
+	rate, err := p.GetCurrentRate(context.Background(), "USD", "TWD")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10-12", rate.Date)
+	assert.InDelta(t, 30.5, rate.Rate, 1e-9)
+
+	// Inverse of the fixture base
+	inverse, err := p.GetCurrentRate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	assert.InDelta(t, 1/0.86, inverse.Rate, 1e-9)
+
+	// Cross rate through the fixture base
+	cross, err := p.GetCurrentRate(context.Background(), "EUR", "GBP")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.74/0.86, cross.Rate, 1e-9)
+
+	_, err = p.GetCurrentRate(context.Background(), "USD", "XYZ")
+	assert.Error(t, err)
+}
+
+func TestFixtureFXProvider_HistoricalRates(t *testing.T) {
+	p, err := provider.NewFixtureFXProvider(fxFixturePath)
+	require.NoError(t, err)
+
+	history, err := p.GetHistoricalRates(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.NotEmpty(t, history.Rates)
+	assert.Equal(t, "2026-10-12", history.Rates[0].Date)
+	assert.Equal(t, "2015-01-05", history.Rates[len(history.Rates)-1].Date)
+
+	// A weekend date carries the previous rate forward
+	filtered := handlers.FilterFXRates(history, "2025-01-11", "", "")
+	require.Len(t, filtered.Rates, 1)
+	assert.Equal(t, "2025-01-06", filtered.Rates[0].Date)
+	assert.InDelta(t, 0.9586, filtered.Rates[0].Rate, 1e-9)
+
+	ranged := handlers.FilterFXRates(history, "", "2025-01-01", "2025-01-31")
+	for _, point := range ranged.Rates {
+		assert.True(t, point.Date >= "2025-01-01" && point.Date <= "2025-01-31")
+	}
+	assert.Len(t, ranged.Rates, 4)
+}
+
+func newFXTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	p, err := provider.NewFixtureFXProvider(fxFixturePath)
+	require.NoError(t, err)
+
+	cfg := &config.Config{Cache: config.CacheConfig{MaxSymbolsPerReq: 10}}
+	handler := handlers.NewFXHandler(p, cfg)
+
+	router := gin.New()
+	router.GET("/api/v1/fx/current", handler.GetCurrentRates)
+	router.GET("/api/v1/fx/historical", handler.GetHistoricalRates)
+	return router
+}
+
+func TestFXHandler_GetCurrentRates(t *testing.T) {
+	router := newFXTestRouter(t)
+
+	req, _ := http.NewRequest("GET", "/api/v1/fx/current?base=usd&quotes=EUR,USD", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool            `json:"success"`
+		Data    []models.FXRate `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, "EUR", response.Data[0].Quote)
+	assert.InDelta(t, 0.86, response.Data[0].Rate, 1e-9)
+	assert.InDelta(t, 1, response.Data[1].Rate, 1e-9)
+
+	req, _ = http.NewRequest("GET", "/api/v1/fx/current?base=USD&quotes=XYZ", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/fx/current?base=US&quotes=EUR", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFXHandler_GetHistoricalRates(t *testing.T) {
+	router := newFXTestRouter(t)
+
+	req, _ := http.NewRequest("GET", "/api/v1/fx/historical?base=USD&quote=TWD&date=2025-01-08", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool                     `json:"success"`
+		Data    models.FXHistoricalRates `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data.Rates, 1)
+	assert.InDelta(t, 32.7685, response.Data.Rates[0].Rate, 1e-9)
+
+	// Before the first fixture date there is nothing to carry forward
+	req, _ = http.NewRequest("GET", "/api/v1/fx/historical?base=USD&quote=TWD&date=2010-01-01", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/fx/historical?base=USD&quote=TWD&from=2025-01-01", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transaction-tracker/price_service/api/handlers"
+	"github.com/transaction-tracker/price_service/internal/config"
+	"github.com/transaction-tracker/price_service/internal/models"
+	"github.com/transaction-tracker/price_service/internal/provider"
+)
+
+func TestFixtureMetadataProvider(t *testing.T) {
+	p, err := provider.NewFixtureMetadataProvider("")
+	require.NoError(t, err)
+
+	metadata, err := p.GetSecurityMetadata(context.Background(), "aapl")
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL", metadata.Symbol)
+	assert.Equal(t, "Technology", metadata.Sector)
+	assert.Equal(t, "US", metadata.Country)
+	assert.Equal(t, models.AssetClassEquity, metadata.AssetClass)
+
+	_, err = p.GetSecurityMetadata(context.Background(), "NOPE")
+	assert.Error(t, err)
+}
+
+func TestFixtureMetadataProvider_CustomFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"abc": {"name": "ABC Corp", "sector": "Industrials"}}`), 0o600))
+
+	p, err := provider.NewFixtureMetadataProvider(path)
+	require.NoError(t, err)
+
+	metadata, err := p.GetSecurityMetadata(context.Background(), "ABC")
+	require.NoError(t, err)
+	assert.Equal(t, "ABC", metadata.Symbol)
+	assert.Equal(t, models.AssetClassOther, metadata.AssetClass)
+}
+
+func TestMetadataHandler_GetSecurityMetadata(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	p, err := provider.NewFixtureMetadataProvider("")
+	require.NoError(t, err)
+	handler := handlers.NewMetadataHandler(p, &config.Config{Cache: config.CacheConfig{MaxSymbolsPerReq: 10}})
+
+	router := gin.New()
+	router.GET("/api/v1/metadata", handler.GetSecurityMetadata)
+
+	req, _ := http.NewRequest("GET", "/api/v1/metadata?symbols=spy,NOPE,BND,SPY", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool                      `json:"success"`
+		Data    []models.SecurityMetadata `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, "SPY", response.Data[0].Symbol)
+	assert.Equal(t, models.AssetClassETF, response.Data[0].AssetClass)
+	assert.Equal(t, models.AssetClassFixedIncome, response.Data[1].AssetClass)
+
+	req, _ = http.NewRequest("GET", "/api/v1/metadata", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -2,9 +2,12 @@ package tests
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/transaction-tracker/price_service/internal/config"
+	"github.com/transaction-tracker/price_service/internal/models"
 	"github.com/transaction-tracker/price_service/internal/provider"
 )
 
@@ -62,3 +65,49 @@ func TestNewThirdPartyProviderMapMissingKeys(t *testing.T) {
 		t.Fatalf("NewThirdPartyProviderMap should not fail with missing keys: %v", err)
 	}
 }
+
+func TestAlphaVantageProvider_IntradayPrices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("function"); got != "TIME_SERIES_INTRADAY" {
+			t.Errorf("function = %s, want TIME_SERIES_INTRADAY", got)
+		}
+		if got := r.URL.Query().Get("interval"); got != "60min" {
+			t.Errorf("interval = %s, want 60min", got)
+		}
+		w.Write([]byte(`{
+			"Meta Data": {"2. Symbol": "AAPL", "6. Time Zone": "US/Eastern"},
+			"Time Series (60min)": {
+				"2025-07-17 15:00:00": {"4. close": "210.50"},
+				"2025-07-17 16:00:00": {"4. close": "211.25"},
+				"2025-07-16 16:00:00": {"4. close": "209.00"}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	alphaVantage := provider.NewAlphaVantageProvider("test-key")
+	alphaVantage.BaseURL = server.URL
+
+	result, err := alphaVantage.GetHistoricalPrices(context.Background(), "AAPL", models.ResolutionIntraday)
+	if err != nil {
+		t.Fatalf("GetHistoricalPrices failed: %v", err)
+	}
+	if result.Resolution != models.ResolutionIntraday {
+		t.Errorf("resolution = %s, want intraday", result.Resolution)
+	}
+
+	// Eastern daylight time is four hours behind UTC, and the newest bar comes first
+	want := []models.ClosePrice{
+		{Date: "2025-07-17T20:00:00Z", Price: 211.25},
+		{Date: "2025-07-17T19:00:00Z", Price: 210.50},
+		{Date: "2025-07-16T20:00:00Z", Price: 209.00},
+	}
+	if len(result.HistoricalPrices) != len(want) {
+		t.Fatalf("got %d prices, want %d", len(result.HistoricalPrices), len(want))
+	}
+	for i, price := range want {
+		if result.HistoricalPrices[i] != price {
+			t.Errorf("price %d = %+v, want %+v", i, result.HistoricalPrices[i], price)
+		}
+	}
+}